@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+)
+
+// SolanaBlock is a getBlock response with transactionDetails "full"
+type SolanaBlock struct {
+	Slot              uint64               `json:"slot"` // not part of the getBlock response; set it to the requested slot
+	ParentSlot        uint64               `json:"parentSlot"`
+	Blockhash         string               `json:"blockhash"`
+	PreviousBlockhash string               `json:"previousBlockhash"`
+	BlockTime         *int64               `json:"blockTime"`
+	BlockHeight       *uint64              `json:"blockHeight"`
+	Transactions      []*SolanaTransaction `json:"transactions"`
+}
+
+// IsVoteTransaction reports whether tx is flagged as a vote or only invokes the Vote program.
+// Program ids are read from the static account keys without building an adapter, so votes can be
+// dropped before parsing.
+func IsVoteTransaction(tx *SolanaTransaction) bool {
+	if tx == nil {
+		return false
+	}
+	if tx.IsVote {
+		return true
+	}
+
+	msg := &tx.Transaction.Message
+	staticKey := func(index int) string {
+		if len(msg.StaticAccountKeys) > 0 {
+			if index >= 0 && index < len(msg.StaticAccountKeys) {
+				return msg.StaticAccountKeys[index]
+			}
+		} else if index >= 0 && index < len(msg.AccountKeys) {
+			return msg.AccountKeys[index].Pubkey
+		}
+		return ""
+	}
+
+	count := 0
+	for _, ix := range msg.CompiledInstructions {
+		if staticKey(ix.ProgramIdIndex) != constants.VOTE_PROGRAM_ID {
+			return false
+		}
+		count++
+	}
+	for _, raw := range msg.Instructions {
+		ix, ok := raw.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		programId, _ := ix["programId"].(string)
+		if index, ok := toUint32(ix["programIdIndex"]); ok && programId == "" {
+			programId = staticKey(int(index))
+		}
+		if programId != constants.VOTE_PROGRAM_ID {
+			return false
+		}
+		count++
+	}
+	return count > 0
+}
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// CallTree reconstructs the program invocation tree from LogMessages, or returns nil when the transaction has no logs
+func (a *TransactionAdapter) CallTree() *types.CallTree {
+	if len(a.LogMessages()) == 0 {
+		return nil
+	}
+
+	tree := &types.CallTree{Roots: make([]*types.CallNode, 0)}
+	var stack []*types.CallNode
+
+	tree.Truncated = a.walkLogs(func(logIndex int, line string, top *logFrame) {
+		if top == nil {
+			return
+		}
+		kind, programId, _ := parseInvocationLine(line)
+
+		if kind == logLineInvoke {
+			for len(stack) > 0 && stack[len(stack)-1].Depth >= top.depth {
+				stack = stack[:len(stack)-1]
+			}
+			node := &types.CallNode{
+				ProgramId:  top.programId,
+				OuterIndex: top.outerIndex,
+				InnerIndex: top.innerIndex,
+				Depth:      top.depth,
+				Status:     types.CallStatusIncomplete,
+			}
+			if len(stack) == 0 {
+				tree.Roots = append(tree.Roots, node)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+			return
+		}
+
+		if len(stack) == 0 {
+			return
+		}
+		node := stack[len(stack)-1]
+
+		switch {
+		case kind == logLineSuccess && programId == node.ProgramId:
+			node.Status = types.CallStatusSuccess
+			stack = stack[:len(stack)-1]
+		case kind == logLineFailed && programId == node.ProgramId:
+			node.Status = types.CallStatusFailed
+			_, node.Error, _ = strings.Cut(line, "failed: ")
+			stack = stack[:len(stack)-1]
+		case kind == logLineConsumed && programId == node.ProgramId:
+			node.ComputeUnitsConsumed, node.ComputeUnitsAvailable = parseConsumedLine(line)
+		default:
+			node.Logs = append(node.Logs, line)
+		}
+	})
+	return tree
+}
+
+// parseConsumedLine reads X and Y from "Program <id> consumed X of Y compute units"
+func parseConsumedLine(line string) (consumed, available uint64) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 || fields[4] != "of" {
+		return 0, 0
+	}
+	consumed, _ = strconv.ParseUint(fields[3], 10, 64)
+	available, _ = strconv.ParseUint(fields[5], 10, 64)
+	return consumed, available
+}
@@ -0,0 +1,113 @@
+package adapter
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// microLamportsPerLamport converts compute unit prices to lamports
+const microLamportsPerLamport = 1_000_000
+
+// ComputeBudget decodes the ComputeBudget instructions of the transaction.
+// Without SetComputeUnitLimit the limit is derived like the runtime default: 200k CU per
+// non-builtin instruction and 3k CU per builtin one, ComputeBudget included, at most 1.4M.
+// Builtins are the programs in constants.BUILTIN_PROGRAMS; feature activations that move a
+// program out of that set are not tracked.
+func (a *TransactionAdapter) ComputeBudget() *types.ComputeBudget {
+	budget := &types.ComputeBudget{}
+	var hasLimit bool
+	var deprecatedFee *uint64
+	defaultLimit := 0
+
+	for _, instruction := range a.Instructions() {
+		programId := a.GetInstructionProgramId(instruction)
+		if constants.IsBuiltinProgram(programId) {
+			defaultLimit += constants.MaxBuiltinInstructionComputeUnitLimit
+		} else {
+			defaultLimit += constants.DefaultInstructionComputeUnitLimit
+		}
+		if programId != constants.COMPUTE_BUDGET_PROGRAM_ID {
+			continue
+		}
+		data := a.GetInstructionData(instruction)
+		if len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case constants.ComputeBudgetRequestUnitsDeprecated:
+			if len(data) >= 9 {
+				budget.UnitLimit = binary.LittleEndian.Uint32(data[1:5])
+				fee := uint64(binary.LittleEndian.Uint32(data[5:9]))
+				deprecatedFee = &fee
+				hasLimit = true
+			}
+		case constants.ComputeBudgetRequestHeapFrame:
+			if len(data) >= 5 {
+				budget.HeapFrameBytes = binary.LittleEndian.Uint32(data[1:5])
+			}
+		case constants.ComputeBudgetSetComputeUnitLimit:
+			if len(data) >= 5 {
+				budget.UnitLimit = binary.LittleEndian.Uint32(data[1:5])
+				hasLimit = true
+			}
+		case constants.ComputeBudgetSetComputeUnitPrice:
+			if len(data) >= 9 {
+				budget.UnitPrice = binary.LittleEndian.Uint64(data[1:9])
+			}
+		case constants.ComputeBudgetSetLoadedAccountsDataSizeLimit:
+			if len(data) >= 5 {
+				budget.LoadedAccountsDataSizeLimit = binary.LittleEndian.Uint32(data[1:5])
+			}
+		}
+	}
+
+	if !hasLimit {
+		budget.UnitLimit = uint32(min(defaultLimit, constants.MaxComputeUnitLimit))
+		budget.DefaultUnitLimit = true
+	}
+	budget.UnitLimit = min(budget.UnitLimit, constants.MaxComputeUnitLimit)
+
+	if deprecatedFee != nil {
+		budget.PriorityFee = *deprecatedFee
+		if budget.UnitLimit > 0 {
+			budget.UnitPrice = *deprecatedFee * microLamportsPerLamport / uint64(budget.UnitLimit)
+		}
+		return budget
+	}
+
+	// ceil(price * limit / 1e6); the product can exceed uint64
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(budget.UnitPrice), new(big.Int).SetUint64(uint64(budget.UnitLimit)))
+	fee.Add(fee, big.NewInt(microLamportsPerLamport-1))
+	fee.Quo(fee, big.NewInt(microLamportsPerLamport))
+	if fee.IsUint64() {
+		budget.PriorityFee = fee.Uint64()
+	}
+	return budget
+}
+
+// FeeSplit splits the transaction fee into the base (signature) fee and the priority fee.
+// With a default unit limit the priority fee follows ComputeBudget's derivation of that limit.
+func (a *TransactionAdapter) FeeSplit(budget *types.ComputeBudget) (base, priority types.TokenAmount) {
+	fee := uint64(0)
+	if a.tx.Meta != nil {
+		fee = a.tx.Meta.Fee
+	}
+	priorityFee := uint64(0)
+	if budget != nil {
+		priorityFee = min(budget.PriorityFee, fee)
+	}
+	return lamportsAmount(fee - priorityFee), lamportsAmount(priorityFee)
+}
+
+// lamportsAmount converts lamports to a SOL TokenAmount
+func lamportsAmount(lamports uint64) types.TokenAmount {
+	uiAmount := types.ConvertToUIAmount(new(big.Int).SetUint64(lamports), 9)
+	return types.TokenAmount{
+		Amount:   new(big.Int).SetUint64(lamports).String(),
+		UIAmount: &uiAmount,
+		Decimals: 9,
+	}
+}
@@ -0,0 +1,176 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// Context returns the context the adapter was created with, passed on to fetcher calls
+func (a *TransactionAdapter) Context() context.Context {
+	return a.ctx
+}
+
+// ShouldFetch reports whether a fetcher with the given filter should be invoked for this transaction
+func (a *TransactionAdapter) ShouldFetch(filter types.FetchFilterType) bool {
+	return a.matchesFetchFilter(filter, a.AccountKeys)
+}
+
+// matchesFetchFilter evaluates a fetcher filter against the known account keys.
+// An empty ProgramIds/AccountInclude list places no restriction on the transaction.
+func (a *TransactionAdapter) matchesFetchFilter(filter types.FetchFilterType, keys []string) bool {
+	if a.Config == nil {
+		return true
+	}
+
+	var wanted []string
+	switch filter {
+	case types.FetchFilterProgram:
+		wanted = a.Config.ProgramIds
+	case types.FetchFilterAccount:
+		wanted = a.Config.AccountInclude
+	default:
+		return true
+	}
+
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		for _, key := range keys {
+			if w == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetchLoadedAddresses resolves the message address table lookups through ALTsFetcher.
+// Returns nil when there is nothing to resolve or the fetcher is not applicable.
+// Lookups missing from the fetcher response are padded with empty keys so that
+// account indexes of the following tables keep pointing at the right slot.
+func (a *TransactionAdapter) fetchLoadedAddresses(staticKeys []string) *LoadedAddresses {
+	lookups := a.tx.Transaction.Message.AddressTableLookups
+	if len(lookups) == 0 || a.Config == nil {
+		return nil
+	}
+
+	fetcher := a.Config.ALTsFetcher
+	if !fetcher.Enabled() || !a.matchesFetchFilter(fetcher.Filter, staticKeys) {
+		return nil
+	}
+
+	alts := make([]types.AddressTableLookup, len(lookups))
+	for i, lookup := range lookups {
+		alts[i] = types.AddressTableLookup{
+			AccountKey:      lookup.AccountKey,
+			WritableIndexes: lookup.WritableIndexes,
+			ReadonlyIndexes: lookup.ReadonlyIndexes,
+		}
+	}
+
+	resolved, err := fetcher.FetchWithContext(a.ctx, alts)
+	if err != nil || len(resolved) == 0 {
+		return nil
+	}
+
+	// Solana orders loaded keys as all writable addresses of every table,
+	// followed by all readonly addresses of every table.
+	loaded := &LoadedAddresses{}
+	for _, lookup := range lookups {
+		table := resolved[lookup.AccountKey]
+		loaded.Writable = append(loaded.Writable, selectLoaded(table, lookup.WritableIndexes, true)...)
+	}
+	for _, lookup := range lookups {
+		table := resolved[lookup.AccountKey]
+		loaded.Readonly = append(loaded.Readonly, selectLoaded(table, lookup.ReadonlyIndexes, false)...)
+	}
+
+	return loaded
+}
+
+// selectLoaded returns the addresses a lookup contributes, padding with empty keys when unresolved
+func selectLoaded(table *types.LoadedAddresses, indexes []int, writable bool) []string {
+	result := make([]string, len(indexes))
+	if table == nil {
+		return result
+	}
+
+	src := table.Readonly
+	if writable {
+		src = table.Writable
+	}
+	copy(result, src)
+	return result
+}
+
+// deferTokenAccount records a transfer account whose mint cannot be derived from the instruction itself
+func (a *TransactionAdapter) deferTokenAccount(account string) {
+	if account == "" {
+		return
+	}
+	for _, pending := range a.unresolvedTokenAccounts {
+		if pending == account {
+			return
+		}
+	}
+	a.unresolvedTokenAccounts = append(a.unresolvedTokenAccounts, account)
+}
+
+// resolveTokenAccounts fills mint/decimals/owner of deferred transfer accounts through
+// TokenAccountsFetcher, then falls back to the SOL defaults for anything still unknown
+func (a *TransactionAdapter) resolveTokenAccounts() {
+	var pending []string
+	for _, account := range a.unresolvedTokenAccounts {
+		if _, ok := a.SPLTokenMap[account]; !ok {
+			pending = append(pending, account)
+		}
+	}
+	a.unresolvedTokenAccounts = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	if fetcher := a.tokenAccountsFetcher(); fetcher != nil {
+		infos, err := fetcher.FetchWithContext(a.ctx, pending)
+		if err == nil {
+			for i, info := range infos {
+				if i >= len(pending) || info == nil || info.Mint == "" {
+					continue
+				}
+				// The fetched decimals are authoritative, 0 included, so they skip setTokenInfo's defaults
+				a.SPLTokenMap[pending[i]] = types.TokenInfo{
+					Mint:      info.Mint,
+					AmountRaw: "0",
+					Decimals:  info.Decimals,
+				}
+				if _, ok := a.SPLDecimalsMap[info.Mint]; !ok {
+					a.SPLDecimalsMap[info.Mint] = info.Decimals
+				}
+				if info.Owner != "" {
+					if a.tokenAccountOwners == nil {
+						a.tokenAccountOwners = make(map[string]string, len(pending))
+					}
+					a.tokenAccountOwners[pending[i]] = info.Owner
+				}
+			}
+		}
+	}
+
+	for _, account := range pending {
+		a.setTokenInfo(account, "", "", 0)
+	}
+}
+
+// tokenAccountsFetcher returns the configured TokenAccountsFetcher if it applies to this transaction
+func (a *TransactionAdapter) tokenAccountsFetcher() *types.TokenAccountsFetcher {
+	if a.Config == nil {
+		return nil
+	}
+	fetcher := a.Config.TokenAccountsFetcher
+	if !fetcher.Enabled() || !a.ShouldFetch(fetcher.Filter) {
+		return nil
+	}
+	return fetcher
+}
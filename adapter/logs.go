@@ -0,0 +1,190 @@
+package adapter
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+const (
+	logProgramData = "Program data: "
+	logRayLog      = "Program log: ray_log: "
+	logTruncated   = "Log truncated"
+)
+
+// logFrame is a program invocation reconstructed from the log messages
+type logFrame struct {
+	programId  string
+	outerIndex int
+	innerIndex int
+	depth      int
+}
+
+// logLineKind classifies the lines that open and close invocations
+type logLineKind int
+
+const (
+	logLineOther logLineKind = iota
+	logLineInvoke
+	logLineSuccess
+	logLineFailed
+	logLineConsumed
+)
+
+// parseInvocationLine recognizes "Program <id> invoke [n]", "Program <id> success", "Program <id> failed: ..."
+// and "Program <id> consumed X of Y compute units"
+func parseInvocationLine(line string) (kind logLineKind, programId string, depth int) {
+	rest, ok := strings.CutPrefix(line, "Program ")
+	if !ok {
+		return logLineOther, "", 0
+	}
+	programId, rest, ok = strings.Cut(rest, " ")
+	if !ok || strings.HasSuffix(programId, ":") {
+		return logLineOther, "", 0
+	}
+	switch {
+	case strings.HasPrefix(rest, "invoke ["):
+		depth, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rest, "invoke ["), "]"))
+		if err != nil {
+			return logLineOther, "", 0
+		}
+		return logLineInvoke, programId, depth
+	case rest == "success":
+		return logLineSuccess, programId, 0
+	case strings.HasPrefix(rest, "failed"):
+		return logLineFailed, programId, 0
+	case strings.HasPrefix(rest, "consumed "):
+		return logLineConsumed, programId, 0
+	}
+	return logLineOther, "", 0
+}
+
+// walkLogs replays LogMessages and calls visit for each line with the invocation executing at that point.
+// Invoke lines are visited with the new frame and success/failed lines with the exiting frame; top is nil
+// outside any invocation. Invocations are aligned with outer and inner instructions by program ID, so
+// instructions that do not log (e.g. precompiles) do not shift the indexes. It reports whether the logs were truncated.
+func (a *TransactionAdapter) walkLogs(visit func(logIndex int, line string, top *logFrame)) (truncated bool) {
+	logs := a.LogMessages()
+	if len(logs) == 0 {
+		return false
+	}
+
+	instructions := a.Instructions()
+	innerSets := make(map[int][]interface{}, len(a.InnerInstructions()))
+	for _, set := range a.InnerInstructions() {
+		innerSets[set.Index] = set.Instructions
+	}
+
+	outer, inner := -1, -1
+	var stack []logFrame
+
+	for i, line := range logs {
+		if strings.HasPrefix(line, logTruncated) {
+			return true
+		}
+
+		kind, programId, depth := parseInvocationLine(line)
+		switch kind {
+		case logLineInvoke:
+			for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+				stack = stack[:len(stack)-1]
+			}
+			if depth <= 1 {
+				outer = a.nextInstructionIndex(instructions, outer, programId)
+				inner = -1
+				stack = stack[:0]
+				stack = append(stack, logFrame{programId: programId, outerIndex: outer, innerIndex: -1, depth: 1})
+			} else {
+				inner = a.nextInstructionIndex(innerSets[outer], inner, programId)
+				stack = append(stack, logFrame{programId: programId, outerIndex: outer, innerIndex: inner, depth: depth})
+			}
+			visit(i, line, &stack[len(stack)-1])
+		case logLineSuccess, logLineFailed:
+			if len(stack) == 0 {
+				visit(i, line, nil)
+				continue
+			}
+			top := stack[len(stack)-1]
+			visit(i, line, &top)
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				visit(i, line, nil)
+				continue
+			}
+			visit(i, line, &stack[len(stack)-1])
+		}
+	}
+	return false
+}
+
+// nextInstructionIndex returns the first index after current whose program is programId, or current+1 if none matches
+func (a *TransactionAdapter) nextInstructionIndex(instructions []interface{}, current int, programId string) int {
+	for j := current + 1; j < len(instructions); j++ {
+		if a.GetInstructionProgramId(instructions[j]) == programId {
+			return j
+		}
+	}
+	return current + 1
+}
+
+// LogEvents returns the "Program data:" and "ray_log:" payloads of the transaction,
+// each attributed to the outer/inner instruction that logged it
+func (a *TransactionAdapter) LogEvents() []types.ClassifiedEvent {
+	a.logEventsOnce.Do(func() {
+		a.logEvents = a.parseLogEvents()
+	})
+	return a.logEvents
+}
+
+// GetLogEvents returns the log events emitted by a program
+func (a *TransactionAdapter) GetLogEvents(programId string) []types.ClassifiedEvent {
+	var result []types.ClassifiedEvent
+	for _, event := range a.LogEvents() {
+		if event.ProgramId == programId {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func (a *TransactionAdapter) parseLogEvents() []types.ClassifiedEvent {
+	var events []types.ClassifiedEvent
+	a.walkLogs(func(logIndex int, line string, top *logFrame) {
+		if top == nil {
+			return
+		}
+
+		var eventType types.LogEventType
+		var payload string
+		if rest, ok := strings.CutPrefix(line, logProgramData); ok {
+			eventType, payload = types.LogEventProgramData, rest
+		} else if rest, ok := strings.CutPrefix(line, logRayLog); ok {
+			eventType, payload = types.LogEventRayLog, rest
+		} else {
+			return
+		}
+
+		var data []byte
+		for _, chunk := range strings.Fields(payload) {
+			decoded, err := base64.StdEncoding.DecodeString(chunk)
+			if err != nil {
+				return
+			}
+			data = append(data, decoded...)
+		}
+
+		events = append(events, types.ClassifiedEvent{
+			Type:       eventType,
+			ProgramId:  top.programId,
+			OuterIndex: top.outerIndex,
+			InnerIndex: top.innerIndex,
+			Depth:      top.depth,
+			LogIndex:   logIndex,
+			Data:       data,
+		})
+	})
+	return events
+}
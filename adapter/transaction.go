@@ -1,8 +1,11 @@
 package adapter
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/binary"
 	"math/big"
+	"sync"
 
 	"github.com/goccy/go-json"
 	"github.com/mr-tron/base58"
@@ -18,6 +21,8 @@ type SolanaTransaction struct {
 	Transaction TransactionData        `json:"transaction"`
 	Meta        *TransactionMeta       `json:"meta"`
 	Version     interface{}            `json:"version"` // can be "legacy", 0, or nil
+	Index       uint64                 `json:"index,omitempty"` // position within the block, when known
+	IsVote      bool                   `json:"isVote,omitempty"` // set by stream sources that flag vote transactions
 }
 
 // TransactionData contains the transaction message and signatures
@@ -37,6 +42,7 @@ type TransactionMessage struct {
 	CompiledInstructions []CompiledInstruction `json:"compiledInstructions,omitempty"`
 
 	// Shared fields
+	RecentBlockhash     string              `json:"recentBlockhash,omitempty"`
 	Instructions        []interface{}       `json:"instructions,omitempty"`
 	AddressTableLookups []AddressTableLookup `json:"addressTableLookups,omitempty"`
 }
@@ -122,6 +128,7 @@ type TransactionMeta struct {
 	LogMessages       []string              `json:"logMessages"`
 	LoadedAddresses   *LoadedAddresses      `json:"loadedAddresses"`
 	ComputeUnitsConsumed *uint64            `json:"computeUnitsConsumed"`
+	ReturnData        *ReturnData           `json:"returnData,omitempty"`
 }
 
 // TokenBalance represents a token balance entry
@@ -144,18 +151,50 @@ type LoadedAddresses struct {
 	Readonly []string `json:"readonly"`
 }
 
+// ReturnData is the data set by the last program that called set_return_data.
+// Data holds the payload and its encoding, ["<base64>", "base64"], as returned by JSON-RPC.
+type ReturnData struct {
+	ProgramId string   `json:"programId"`
+	Data      []string `json:"data"`
+}
+
+// Bytes decodes the return data payload
+func (r *ReturnData) Bytes() []byte {
+	if r == nil || len(r.Data) == 0 {
+		return nil
+	}
+	data, _ := base64.StdEncoding.DecodeString(r.Data[0])
+	return data
+}
+
 // TransactionAdapter provides unified access to transaction data
 type TransactionAdapter struct {
+	ctx            context.Context
 	tx             *SolanaTransaction
 	Config         *types.ParseConfig
 	AccountKeys    []string
 	SPLTokenMap    map[string]types.TokenInfo
 	SPLDecimalsMap map[string]uint8
+
+	// tokenAccountOwners holds owners resolved through TokenAccountsFetcher
+	tokenAccountOwners map[string]string
+	// unresolvedTokenAccounts lists transfer accounts whose mint is unknown after instruction scan
+	unresolvedTokenAccounts []string
+
+	// logEvents caches the "Program data:" and "ray_log:" payloads decoded from LogMessages
+	logEvents     []types.ClassifiedEvent
+	logEventsOnce sync.Once
 }
 
 // NewTransactionAdapter creates a new TransactionAdapter
 func NewTransactionAdapter(tx *SolanaTransaction, config *types.ParseConfig) *TransactionAdapter {
+	return NewTransactionAdapterContext(context.Background(), tx, config)
+}
+
+// NewTransactionAdapterContext creates a new TransactionAdapter whose fetcher calls use ctx
+func NewTransactionAdapterContext(ctx context.Context, tx *SolanaTransaction, config *types.ParseConfig) *TransactionAdapter {
 	adapter := &TransactionAdapter{
+		ctx:            ctx,
 		tx:             tx,
 		Config:         config,
 		SPLTokenMap:    make(map[string]types.TokenInfo, 32),
@@ -300,6 +339,14 @@ func (a *TransactionAdapter) ComputeUnits() uint64 {
 	return 0
 }
 
+// ReturnData returns the transaction return data, or nil if none was set
+func (a *TransactionAdapter) ReturnData() *ReturnData {
+	if a.tx.Meta != nil {
+		return a.tx.Meta.ReturnData
+	}
+	return nil
+}
+
 // TxStatus returns the transaction status
 func (a *TransactionAdapter) TxStatus() types.TransactionStatus {
 	if a.tx.Meta == nil {
@@ -328,10 +375,13 @@ func (a *TransactionAdapter) extractAccountKeys() []string {
 		}
 	}
 
-	// Add loaded addresses
+	// Add loaded addresses, resolving lookups through ALTsFetcher when meta lacks them
 	if a.tx.Meta != nil && a.tx.Meta.LoadedAddresses != nil {
 		keys = append(keys, a.tx.Meta.LoadedAddresses.Writable...)
 		keys = append(keys, a.tx.Meta.LoadedAddresses.Readonly...)
+	} else if loaded := a.fetchLoadedAddresses(keys); loaded != nil {
+		keys = append(keys, loaded.Writable...)
+		keys = append(keys, loaded.Readonly...)
 	}
 
 	return keys
@@ -502,6 +552,9 @@ func (a *TransactionAdapter) GetTokenAccountOwner(accountKey string) string {
 			return balance.Owner
 		}
 	}
+	if owner, ok := a.tokenAccountOwners[accountKey]; ok {
+		return owner
+	}
 	return ""
 }
 
@@ -546,6 +599,7 @@ func (a *TransactionAdapter) GetPoolEventBase(eventType types.PoolEventType, pro
 func (a *TransactionAdapter) extractTokenInfo() {
 	a.extractTokenBalances()
 	a.extractTokenFromInstructions()
+	a.resolveTokenAccounts()
 
 	// Add SOL if not exists
 	if _, ok := a.SPLTokenMap[constants.TOKENS.SOL]; !ok {
@@ -624,10 +678,12 @@ func (a *TransactionAdapter) extractFromInstruction(ix interface{}) {
 
 	switch instructionType {
 	case constants.SPLTokenTransfer:
+		// Mint is unknown here, resolve after all instructions are scanned
 		if len(accounts) >= 2 {
-			source = accounts[0]
-			destination = accounts[1]
+			a.deferTokenAccount(accounts[0])
+			a.deferTokenAccount(accounts[1])
 		}
+		return
 	case constants.SPLTokenTransferChecked:
 		if len(accounts) >= 3 {
 			source = accounts[0]
@@ -664,8 +720,9 @@ func (a *TransactionAdapter) extractFromInstruction(ix interface{}) {
 			}
 		}
 	case constants.SPLTokenCloseAccount:
+		// The closed account may hold any mint, the destination only receives lamports
 		if len(accounts) >= 2 {
-			source = accounts[0]
+			a.deferTokenAccount(accounts[0])
 			destination = accounts[1]
 		}
 	}
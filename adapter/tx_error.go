@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"math"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/goccy/go-json"
+)
+
+// TxError decodes meta.err, or returns nil if the transaction succeeded.
+// Custom codes of an InstructionError are attributed to the deepest failed invocation in the logs,
+// so a slippage error raised by a DEX inside a router reports the DEX program, and are named from constants.PROGRAM_ERRORS.
+func (a *TransactionAdapter) TxError() *types.TxError {
+	if a.tx.Meta == nil || a.tx.Meta.Err == nil {
+		return nil
+	}
+	raw := a.tx.Meta.Err
+	txErr := &types.TxError{InstructionIndex: -1, InnerIndex: -1, Raw: raw}
+
+	switch v := raw.(type) {
+	case string:
+		txErr.Kind, txErr.Name = v, v
+		return txErr
+	case map[string]interface{}:
+		for kind, value := range v {
+			txErr.Kind, txErr.Name = kind, kind
+			if kind == types.TxErrorInstruction {
+				a.decodeInstructionError(txErr, value)
+			}
+			break
+		}
+		return txErr
+	}
+	txErr.Kind = "Unknown"
+	return txErr
+}
+
+// decodeInstructionError fills txErr from the [index, detail] pair of an InstructionError
+func (a *TransactionAdapter) decodeInstructionError(txErr *types.TxError, value interface{}) {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return
+	}
+	index, ok := toUint32(pair[0])
+	if !ok {
+		return
+	}
+	txErr.InstructionIndex = int(index)
+	if instructions := a.Instructions(); txErr.InstructionIndex < len(instructions) {
+		txErr.ProgramId = a.GetInstructionProgramId(instructions[txErr.InstructionIndex])
+	}
+	if failed := a.CallTree().FailedCall(); failed != nil && failed.OuterIndex == txErr.InstructionIndex {
+		txErr.ProgramId = failed.ProgramId
+		txErr.InnerIndex = failed.InnerIndex
+	}
+
+	switch detail := pair[1].(type) {
+	case string:
+		txErr.Name = detail
+	case map[string]interface{}:
+		for variant, arg := range detail {
+			txErr.Name = variant
+			if variant != "Custom" {
+				break
+			}
+			if code, ok := toUint32(arg); ok {
+				txErr.Code = &code
+				if name := constants.GetProgramErrorName(txErr.ProgramId, code); name != "" {
+					txErr.Name = name
+				}
+			}
+			break
+		}
+	}
+}
+
+// toUint32 converts a JSON-decoded number to uint32
+func toUint32(v interface{}) (uint32, bool) {
+	switch n := v.(type) {
+	case float64:
+		if n < 0 || n > math.MaxUint32 || n != math.Trunc(n) {
+			return 0, false
+		}
+		return uint32(n), true
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil || i < 0 || i > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(i), true
+	case int:
+		if n < 0 || n > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(n), true
+	case int64:
+		if n < 0 || n > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(n), true
+	case uint32:
+		return n, true
+	case uint64:
+		if n > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(n), true
+	}
+	return 0, false
+}
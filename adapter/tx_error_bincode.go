@@ -0,0 +1,185 @@
+package adapter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// transactionErrors lists the solana TransactionError variants in bincode order
+var transactionErrors = []string{
+	"AccountInUse",
+	"AccountLoadedTwice",
+	"AccountNotFound",
+	"ProgramAccountNotFound",
+	"InsufficientFundsForFee",
+	"InvalidAccountForFee",
+	"AlreadyProcessed",
+	"BlockhashNotFound",
+	"InstructionError",
+	"CallChainTooDeep",
+	"MissingSignatureForFee",
+	"InvalidAccountIndex",
+	"SignatureFailure",
+	"InvalidProgramForExecution",
+	"SanitizeFailure",
+	"ClusterMaintenance",
+	"AccountBorrowOutstanding",
+	"WouldExceedMaxBlockCostLimit",
+	"UnsupportedVersion",
+	"InvalidWritableAccount",
+	"WouldExceedMaxAccountCostLimit",
+	"WouldExceedAccountDataBlockLimit",
+	"TooManyAccountLocks",
+	"AddressLookupTableNotFound",
+	"InvalidAddressLookupTableOwner",
+	"InvalidAddressLookupTableData",
+	"InvalidAddressLookupTableIndex",
+	"InvalidRentPayingAccount",
+	"WouldExceedMaxVoteCostLimit",
+	"WouldExceedAccountDataTotalLimit",
+	"DuplicateInstruction",
+	"InsufficientFundsForRent",
+	"MaxLoadedAccountsDataSizeExceeded",
+	"InvalidLoadedAccountsDataSizeLimit",
+	"ResanitizationNeeded",
+	"ProgramExecutionTemporarilyRestricted",
+	"UnbalancedTransaction",
+	"ProgramCacheHitMaxLimit",
+	"CommitCancelled",
+}
+
+// instructionErrors lists the solana InstructionError variants in bincode order
+var instructionErrors = []string{
+	"GenericError",
+	"InvalidArgument",
+	"InvalidInstructionData",
+	"InvalidAccountData",
+	"AccountDataTooSmall",
+	"InsufficientFunds",
+	"IncorrectProgramId",
+	"MissingRequiredSignature",
+	"AccountAlreadyInitialized",
+	"UninitializedAccount",
+	"UnbalancedInstruction",
+	"ModifiedProgramId",
+	"ExternalAccountLamportSpend",
+	"ReadonlyLamportChange",
+	"ReadonlyDataModified",
+	"DuplicateAccountIndex",
+	"ExecutableModified",
+	"RentEpochModified",
+	"NotEnoughAccountKeys",
+	"AccountDataSizeChanged",
+	"AccountNotExecutable",
+	"AccountBorrowFailed",
+	"AccountBorrowOutstanding",
+	"DuplicateAccountOutOfSync",
+	"Custom",
+	"InvalidError",
+	"ExecutableDataModified",
+	"ExecutableLamportChange",
+	"ExecutableAccountNotRentExempt",
+	"UnsupportedProgramId",
+	"CallDepth",
+	"MissingAccount",
+	"ReentrancyNotAllowed",
+	"MaxSeedLengthExceeded",
+	"InvalidSeeds",
+	"InvalidRealloc",
+	"ComputationalBudgetExceeded",
+	"PrivilegeEscalation",
+	"ProgramEnvironmentSetupFailure",
+	"ProgramFailedToComplete",
+	"ProgramFailedToCompile",
+	"Immutable",
+	"IncorrectAuthority",
+	"BorshIoError",
+	"AccountNotRentExempt",
+	"InvalidAccountOwner",
+	"ArithmeticOverflow",
+	"UnsupportedSysvar",
+	"IllegalOwner",
+	"MaxAccountsDataAllocationsExceeded",
+	"MaxAccountsExceeded",
+	"MaxInstructionTraceLengthExceeded",
+	"BuiltinProgramsMustConsumeComputeUnits",
+}
+
+var errBincodeTruncated = errors.New("truncated transaction error")
+
+// DecodeTransactionError decodes a bincode-serialized TransactionError, as carried by gRPC and
+// Geyser streams, into the value JSON-RPC returns for meta.err: a variant name such as
+// "AccountInUse", or an object such as {"InstructionError":[2,{"Custom":6001}]}.
+// Numbers are float64, as after json.Unmarshal, so TxError handles both sources alike.
+func DecodeTransactionError(data []byte) (interface{}, error) {
+	variant, rest, err := bincodeUint32(data)
+	if err != nil {
+		return nil, err
+	}
+	if int(variant) >= len(transactionErrors) {
+		return nil, fmt.Errorf("unknown transaction error variant %d", variant)
+	}
+	name := transactionErrors[variant]
+
+	switch name {
+	case "InstructionError":
+		if len(rest) < 1 {
+			return nil, errBincodeTruncated
+		}
+		detail, err := decodeInstructionErrorDetail(rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{name: []interface{}{float64(rest[0]), detail}}, nil
+	case "DuplicateInstruction":
+		if len(rest) < 1 {
+			return nil, errBincodeTruncated
+		}
+		return map[string]interface{}{name: float64(rest[0])}, nil
+	case "InsufficientFundsForRent", "ProgramExecutionTemporarilyRestricted":
+		if len(rest) < 1 {
+			return nil, errBincodeTruncated
+		}
+		return map[string]interface{}{name: map[string]interface{}{"account_index": float64(rest[0])}}, nil
+	}
+	return name, nil
+}
+
+// decodeInstructionErrorDetail decodes the InstructionError of an InstructionError(index, detail)
+func decodeInstructionErrorDetail(data []byte) (interface{}, error) {
+	variant, rest, err := bincodeUint32(data)
+	if err != nil {
+		return nil, err
+	}
+	if int(variant) >= len(instructionErrors) {
+		return nil, fmt.Errorf("unknown instruction error variant %d", variant)
+	}
+	name := instructionErrors[variant]
+
+	switch name {
+	case "Custom":
+		code, _, err := bincodeUint32(rest)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{name: float64(code)}, nil
+	case "BorshIoError":
+		if len(rest) < 8 {
+			return nil, errBincodeTruncated
+		}
+		size := binary.LittleEndian.Uint64(rest)
+		if size > uint64(len(rest)-8) {
+			return nil, errBincodeTruncated
+		}
+		return map[string]interface{}{name: string(rest[8 : 8+size])}, nil
+	}
+	return name, nil
+}
+
+func bincodeUint32(data []byte) (uint32, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, errBincodeTruncated
+	}
+	return binary.LittleEndian.Uint32(data), data[4:], nil
+}
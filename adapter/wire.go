@@ -0,0 +1,293 @@
+package adapter
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	wireSignatureLength = 64
+	wirePubkeyLength    = 32
+	wireVersionPrefix   = 0x80
+)
+
+var errWireTruncated = errors.New("truncated wire transaction")
+
+// DecodeWireTransaction decodes a transaction in the binary wire format, as sent to sendTransaction
+// and carried in packets and shreds. Legacy and v0 messages are supported; instructions are returned
+// as CompiledInstructions and v0 address table lookups are kept.
+// The wire format has no meta: set Meta, Slot and BlockTime when they are known from another source.
+// Without meta, lookup accounts are resolved through the ALTs fetcher if one is configured.
+func DecodeWireTransaction(data []byte) (*SolanaTransaction, error) {
+	tx, n, err := ReadWireTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, fmt.Errorf("%d trailing bytes after wire transaction", len(data)-n)
+	}
+	return tx, nil
+}
+
+// DecodeWireTransactionString decodes a wire transaction encoded as "base64" or "base58"
+// ("binary" is the deprecated RPC name for base58)
+func DecodeWireTransactionString(payload, encoding string) (*SolanaTransaction, error) {
+	var data []byte
+	var err error
+	switch encoding {
+	case "base64":
+		data, err = base64.StdEncoding.DecodeString(payload)
+	case "base58", "binary":
+		data, err = base58.Decode(payload)
+	default:
+		return nil, fmt.Errorf("unsupported transaction encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s transaction: %w", encoding, err)
+	}
+	return DecodeWireTransaction(data)
+}
+
+// ReadWireTransaction decodes the wire transaction at the start of data and returns the number
+// of bytes it used, for transactions serialized back to back such as in ledger entries
+func ReadWireTransaction(data []byte) (*SolanaTransaction, int, error) {
+	r := &wireReader{data: data}
+	txData, version, err := r.transaction()
+	if err != nil {
+		return nil, 0, err
+	}
+	return &SolanaTransaction{Transaction: txData, Version: version}, r.pos, nil
+}
+
+// UnmarshalJSON accepts the ["<payload>", "<encoding>"] form of getTransaction responses with a
+// binary encoding, besides the json and jsonParsed objects
+func (d *TransactionData) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		var encoded []string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return err
+		}
+		if len(encoded) != 2 {
+			return fmt.Errorf("expected [payload, encoding], got %d elements", len(encoded))
+		}
+		tx, err := DecodeWireTransactionString(encoded[0], encoded[1])
+		if err != nil {
+			return err
+		}
+		*d = tx.Transaction
+		return nil
+	}
+	type transactionData TransactionData
+	return json.Unmarshal(data, (*transactionData)(d))
+}
+
+// wireReader reads the wire format: compact-u16 length prefixes and fixed size keys
+type wireReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wireReader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errWireTruncated
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wireReader) bytes(n int) ([]byte, error) {
+	if n > len(r.data)-r.pos {
+		return nil, errWireTruncated
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// compactU16 reads a compact-u16 (shortvec) length: 7 bits per byte, at most 3 bytes
+func (r *wireReader) compactU16() (int, error) {
+	value := 0
+	for i := 0; i < 3; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		if i == 2 && b > 0x03 {
+			return 0, errors.New("compact-u16 overflows u16")
+		}
+		value |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			if i > 0 && b == 0 {
+				return 0, errors.New("non-canonical compact-u16")
+			}
+			return value, nil
+		}
+	}
+	return 0, errors.New("compact-u16 overflows u16")
+}
+
+func (r *wireReader) byteArray() ([]byte, error) {
+	n, err := r.compactU16()
+	if err != nil {
+		return nil, err
+	}
+	return r.bytes(n)
+}
+
+func (r *wireReader) indexes() ([]int, error) {
+	b, err := r.byteArray()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]int, len(b))
+	for i, v := range b {
+		result[i] = int(v)
+	}
+	return result, nil
+}
+
+func (r *wireReader) pubkeys() ([]string, error) {
+	n, err := r.compactU16()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, n)
+	for i := range keys {
+		b, err := r.bytes(wirePubkeyLength)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = base58.Encode(b)
+	}
+	return keys, nil
+}
+
+// transaction reads the signatures and the message, returning the message version as
+// "legacy" or 0 like the RPC version field
+func (r *wireReader) transaction() (TransactionData, interface{}, error) {
+	var txData TransactionData
+	count, err := r.compactU16()
+	if err != nil {
+		return txData, nil, err
+	}
+	txData.Signatures = make([]string, count)
+	for i := range txData.Signatures {
+		sig, err := r.bytes(wireSignatureLength)
+		if err != nil {
+			return txData, nil, err
+		}
+		txData.Signatures[i] = base58.Encode(sig)
+	}
+
+	prefix, err := r.byte()
+	if err != nil {
+		return txData, nil, err
+	}
+	var version interface{} = "legacy"
+	versioned := prefix&wireVersionPrefix != 0
+	if versioned {
+		if v := prefix &^ wireVersionPrefix; v != 0 {
+			return txData, nil, fmt.Errorf("unsupported message version %d", v)
+		}
+		version = float64(0) // as json.Unmarshal reports it, like ConvertYellowstoneTransaction
+	} else {
+		r.pos-- // the prefix is the first header byte of a legacy message
+	}
+
+	header, err := r.bytes(3)
+	if err != nil {
+		return txData, nil, err
+	}
+	msg := &txData.Message
+	msg.Header = &MessageHeader{
+		NumRequiredSignatures:       int(header[0]),
+		NumReadonlySignedAccounts:   int(header[1]),
+		NumReadonlyUnsignedAccounts: int(header[2]),
+	}
+	keys, err := r.pubkeys()
+	if err != nil {
+		return txData, nil, err
+	}
+	if h := msg.Header; h.NumRequiredSignatures+h.NumReadonlyUnsignedAccounts > len(keys) || h.NumReadonlySignedAccounts > h.NumRequiredSignatures {
+		return txData, nil, fmt.Errorf("message header %+v does not fit %d account keys", *h, len(keys))
+	}
+	blockhash, err := r.bytes(wirePubkeyLength)
+	if err != nil {
+		return txData, nil, err
+	}
+	msg.RecentBlockhash = base58.Encode(blockhash)
+
+	count, err = r.compactU16()
+	if err != nil {
+		return txData, nil, err
+	}
+	msg.CompiledInstructions = make([]CompiledInstruction, count)
+	for i := range msg.CompiledInstructions {
+		programIdIndex, err := r.byte()
+		if err != nil {
+			return txData, nil, err
+		}
+		accounts, err := r.indexes()
+		if err != nil {
+			return txData, nil, err
+		}
+		data, err := r.byteArray()
+		if err != nil {
+			return txData, nil, err
+		}
+		msg.CompiledInstructions[i] = CompiledInstruction{
+			ProgramIdIndex: int(programIdIndex),
+			Accounts:       accounts,
+			Data:           base58.Encode(data),
+		}
+	}
+
+	if !versioned {
+		msg.AccountKeys = legacyAccountKeys(keys, msg.Header)
+		return txData, version, nil
+	}
+
+	msg.StaticAccountKeys = keys
+	count, err = r.compactU16()
+	if err != nil {
+		return txData, nil, err
+	}
+	msg.AddressTableLookups = make([]AddressTableLookup, count)
+	for i := range msg.AddressTableLookups {
+		key, err := r.bytes(wirePubkeyLength)
+		if err != nil {
+			return txData, nil, err
+		}
+		writable, err := r.indexes()
+		if err != nil {
+			return txData, nil, err
+		}
+		readonly, err := r.indexes()
+		if err != nil {
+			return txData, nil, err
+		}
+		msg.AddressTableLookups[i] = AddressTableLookup{
+			AccountKey:      base58.Encode(key),
+			WritableIndexes: writable,
+			ReadonlyIndexes: readonly,
+		}
+	}
+	return txData, version, nil
+}
+
+// legacyAccountKeys sets the signer and writable flags that the header implies for each key
+func legacyAccountKeys(keys []string, header *MessageHeader) []AccountKey {
+	result := make([]AccountKey, len(keys))
+	signers := header.NumRequiredSignatures
+	for i, key := range keys {
+		writable := i < signers-header.NumReadonlySignedAccounts ||
+			(i >= signers && i < len(keys)-header.NumReadonlyUnsignedAccounts)
+		result[i] = AccountKey{Pubkey: key, Signer: i < signers, Writable: writable}
+	}
+	return result
+}
@@ -0,0 +1,343 @@
+// Package aggregate builds OHLCV candles from parsed trades.
+//
+// An Aggregator consumes ParseResults, keeps candles at several intervals keyed by pool or by
+// base mint and emits each candle on a channel once its interval has ended and a grace window
+// for late results has passed, measured by trade timestamps.
+package aggregate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// KeyBy selects what candles are grouped by
+type KeyBy string
+
+const (
+	// KeyByPool groups trades by their first pool address
+	KeyByPool KeyBy = "pool"
+	// KeyByMint groups trades by base mint and quote mint across pools
+	KeyByMint KeyBy = "mint"
+)
+
+// Config configures an Aggregator
+type Config struct {
+	// Intervals are the candle lengths, in whole seconds
+	Intervals []time.Duration
+
+	// KeyBy selects grouping by pool or by base mint
+	KeyBy KeyBy
+
+	// Grace is how long after an interval ends, by trade timestamps, trades for it are still
+	// accepted; later ones are dropped and counted by Late
+	Grace time.Duration
+
+	// Buffer is the capacity of the candle channel
+	Buffer int
+}
+
+// DefaultConfig returns the default aggregator configuration
+func DefaultConfig() Config {
+	return Config{
+		Intervals: []time.Duration{time.Second, time.Minute, 5 * time.Minute, time.Hour},
+		KeyBy:     KeyByPool,
+		Grace:     5 * time.Second,
+		Buffer:    1024,
+	}
+}
+
+// Candle is the OHLCV summary of the trades of one pool or mint in one interval. Prices are in
+// quote per base token and volumes in UI amounts.
+type Candle struct {
+	Key       string        `json:"key"` // pool address, or base mint with KeyByMint
+	BaseMint  string        `json:"baseMint"`
+	QuoteMint string        `json:"quoteMint"`
+	Interval  time.Duration `json:"interval"`
+	Start     int64         `json:"start"` // unix timestamp of the interval start
+
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+
+	Volume      float64 `json:"volume"`      // base volume
+	QuoteVolume float64 `json:"quoteVolume"` // quote volume
+	BuyVolume   float64 `json:"buyVolume"`   // quote spent on buys of the base token
+	SellVolume  float64 `json:"sellVolume"`  // quote received from sells of the base token
+
+	Trades        int `json:"trades"`
+	Buys          int `json:"buys"`
+	Sells         int `json:"sells"`
+	UniqueTraders int `json:"uniqueTraders"`
+
+	FirstSlot uint64 `json:"firstSlot"`
+	LastSlot  uint64 `json:"lastSlot"`
+}
+
+// candleKey identifies an open candle
+type candleKey struct {
+	interval time.Duration
+	key      string
+	quote    string
+	start    int64
+}
+
+// order places a trade within a candle: by slot, then by arrival
+type order struct {
+	slot uint64
+	seq  uint64
+}
+
+func (o order) before(other order) bool {
+	return o.slot < other.slot || (o.slot == other.slot && o.seq < other.seq)
+}
+
+// openCandle is a candle still accepting trades
+type openCandle struct {
+	Candle
+	first, last order
+	traders     map[string]struct{}
+}
+
+// Aggregator builds candles from results. It is safe for concurrent use. Candles are sent while
+// holding the aggregator's lock, so Add, Flush and Close block while the channel is full and the
+// goroutine reading it must not call the Aggregator.
+type Aggregator struct {
+	config Config
+
+	mu        sync.Mutex
+	candles   chan Candle
+	open      map[candleKey]*openCandle
+	watermark int64
+	flushed   int64 // watermark at the last Flush
+	seq       uint64
+	late      uint64
+	closed    bool
+}
+
+// NewAggregator creates an Aggregator. Intervals shorter than a second are ignored.
+func NewAggregator(config Config) *Aggregator {
+	intervals := make([]time.Duration, 0, len(config.Intervals))
+	for _, interval := range config.Intervals {
+		if interval >= time.Second {
+			intervals = append(intervals, interval.Truncate(time.Second))
+		}
+	}
+	config.Intervals = intervals
+	if config.KeyBy == "" {
+		config.KeyBy = KeyByPool
+	}
+	if config.Buffer < 0 {
+		config.Buffer = 0
+	}
+	return &Aggregator{
+		config:  config,
+		candles: make(chan Candle, config.Buffer),
+		open:    make(map[candleKey]*openCandle),
+	}
+}
+
+// Candles returns the channel finalized candles are emitted on, ordered by interval start,
+// interval and key. It is closed by Close.
+func (a *Aggregator) Candles() <-chan Candle {
+	return a.candles
+}
+
+// Add adds the trades of a successful result. Trades without a timestamp or without a quote
+// token are skipped.
+func (a *Aggregator) Add(result *types.ParseResult) {
+	if result == nil || result.TxStatus == types.TransactionStatusFailed {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+
+	signer := ""
+	if len(result.Signer) > 0 {
+		signer = result.Signer[0]
+	}
+	watermark := a.watermark
+	for _, trade := range result.Trades {
+		if trade.Timestamp > watermark {
+			watermark = trade.Timestamp
+		}
+		a.addTrade(trade, result.Slot, signer)
+	}
+	if watermark > a.watermark {
+		a.watermark = watermark
+		a.finalize(false)
+	}
+}
+
+// AddBlock adds the results of a block in block order
+func (a *Aggregator) AddBlock(block *types.BlockResult) {
+	if block == nil {
+		return
+	}
+	for _, tx := range block.Transactions {
+		a.Add(tx.Result)
+	}
+}
+
+// Flush emits all open candles, for example at the end of a replay. Intervals starting at or
+// before the latest trade timestamp are closed by it: trades added afterwards for them are
+// dropped and counted by Late, so no candle is emitted twice.
+func (a *Aggregator) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.closed {
+		a.finalize(true)
+		a.flushed = a.watermark
+	}
+}
+
+// Close flushes the open candles and closes the candle channel; later results are ignored
+func (a *Aggregator) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	a.finalize(true)
+	a.closed = true
+	close(a.candles)
+}
+
+// Late returns how often a trade was dropped from an interval whose candle was already emitted
+// or closed by Flush
+func (a *Aggregator) Late() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.late
+}
+
+func (a *Aggregator) addTrade(trade types.TradeInfo, slot uint64, signer string) {
+	if trade.Timestamp <= 0 {
+		return
+	}
+	base, quote, buy, ok := utils.OrientTrade(trade)
+	if !ok || base.Amount <= 0 || quote.Amount <= 0 {
+		return
+	}
+	baseMint, quoteMint := base.Mint, quote.Mint
+	key := baseMint
+	if a.config.KeyBy == KeyByPool {
+		key = trade.ProgramId + ":" + baseMint
+		if len(trade.Pool) > 0 && trade.Pool[0] != "" {
+			key = trade.Pool[0]
+		}
+	}
+	trader := trade.User
+	if trader == "" {
+		trader = signer
+	}
+	if trade.Slot != 0 {
+		slot = trade.Slot
+	}
+	price := quote.Amount / base.Amount
+	a.seq++
+	at := order{slot: slot, seq: a.seq}
+
+	for _, interval := range a.config.Intervals {
+		seconds := int64(interval / time.Second)
+		start := trade.Timestamp - trade.Timestamp%seconds
+		if a.expired(start, interval) {
+			a.late++
+			continue
+		}
+
+		ck := candleKey{interval: interval, key: key, quote: quoteMint, start: start}
+		c, ok := a.open[ck]
+		if !ok {
+			c = &openCandle{
+				Candle: Candle{
+					Key:       key,
+					BaseMint:  baseMint,
+					QuoteMint: quoteMint,
+					Interval:  interval,
+					Start:     start,
+					Open:      price,
+					High:      price,
+					Low:       price,
+					Close:     price,
+					FirstSlot: slot,
+					LastSlot:  slot,
+				},
+				first:   at,
+				last:    at,
+				traders: make(map[string]struct{}),
+			}
+			a.open[ck] = c
+		}
+
+		if at.before(c.first) {
+			c.first, c.Open = at, price
+		}
+		if c.last.before(at) {
+			c.last, c.Close = at, price
+		}
+		c.High = max(c.High, price)
+		c.Low = min(c.Low, price)
+		c.FirstSlot = min(c.FirstSlot, slot)
+		c.LastSlot = max(c.LastSlot, slot)
+		c.Volume += base.Amount
+		c.QuoteVolume += quote.Amount
+		if buy {
+			c.Buys++
+			c.BuyVolume += quote.Amount
+		} else {
+			c.Sells++
+			c.SellVolume += quote.Amount
+		}
+		c.Trades++
+		if trader != "" {
+			c.traders[trader] = struct{}{}
+		}
+	}
+}
+
+// expired reports whether the candle starting at start has passed its grace window or was
+// closed by Flush
+func (a *Aggregator) expired(start int64, interval time.Duration) bool {
+	if a.flushed > 0 && start <= a.flushed {
+		return true
+	}
+	end := time.Duration(start)*time.Second + interval + a.config.Grace
+	return end <= time.Duration(a.watermark)*time.Second
+}
+
+// finalize emits expired candles, or all of them, in a deterministic order
+func (a *Aggregator) finalize(all bool) {
+	var ready []candleKey
+	for ck := range a.open {
+		if all || a.expired(ck.start, ck.interval) {
+			ready = append(ready, ck)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		x, y := ready[i], ready[j]
+		if x.start != y.start {
+			return x.start < y.start
+		}
+		if x.interval != y.interval {
+			return x.interval < y.interval
+		}
+		if x.key != y.key {
+			return x.key < y.key
+		}
+		return x.quote < y.quote
+	})
+	for _, ck := range ready {
+		c := a.open[ck]
+		delete(a.open, ck)
+		c.UniqueTraders = len(c.traders)
+		a.candles <- c.Candle
+	}
+}
@@ -0,0 +1,174 @@
+package dexparser
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// ParseBlock parses the transactions of a getBlock response. Vote transactions are skipped
+// before parsing, the rest are parsed concurrently with the block's slot and blockTime, and the
+// result keeps each transaction's position in the block along with per-block aggregates.
+// Atomic arbitrages that trade back against another signer's preceding trade are reclassified
+// as backruns.
+func (dp *DexParser) ParseBlock(block *adapter.SolanaBlock, config *types.ParseConfig) *types.BlockResult {
+	return dp.ParseBlockContext(context.Background(), block, config)
+}
+
+// ParseBlockContext is ParseBlock with cancellation; transactions not started when ctx is done
+// are marked Skipped
+func (dp *DexParser) ParseBlockContext(ctx context.Context, block *adapter.SolanaBlock, config *types.ParseConfig) *types.BlockResult {
+	result := &types.BlockResult{
+		Transactions: []types.BlockTransaction{},
+		Aggregates: types.BlockAggregates{
+			AMMs:      make(map[string]*types.AMMStats),
+			NewTokens: []string{},
+		},
+	}
+	if block == nil {
+		return result
+	}
+	result.Slot = block.Slot
+	result.ParentSlot = block.ParentSlot
+	result.Blockhash = block.Blockhash
+	if block.BlockTime != nil {
+		result.BlockTime = *block.BlockTime
+	}
+
+	// Copies carry the block fields so the caller's transactions are left untouched
+	txs := make([]*adapter.SolanaTransaction, 0, len(block.Transactions))
+	positions := make([]int, 0, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		if adapter.IsVoteTransaction(tx) {
+			result.VoteCount++
+			continue
+		}
+		copied := *tx
+		copied.Slot = block.Slot
+		copied.BlockTime = block.BlockTime
+		copied.Index = uint64(i)
+		txs = append(txs, &copied)
+		positions = append(positions, i)
+	}
+
+	results := dp.ParseBatchContext(ctx, txs, config, runtime.GOMAXPROCS(0))
+	result.Transactions = make([]types.BlockTransaction, len(results))
+	for i, parsed := range results {
+		result.Transactions[i] = types.BlockTransaction{Index: positions[i], Result: parsed}
+		dp.aggregateBlock(ctx, &result.Aggregates, txs[i], parsed, config)
+	}
+	markBackruns(result.Transactions)
+	return result
+}
+
+// backrunDistance is how many preceding transactions may hold the target of a backrun; a bundle
+// holds up to five transactions
+const backrunDistance = 4
+
+// markBackruns reclassifies atomic arbitrages that follow another signer's trade on one of their
+// pools in the opposite direction, which the arbitrage moves back
+func markBackruns(txs []types.BlockTransaction) {
+	for i, tx := range txs {
+		arb := tx.Result.Arbitrage
+		if arb == nil || arb.Type != types.ArbitrageTypeAtomic {
+			continue
+		}
+		for j := i - 1; j >= 0 && j >= i-backrunDistance; j-- {
+			target := txs[j].Result
+			if target.TxStatus == types.TransactionStatusFailed || target.Skipped || sameSigner(target, tx.Result) {
+				continue
+			}
+			if backruns(arb, target.Trades) {
+				arb.Type = types.ArbitrageTypeBackrun
+				arb.Target = target.Signature
+				break
+			}
+		}
+	}
+}
+
+// backruns reports whether a hop of arb trades against one of trades on the same pool
+func backruns(arb *types.ArbitrageInfo, trades []types.TradeInfo) bool {
+	for _, cycle := range arb.Cycles {
+		for _, hop := range cycle.Hops {
+			if hop.Pool == "" {
+				continue
+			}
+			for _, trade := range trades {
+				if len(trade.Pool) > 0 && trade.Pool[0] == hop.Pool &&
+					hop.InputMint == constants.NormalizeMint(trade.OutputToken.Mint) && hop.OutputMint == constants.NormalizeMint(trade.InputToken.Mint) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func sameSigner(a, b *types.ParseResult) bool {
+	return len(a.Signer) > 0 && len(b.Signer) > 0 && a.Signer[0] == b.Signer[0]
+}
+
+// aggregateBlock adds one transaction result to the block aggregates
+func (dp *DexParser) aggregateBlock(ctx context.Context, agg *types.BlockAggregates, tx *adapter.SolanaTransaction, result *types.ParseResult, config *types.ParseConfig) {
+	if result.TxStatus == types.TransactionStatusFailed {
+		agg.FailedTransactions++
+		if !result.Skipped && dp.invokesSwapProgram(ctx, tx, config) {
+			agg.FailedSwaps++
+		}
+		return
+	}
+
+	for _, trade := range result.Trades {
+		name := trade.AMM
+		if name == "" {
+			name = trade.ProgramId
+		}
+		stats, ok := agg.AMMs[name]
+		if !ok {
+			stats = &types.AMMStats{Volume: make(map[string]float64)}
+			agg.AMMs[name] = stats
+		}
+		stats.Trades++
+		agg.TradeCount++
+		if _, quote, _, ok := utils.OrientTrade(trade); ok {
+			stats.Volume[quote.Mint] += quote.Amount
+		}
+	}
+
+	for _, event := range result.MemeEvents {
+		if event.Type == types.TradeTypeCreate && event.BaseMint != "" && !containsString(agg.NewTokens, event.BaseMint) {
+			agg.NewTokens = append(agg.NewTokens, event.BaseMint)
+		}
+	}
+}
+
+// invokesSwapProgram reports whether tx invokes a program with a registered trade parser, or a
+// known AMM or router
+func (dp *DexParser) invokesSwapProgram(ctx context.Context, tx *adapter.SolanaTransaction, config *types.ParseConfig) bool {
+	if config == nil {
+		defaultConfig := types.DefaultParseConfig()
+		config = &defaultConfig
+	}
+	reg := dp.registry.Load()
+	adapt := adapter.NewTransactionAdapterContext(ctx, tx, config)
+	for _, programId := range classifier.NewInstructionClassifier(adapt).GetAllProgramIds() {
+		if _, ok := reg.trade[programId]; ok {
+			return true
+		}
+		for _, tag := range constants.GetDexProgramByID(programId).Tags {
+			if tag == "amm" || tag == "route" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -111,6 +111,11 @@ func (ic *InstructionClassifier) HasProgram(programId string) bool {
 	return ok
 }
 
+// GetEvents returns the log events ("Program data:" and "ray_log:") emitted by a program
+func (ic *InstructionClassifier) GetEvents(programId string) []types.ClassifiedEvent {
+	return ic.adapter.GetLogEvents(programId)
+}
+
 // GetAdapter returns the underlying TransactionAdapter
 func (ic *InstructionClassifier) GetAdapter() *adapter.TransactionAdapter {
 	return ic.adapter
@@ -0,0 +1,159 @@
+package constants
+
+// tokenErrors are the SPL Token program errors, shared by Token-2022
+var tokenErrors = map[uint32]string{
+	0:  "NotRentExempt",
+	1:  "InsufficientFunds",
+	2:  "InvalidMint",
+	3:  "MintMismatch",
+	4:  "OwnerMismatch",
+	5:  "FixedSupply",
+	6:  "AlreadyInUse",
+	7:  "InvalidNumberOfProvidedSigners",
+	8:  "InvalidNumberOfRequiredSigners",
+	9:  "UninitializedState",
+	10: "NativeNotSupported",
+	11: "NonNativeHasBalance",
+	12: "InvalidInstruction",
+	13: "InvalidState",
+	14: "Overflow",
+	15: "AuthorityTypeNotSupported",
+	16: "MintCannotFreeze",
+	17: "AccountFrozen",
+	18: "MintDecimalsMismatch",
+	19: "NonNativeNotSupported",
+}
+
+// PROGRAM_ERRORS maps program IDs to their custom error codes ({"Custom": code}) and names
+var PROGRAM_ERRORS = map[string]map[uint32]string{
+	SYSTEM_PROGRAM_ID: {
+		0: "AccountAlreadyInUse",
+		1: "ResultWithNegativeLamports",
+		2: "InvalidProgramId",
+		3: "InvalidAccountDataLength",
+		4: "MaxSeedLengthExceeded",
+		5: "AddressWithSeedMismatch",
+		6: "NonceNoRecentBlockhashes",
+		7: "NonceBlockhashNotExpired",
+		8: "NonceUnexpectedBlockhashValue",
+	},
+	TOKEN_PROGRAM_ID:      tokenErrors,
+	TOKEN_2022_PROGRAM_ID: tokenErrors,
+	DEX_PROGRAMS.PUMP_FUN.ID: {
+		6000: "NotAuthorized",
+		6001: "AlreadyInitialized",
+		6002: "TooMuchSolRequired",
+		6003: "TooLittleSolReceived",
+		6004: "MintDoesNotMatchBondingCurve",
+		6005: "BondingCurveComplete",
+		6006: "BondingCurveNotComplete",
+		6007: "NotInitialized",
+		6008: "WithdrawTooFrequent",
+		6020: "BuyZeroAmount",
+		6021: "NotEnoughTokensToBuy",
+		6022: "SellZeroAmount",
+		6023: "NotEnoughTokensToSell",
+	},
+	DEX_PROGRAMS.RAYDIUM_V4.ID: {
+		29: "InvalidInput",
+		30: "ExceededSlippage",
+		40: "InsufficientFunds",
+	},
+	DEX_PROGRAMS.RAYDIUM_CPMM.ID: {
+		6000: "NotApproved",
+		6001: "InvalidOwner",
+		6002: "EmptySupply",
+		6003: "InvalidInput",
+		6004: "IncorrectLpMint",
+		6005: "ExceededSlippage",
+		6006: "ZeroTradingTokens",
+		6007: "NotSupportMint",
+		6008: "InvalidVault",
+		6009: "InitLpAmountTooLess",
+	},
+	DEX_PROGRAMS.JUPITER.ID: {
+		6000: "EmptyRoute",
+		6001: "SlippageToleranceExceeded",
+		6002: "InvalidCalculation",
+		6003: "MissingPlatformFeeAccount",
+		6004: "InvalidSlippage",
+		6005: "NotEnoughPercent",
+		6006: "InvalidInputIndex",
+		6007: "InvalidOutputIndex",
+		6008: "NotEnoughAccountKeys",
+		6009: "NonZeroMinimumOutAmountNotSupported",
+		6010: "InvalidRoutePlan",
+		6011: "InvalidReferralAuthority",
+		6012: "LedgerTokenAccountDoesNotMatch",
+		6013: "InvalidTokenLedger",
+		6014: "IncorrectTokenProgramID",
+		6015: "TokenProgramNotProvided",
+		6016: "SwapNotSupported",
+		6017: "ExactOutAmountNotMatched",
+	},
+}
+
+// ANCHOR_ERRORS contains the Anchor framework error codes, raised by any Anchor program below 6000
+var ANCHOR_ERRORS = map[uint32]string{
+	100:  "InstructionMissing",
+	101:  "InstructionFallbackNotFound",
+	102:  "InstructionDidNotDeserialize",
+	103:  "InstructionDidNotSerialize",
+	2000: "ConstraintMut",
+	2001: "ConstraintHasOne",
+	2002: "ConstraintSigner",
+	2003: "ConstraintRaw",
+	2004: "ConstraintOwner",
+	2005: "ConstraintRentExempt",
+	2006: "ConstraintSeeds",
+	2007: "ConstraintExecutable",
+	2008: "ConstraintState",
+	2009: "ConstraintAssociated",
+	2010: "ConstraintAssociatedInit",
+	2011: "ConstraintClose",
+	2012: "ConstraintAddress",
+	2013: "ConstraintZero",
+	2014: "ConstraintTokenMint",
+	2015: "ConstraintTokenOwner",
+	3000: "AccountDiscriminatorAlreadySet",
+	3001: "AccountDiscriminatorNotFound",
+	3002: "AccountDiscriminatorMismatch",
+	3003: "AccountDidNotDeserialize",
+	3004: "AccountDidNotSerialize",
+	3005: "AccountNotEnoughKeys",
+	3006: "AccountNotMutable",
+	3007: "AccountOwnedByWrongProgram",
+	3008: "InvalidProgramId",
+	3009: "InvalidProgramExecutable",
+	3010: "AccountNotSigner",
+	3011: "AccountNotSystemOwned",
+	3012: "AccountNotInitialized",
+	3013: "AccountNotProgramData",
+	3014: "AccountNotAssociatedTokenAccount",
+	3015: "AccountSysvarMismatch",
+	3016: "AccountReallocExceedsLimit",
+	3017: "AccountDuplicateReallocs",
+}
+
+// anchorPrograms are the programs in PROGRAM_ERRORS built with Anchor, whose codes below 6000
+// are Anchor framework errors
+var anchorPrograms = map[string]bool{
+	DEX_PROGRAMS.PUMP_FUN.ID:     true,
+	DEX_PROGRAMS.RAYDIUM_CPMM.ID: true,
+	DEX_PROGRAMS.JUPITER.ID:      true,
+}
+
+// GetProgramErrorName returns the name of a custom program error. Codes below 6000 fall back to
+// the Anchor framework errors for Anchor programs and for programs without an error table;
+// native programs such as System, Token or Raydium V4 have tables and get no Anchor names.
+// Returns empty string if unknown.
+func GetProgramErrorName(programId string, code uint32) string {
+	table, ok := PROGRAM_ERRORS[programId]
+	if name, found := table[code]; found {
+		return name
+	}
+	if (!ok || anchorPrograms[programId]) && code >= 100 && code < 6000 {
+		return ANCHOR_ERRORS[code]
+	}
+	return ""
+}
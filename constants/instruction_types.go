@@ -39,6 +39,25 @@ const (
 	SystemCreateIdempotent         = 14
 )
 
+// ComputeBudget instruction types
+const (
+	ComputeBudgetRequestUnitsDeprecated         = 0
+	ComputeBudgetRequestHeapFrame               = 1
+	ComputeBudgetSetComputeUnitLimit            = 2
+	ComputeBudgetSetComputeUnitPrice            = 3
+	ComputeBudgetSetLoadedAccountsDataSizeLimit = 4
+)
+
+// Runtime compute unit limits
+const (
+	// DefaultInstructionComputeUnitLimit is the CU limit per non-builtin instruction when SetComputeUnitLimit is absent
+	DefaultInstructionComputeUnitLimit = 200_000
+	// MaxBuiltinInstructionComputeUnitLimit is the CU limit per builtin instruction when SetComputeUnitLimit is absent
+	MaxBuiltinInstructionComputeUnitLimit = 3_000
+	// MaxComputeUnitLimit is the maximum CU limit of a transaction
+	MaxComputeUnitLimit = 1_400_000
+)
+
 // IsSPLTransferInstruction checks if the instruction type is a transfer
 func IsSPLTransferInstruction(instructionType uint8) bool {
 	return instructionType == SPLTokenTransfer || instructionType == SPLTokenTransferChecked
@@ -492,6 +492,21 @@ var SYSTEM_PROGRAMS = []string{
 	"ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL",
 }
 
+// BUILTIN_PROGRAMS contains the native programs the runtime reserves only
+// MaxBuiltinInstructionComputeUnitLimit for when SetComputeUnitLimit is absent. Programs
+// migrated to core BPF (Stake, Config, Address Lookup Table) are not included.
+var BUILTIN_PROGRAMS = []string{
+	"11111111111111111111111111111111",
+	"ComputeBudget111111111111111111111111111111",
+	"Vote111111111111111111111111111111111111111",
+	"BPFLoader1111111111111111111111111111111111",
+	"BPFLoader2111111111111111111111111111111111",
+	"BPFLoaderUpgradeab1e11111111111111111111111",
+	"LoaderV411111111111111111111111111111111111",
+	"Ed25519SigVerify111111111111111111111111111",
+	"KeccakSecp256k11111111111111111111111111111",
+}
+
 // SKIP_PROGRAM_IDS contains program IDs that should be skipped
 var SKIP_PROGRAM_IDS = []string{
 	"pfeeUxB6jkeY1Hxd7CsFCAjcbHA9rWtchMGdZ6VojVZ", // Pumpswap Fee
@@ -511,6 +526,10 @@ const (
 	METAPLEX_PROGRAM_ID = "metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s"
 	// Address Lookup Table program
 	ALT_PROGRAM_ID = "AddressLookupTab1e1111111111111111111111111"
+	// Compute Budget program ID
+	COMPUTE_BUDGET_PROGRAM_ID = "ComputeBudget111111111111111111111111111111"
+	// Vote program ID
+	VOTE_PROGRAM_ID = "Vote111111111111111111111111111111111111111"
 )
 
 // PUMPFUN_MIGRATORS contains Pumpfun migrator addresses
@@ -519,17 +538,7 @@ var PUMPFUN_MIGRATORS = []string{
 }
 
 // FEE_ACCOUNTS contains known fee account addresses
-var FEE_ACCOUNTS = []string{
-	// Jitotip accounts
-	"96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5",
-	"HFqU5x63VTqvQss8hp11i4wVV8bD44PvwucfZ2bU7gRe",
-	"Cw8CFyM9FkoMi7K7Crf6HNQqf4uEMzpKw6QNghXLvLkY",
-	"ADaUMid9yfUytqMBgopwjb2DTLSokTSzL1zt6iGPaS49",
-	"DfXygSm4jCyNCybVYYK6DwvWqjKee8pbDmJGcLWNDXjh",
-	"ADuUkR4vqLUMWXxW9gh6D6L8pMSawimctcNZ5pGwDcEt",
-	"DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL",
-	"3AVi9Tg9Uo68tJfuvoKvqKNWKkC5wPdSSdeBnizKZ6jT",
-
+var FEE_ACCOUNTS = append(append([]string{}, TIP_ACCOUNTS["Jito"]...), // Jitotip accounts
 	// Jupiter Partner Referral Fee Vault
 	"45ruCyfdRkWpRNGEqWzjCiXRHkZs8WXCLQ67Pnpye7Hp",
 
@@ -553,7 +562,7 @@ var FEE_ACCOUNTS = []string{
 
 	// Meteora Fee Vault
 	"CdQTNULjDiTsvyR5UKjYBMqWvYpxXj6HY4m6atm2hErk",
-}
+)
 
 // dexProgramMap is a map for quick lookup of DEX programs by ID
 var dexProgramMap map[string]DexProgram
@@ -723,6 +732,16 @@ func IsSystemProgram(programId string) bool {
 	return false
 }
 
+// IsBuiltinProgram checks if a program ID is a builtin program
+func IsBuiltinProgram(programId string) bool {
+	for _, p := range BUILTIN_PROGRAMS {
+		if p == programId {
+			return true
+		}
+	}
+	return false
+}
+
 // IsFeeAccount checks if an account is a known fee account
 func IsFeeAccount(account string) bool {
 	for _, a := range FEE_ACCOUNTS {
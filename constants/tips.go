@@ -0,0 +1,57 @@
+package constants
+
+// TIP_ACCOUNTS maps block engine and relay providers to their public tip accounts. Other
+// providers can be added per parse with ParseConfig.TipAccounts.
+var TIP_ACCOUNTS = map[string][]string{
+	"Jito": {
+		"96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5",
+		"HFqU5x63VTqvQss8hp11i4wVV8bD44PvwucfZ2bU7gRe",
+		"Cw8CFyM9FkoMi7K7Crf6HNQqf4uEMzpKw6QNghXLvLkY",
+		"ADaUMid9yfUytqMBgopwjb2DTLSokTSzL1zt6iGPaS49",
+		"DfXygSm4jCyNCybVYYK6DwvWqjKee8pbDmJGcLWNDXjh",
+		"ADuUkR4vqLUMWXxW9gh6D6L8pMSawimctcNZ5pGwDcEt",
+		"DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL",
+		"3AVi9Tg9Uo68tJfuvoKvqKNWKkC5wPdSSdeBnizKZ6jT",
+	},
+	"bloXroute": {
+		"HWEoBxYs7ssKuudEjzjmpfJVX7Dvi7wescFsVx2L5yoY",
+		"95cfoy472fcQHaw4tPGBTKpn6ZQnfEPfBgDQx6gcRmRg",
+		"3UQUKjhMKaY2S6bjcQD6yHB7utcZt5bfarRCmctpRtUd",
+		"FogxVNs6Mm2w9rnGL1vkARSwJxvLE8mujTv3LK8RnUhF",
+	},
+	"Nextblock": {
+		"NextbLoCkVtMGcV47JzewQdvBpLqT9TxQFozQkN98pE",
+		"NexTbLoCkWykbLuB1NkjXgFWkX9oAtcoagQegygXXA2",
+		"NeXTBLoCKs9F1y5PJS9CKrFNNLU1keHW71rfh7KgA1X",
+		"NexTBLockJYZ7QD7p2byrUa6df8ndV2WSd8GkbWqfbb",
+		"neXtBLock1LeC67jYd1QdAa32kbVeubsfPNTJC1V5At",
+		"nEXTBLockYgngeRmRrjDV31mGSekVPqZoMGhQEZtPVG",
+		"NEXTbLoCkB51HpLBLojQfpyVAMorm3zzKg7w9NFdqid",
+		"nextBLoCkPMgmG8ZgJtABeScP35qLa2AMCNKntAP7Xc",
+	},
+	"0slot": {
+		"Eb2KpSC8uMt9GmzyAEm5Eb1AAAgTjRaXWFjKyFXHZxF3",
+		"FCjUJZ1qozm1e8romw216qyfQMaaWKxWsuySnumVCCNe",
+		"ENxTEjSQ1YabmUpXAdCgevnHQ9MHdLv8tzFiuiYJqa13",
+		"6rYLG55Q9RpsPGvqdPNJs4z5WTxJVatMB8zV3WJhs5EK",
+		"Cix2bHfqPcKcM233mzxbLk14kSggUUiz2A87fJtGivXr",
+	},
+}
+
+// tipAccountMap is a reverse lookup map for fast tip account detection
+var tipAccountMap map[string]string
+
+func init() {
+	tipAccountMap = make(map[string]string)
+	for provider, accounts := range TIP_ACCOUNTS {
+		for _, account := range accounts {
+			tipAccountMap[account] = provider
+		}
+	}
+}
+
+// GetTipProvider returns the provider name if the account is a known tip account
+// Returns empty string if not found
+func GetTipProvider(account string) string {
+	return tipAccountMap[account]
+}
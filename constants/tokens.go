@@ -59,6 +59,33 @@ func IsQuoteToken(mint string) bool {
 	return IsSOL(mint) || IsStablecoin(mint)
 }
 
+// IsUSDStablecoin checks if a token is a stablecoin pegged to USD
+func IsUSDStablecoin(mint string) bool {
+	return IsStablecoin(mint) && mint != TOKENS.EURC
+}
+
+// NormalizeMint returns wrapped SOL for native SOL, so both compare equal
+func NormalizeMint(mint string) string {
+	if IsSOL(mint) {
+		return TOKENS.SOL
+	}
+	return mint
+}
+
+// QuoteRank orders tokens by preference as the quote side of a pair: USD stablecoins, then SOL,
+// then other stablecoins; other tokens rank 0
+func QuoteRank(mint string) int {
+	switch {
+	case IsUSDStablecoin(mint):
+		return 3
+	case IsSOL(mint):
+		return 2
+	case IsStablecoin(mint):
+		return 1
+	}
+	return 0
+}
+
 // GetTokenDecimals returns the decimals for a known token, or 0 if unknown
 func GetTokenDecimals(mint string) (uint8, bool) {
 	decimals, ok := TOKEN_DECIMALS[mint]
@@ -1,8 +1,11 @@
 package dexparser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
 	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
@@ -29,19 +32,10 @@ import (
 // returns: true to continue processing, false to stop early
 type ParseCallback func(index int, tx *adapter.SolanaTransaction, result *types.ParseResult, err error) bool
 
-// DexParser is the main parser class for Solana DEX transactions
+// DexParser is the main parser class for Solana DEX transactions.
+// It is safe for concurrent use; each parse works on an immutable snapshot of the parser registry.
 type DexParser struct {
-	// Trade parsers by program ID
-	tradeParserFactories map[string]TradeParserFactory
-
-	// Liquidity parsers by program ID
-	liquidityParserFactories map[string]LiquidityParserFactory
-
-	// Transfer parsers by program ID
-	transferParserFactories map[string]TransferParserFactory
-
-	// Meme event parsers by program ID
-	memeEventParserFactories map[string]MemeEventParserFactory
+	registry atomic.Pointer[registry]
 }
 
 // TradeParserFactory creates a trade parser
@@ -73,215 +67,230 @@ type MemeEventParserFactory func(
 	transferActions map[string][]types.TransferData,
 ) parsers.EventParser
 
-// NewDexParser creates a new DexParser instance
-func NewDexParser() *DexParser {
-	dp := &DexParser{
-		tradeParserFactories:     make(map[string]TradeParserFactory, 20),
-		liquidityParserFactories: make(map[string]LiquidityParserFactory, 10),
-		transferParserFactories:  make(map[string]TransferParserFactory, 5),
-		memeEventParserFactories: make(map[string]MemeEventParserFactory, 10),
+// NewDexParser creates a new DexParser instance with the default parsers and the given options
+func NewDexParser(opts ...Option) *DexParser {
+	o := &parserOptions{}
+	for _, opt := range opts {
+		opt(o)
 	}
 
-	// Register default parsers
-	dp.registerDefaultParsers()
+	r := newRegistry()
+	if !o.withoutDefaults {
+		registerDefaultParsers(r)
+	}
+	for _, apply := range o.registrations {
+		apply(r)
+	}
 
+	dp := &DexParser{}
+	dp.registry.Store(r)
 	return dp
 }
 
 // registerDefaultParsers registers all default parsers
-func (dp *DexParser) registerDefaultParsers() {
+func registerDefaultParsers(r *registry) {
 	// Trade parsers
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.JUPITER.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.JUPITER.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return jupiter.NewJupiterParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.JUPITER_DCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.JUPITER_DCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return jupiter.NewJupiterDCAParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.JUPITER_VA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.JUPITER_VA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return jupiter.NewJupiterVAParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return jupiter.NewJupiterLimitOrderV2Parser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.PUMP_FUN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.PUMP_FUN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return pumpfun.NewPumpfunParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return pumpfun.NewPumpswapParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.METEORA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.METEORA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meteora.NewMeteoraParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.METEORA_DAMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.METEORA_DAMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meteora.NewMeteoraParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.METEORA_DAMM_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.METEORA_DAMM_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meteora.NewMeteoraParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.METEORA_DBC.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.METEORA_DBC.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meteora.NewMeteoraDBCParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_ROUTE.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_ROUTE.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_CL.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_CL.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_CPMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_CPMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_AMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_AMM.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.RAYDIUM_LCP.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.RAYDIUM_LCP.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return raydium.NewRaydiumLaunchpadParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.ORCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.ORCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return orca.NewOrcaParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.BOOP_FUN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.BOOP_FUN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meme.NewBoopfunParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.MOONIT.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.MOONIT.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meme.NewMoonitParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.HEAVEN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.HEAVEN.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meme.NewHeavenParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.SUGAR.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.SUGAR.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return meme.NewSugarParser(a, d, t, c)
 	}
 
 	// Prop AMM parsers
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.SOLFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.SOLFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return propamm.NewSolFiParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.GOONFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.GOONFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return propamm.NewGoonFiParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.OBRIC_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.OBRIC_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return propamm.NewObricParser(a, d, t, c)
 	}
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.HUMIDIFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.HUMIDIFI.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return propamm.NewHumidiFiParser(a, d, t, c)
 	}
 
 	// Aggregator parsers
-	dp.tradeParserFactories[constants.DEX_PROGRAMS.DFLOW.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
+	r.trade[constants.DEX_PROGRAMS.DFLOW.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TradeParser {
 		return dflow.NewDFlowParser(a, d, t, c)
 	}
 
 	// Liquidity parsers
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.METEORA.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.METEORA.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return meteora.NewMeteoraDLMMPoolParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.METEORA_DAMM.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.METEORA_DAMM.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return meteora.NewMeteoraPoolsParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.METEORA_DAMM_V2.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.METEORA_DAMM_V2.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return meteora.NewMeteoraDAMMPoolParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return raydium.NewRaydiumV4PoolParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.RAYDIUM_CPMM.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.RAYDIUM_CPMM.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return raydium.NewRaydiumCPMMPoolParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.RAYDIUM_CL.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.RAYDIUM_CL.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return raydium.NewRaydiumCLPoolParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.ORCA.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.ORCA.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return orca.NewOrcaLiquidityParser(a, t, c)
 	}
-	dp.liquidityParserFactories[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
+	r.liquidity[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.LiquidityParser {
 		return pumpfun.NewPumpswapLiquidityParser(a, t, c)
 	}
 
 	// Transfer parsers
-	dp.transferParserFactories[constants.DEX_PROGRAMS.JUPITER_DCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
+	r.transfer[constants.DEX_PROGRAMS.JUPITER_DCA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
 		return jupiter.NewJupiterDCAParser(a, d, t, c)
 	}
-	dp.transferParserFactories[constants.DEX_PROGRAMS.JUPITER_VA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
+	r.transfer[constants.DEX_PROGRAMS.JUPITER_VA.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
 		return jupiter.NewJupiterVAParser(a, d, t, c)
 	}
-	dp.transferParserFactories[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
+	r.transfer[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
 		return jupiter.NewJupiterLimitOrderParser(a, d, t, c)
 	}
-	dp.transferParserFactories[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
+	r.transfer[constants.DEX_PROGRAMS.JUPITER_LIMIT_ORDER_V2.ID] = func(a *adapter.TransactionAdapter, d types.DexInfo, t map[string][]types.TransferData, c []types.ClassifiedInstruction) parsers.TransferParser {
 		return jupiter.NewJupiterLimitOrderV2Parser(a, d, t, c)
 	}
 
 	// Meme event parsers
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.PUMP_FUN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.PUMP_FUN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return pumpfun.NewPumpfunEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return pumpfun.NewPumpswapEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.MOONIT.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.MOONIT.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return meme.NewMoonitEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.RAYDIUM_LCP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.RAYDIUM_LCP.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return raydium.NewRaydiumLaunchpadEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.METEORA_DBC.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.METEORA_DBC.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return meteora.NewMeteoraDBCEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.BOOP_FUN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.BOOP_FUN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return meme.NewBoopfunEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.SUGAR.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.SUGAR.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return meme.NewSugarEventParser(a, t)
 	}
-	dp.memeEventParserFactories[constants.DEX_PROGRAMS.HEAVEN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
+	r.meme[constants.DEX_PROGRAMS.HEAVEN.ID] = func(a *adapter.TransactionAdapter, t map[string][]types.TransferData) parsers.EventParser {
 		return meme.NewHeavenEventParser(a, t)
 	}
 }
 
-// RegisterTradeParser registers a trade parser for a program ID
+// RegisterTradeParser registers a trade parser for a program ID.
+// Deprecated: Use NewDexParser(WithTradeParser(...)) instead.
 func (dp *DexParser) RegisterTradeParser(programId string, factory TradeParserFactory) {
-	dp.tradeParserFactories[programId] = factory
+	dp.update(func(r *registry) { r.trade[programId] = factory })
 }
 
-// RegisterLiquidityParser registers a liquidity parser for a program ID
+// RegisterLiquidityParser registers a liquidity parser for a program ID.
+// Deprecated: Use NewDexParser(WithLiquidityParser(...)) instead.
 func (dp *DexParser) RegisterLiquidityParser(programId string, factory LiquidityParserFactory) {
-	dp.liquidityParserFactories[programId] = factory
+	dp.update(func(r *registry) { r.liquidity[programId] = factory })
 }
 
-// RegisterTransferParser registers a transfer parser for a program ID
+// RegisterTransferParser registers a transfer parser for a program ID.
+// Deprecated: Use NewDexParser(WithTransferParser(...)) instead.
 func (dp *DexParser) RegisterTransferParser(programId string, factory TransferParserFactory) {
-	dp.transferParserFactories[programId] = factory
+	dp.update(func(r *registry) { r.transfer[programId] = factory })
 }
 
-// RegisterMemeEventParser registers a meme event parser for a program ID
+// RegisterMemeEventParser registers a meme event parser for a program ID.
+// Deprecated: Use NewDexParser(WithMemeParser(...)) instead.
 func (dp *DexParser) RegisterMemeEventParser(programId string, factory MemeEventParserFactory) {
-	dp.memeEventParserFactories[programId] = factory
+	dp.update(func(r *registry) { r.meme[programId] = factory })
 }
 
 // ParseTrades parses trades from a transaction
 func (dp *DexParser) ParseTrades(tx *adapter.SolanaTransaction, config *types.ParseConfig) []types.TradeInfo {
-	result := dp.parseWithClassifier(tx, config, "trades")
+	result := dp.parseWithClassifier(context.Background(), tx, config, "trades")
 	return result.Trades
 }
 
 // ParseLiquidity parses liquidity events from a transaction
 func (dp *DexParser) ParseLiquidity(tx *adapter.SolanaTransaction, config *types.ParseConfig) []types.PoolEvent {
-	result := dp.parseWithClassifier(tx, config, "liquidity")
+	result := dp.parseWithClassifier(context.Background(), tx, config, "liquidity")
 	return result.Liquidities
 }
 
 // ParseTransfers parses transfers from a transaction
 func (dp *DexParser) ParseTransfers(tx *adapter.SolanaTransaction, config *types.ParseConfig) []types.TransferData {
-	result := dp.parseWithClassifier(tx, config, "transfer")
+	result := dp.parseWithClassifier(context.Background(), tx, config, "transfer")
 	return result.Transfers
 }
 
 // ParseAll parses all data from a transaction
 func (dp *DexParser) ParseAll(tx *adapter.SolanaTransaction, config *types.ParseConfig) *types.ParseResult {
-	return dp.parseWithClassifier(tx, config, "all")
+	return dp.parseWithClassifier(context.Background(), tx, config, "all")
+}
+
+// ParseAllContext parses all data from a transaction, passing ctx to fetcher calls.
+// If ctx is already done the transaction is not parsed and the result is marked Skipped.
+func (dp *DexParser) ParseAllContext(ctx context.Context, tx *adapter.SolanaTransaction, config *types.ParseConfig) *types.ParseResult {
+	return dp.parseWithClassifier(ctx, tx, config, "all")
 }
 
 // ParseBatch parses multiple transactions concurrently
@@ -298,6 +307,51 @@ func (dp *DexParser) ParseBatchWithCallback(
 	config *types.ParseConfig,
 	maxWorkers int,
 	callback ParseCallback,
+) []*types.ParseResult {
+	return dp.parseBatch(context.Background(), txs, config, maxWorkers, callback)
+}
+
+// ParseBatchContext parses multiple transactions concurrently until ctx is done.
+// No new transactions are scheduled after cancellation; their results are marked Skipped,
+// while transactions already being parsed complete with ctx passed to fetcher calls.
+func (dp *DexParser) ParseBatchContext(
+	ctx context.Context,
+	txs []*adapter.SolanaTransaction,
+	config *types.ParseConfig,
+	maxWorkers int,
+) []*types.ParseResult {
+	return dp.ParseBatchWithCallbackContext(ctx, txs, config, maxWorkers, nil)
+}
+
+// ParseBatchWithCallbackContext is ParseBatchWithCallback with cancellation; transactions
+// not started because ctx was done or the callback stopped the batch are marked Skipped
+func (dp *DexParser) ParseBatchWithCallbackContext(
+	ctx context.Context,
+	txs []*adapter.SolanaTransaction,
+	config *types.ParseConfig,
+	maxWorkers int,
+	callback ParseCallback,
+) []*types.ParseResult {
+	results := dp.parseBatch(ctx, txs, config, maxWorkers, callback)
+	for i, result := range results {
+		if result == nil {
+			err := ctx.Err()
+			if err == nil {
+				err = errBatchStopped
+			}
+			results[i] = newSkippedResult(txs[i], err)
+		}
+	}
+	return results
+}
+
+// parseBatch dispatches to sequential or concurrent processing; unstarted results are nil
+func (dp *DexParser) parseBatch(
+	ctx context.Context,
+	txs []*adapter.SolanaTransaction,
+	config *types.ParseConfig,
+	maxWorkers int,
+	callback ParseCallback,
 ) []*types.ParseResult {
 	if len(txs) == 0 {
 		return []*types.ParseResult{}
@@ -305,14 +359,29 @@ func (dp *DexParser) ParseBatchWithCallback(
 
 	// Optimize for single worker case
 	if maxWorkers <= 1 {
-		return dp.parseSequentiallyWithCallback(txs, config, callback)
+		return dp.parseSequentiallyWithCallback(ctx, txs, config, callback)
 	}
 
-	return dp.parseConcurrentlyWithCallback(txs, config, maxWorkers, callback)
+	return dp.parseConcurrentlyWithCallback(ctx, txs, config, maxWorkers, callback)
+}
+
+// parseOne parses a single batch transaction, converting a panic into an error result
+func (dp *DexParser) parseOne(ctx context.Context, index int, tx *adapter.SolanaTransaction, config *types.ParseConfig) (result *types.ParseResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in transaction %d: %v", index, r)
+			result = types.NewParseResult()
+			result.State = false
+			result.Msg = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	return dp.ParseAllContext(ctx, tx, config), nil
 }
 
 // parseSequentiallyWithCallback processes transactions one by one with callback
 func (dp *DexParser) parseSequentiallyWithCallback(
+	ctx context.Context,
 	txs []*adapter.SolanaTransaction,
 	config *types.ParseConfig,
 	callback ParseCallback,
@@ -320,23 +389,11 @@ func (dp *DexParser) parseSequentiallyWithCallback(
 	results := make([]*types.ParseResult, len(txs))
 
 	for i, tx := range txs {
-		var result *types.ParseResult
-		var err error
-
-		// Handle panic gracefully
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					err = fmt.Errorf("panic in transaction %d: %v", i, r)
-					result = types.NewParseResult()
-					result.State = false
-					result.Msg = fmt.Sprintf("panic: %v", r)
-				}
-			}()
-
-			result = dp.ParseAll(tx, config)
-		}()
+		if ctx.Err() != nil {
+			break
+		}
 
+		result, err := dp.parseOne(ctx, i, tx, config)
 		results[i] = result
 
 		// Call callback function if provided
@@ -351,102 +408,109 @@ func (dp *DexParser) parseSequentiallyWithCallback(
 	return results
 }
 
-// parseConcurrentlyWithCallback processes transactions using goroutines with callback
+// parseConcurrentlyWithCallback processes transactions on a pool of maxWorkers goroutines with callback.
+// Scheduling stops as soon as ctx is done or the callback requests early termination.
 func (dp *DexParser) parseConcurrentlyWithCallback(
+	ctx context.Context,
 	txs []*adapter.SolanaTransaction,
 	config *types.ParseConfig,
 	maxWorkers int,
 	callback ParseCallback,
 ) []*types.ParseResult {
-	semaphore := make(chan struct{}, maxWorkers)
+	if maxWorkers > len(txs) {
+		maxWorkers = len(txs)
+	}
+
+	jobs := make(chan int)
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 	var callbackMu sync.Mutex
 
-	// Pre-allocate results slice
+	// Pre-allocate results slice; each index is written by exactly one worker
 	results := make([]*types.ParseResult, len(txs))
 	var shouldStop bool
+	stopped := func() bool {
+		callbackMu.Lock()
+		defer callbackMu.Unlock()
+		return shouldStop
+	}
 
-	for i, tx := range txs {
+	for w := 0; w < maxWorkers; w++ {
 		wg.Add(1)
-		go func(index int, transaction *adapter.SolanaTransaction) {
+		go func() {
 			defer wg.Done()
-
-			// Check if we should stop early
-			callbackMu.Lock()
-			if shouldStop {
-				callbackMu.Unlock()
-				return
-			}
-			callbackMu.Unlock()
-
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			var result *types.ParseResult
-			var err error
-
-			// Handle panic gracefully
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						err = fmt.Errorf("panic in transaction %d: %v", index, r)
-						result = types.NewParseResult()
-						result.State = false
-						result.Msg = fmt.Sprintf("panic: %v", r)
-					}
-				}()
-
-				result = dp.ParseAll(transaction, config)
-			}()
-
-			// Store result
-			mu.Lock()
-			results[index] = result
-			mu.Unlock()
-
-			// Call callback function if provided
-			if callback != nil {
-				callbackMu.Lock()
-				if !shouldStop {
-					if !callback(index, transaction, result, err) {
-						// Early termination requested
-						shouldStop = true
+			for index := range jobs {
+				transaction := txs[index]
+				result, err := dp.parseOne(ctx, index, transaction, config)
+				results[index] = result
+
+				// Call callback function if provided
+				if callback != nil {
+					callbackMu.Lock()
+					if !shouldStop {
+						if !callback(index, transaction, result, err) {
+							// Early termination requested
+							shouldStop = true
+						}
 					}
+					callbackMu.Unlock()
 				}
-				callbackMu.Unlock()
 			}
-		}(i, tx)
+		}()
+	}
+
+schedule:
+	for i := range txs {
+		if stopped() {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break schedule
+		case jobs <- i:
+		}
 	}
+	close(jobs)
 
-	// Wait for all goroutines to complete
+	// Wait for in-flight transactions to complete
 	wg.Wait()
 
 	return results
 }
 
 // parseWithClassifier is the main parsing logic
-func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *types.ParseConfig, parseType string) *types.ParseResult {
+func (dp *DexParser) parseWithClassifier(ctx context.Context, tx *adapter.SolanaTransaction, config *types.ParseConfig, parseType string) (result *types.ParseResult) {
+	if err := ctx.Err(); err != nil {
+		return newSkippedResult(tx, err)
+	}
 	if config == nil {
 		defaultConfig := types.DefaultParseConfig()
 		config = &defaultConfig
 	}
 
-	result := types.NewParseResult()
+	result = types.NewParseResult()
+	if tx == nil {
+		failResult(result, types.NewParseError(types.ParseErrorMalformedInput, "", "", errNilTransaction), "Parse error: nil transaction")
+		return result
+	}
 	result.Slot = tx.Slot
 
+	if !isSupportedVersion(tx.Version) {
+		err := fmt.Errorf("unsupported transaction version: %v", tx.Version)
+		failResult(result, types.NewParseError(types.ParseErrorUnsupportedVersion, "", "", err), err.Error())
+		return result
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			if config.ThrowError {
 				panic(r)
 			}
-			result.State = false
-			result.Msg = fmt.Sprintf("Parse error: %v", r)
+			failResult(result, types.NewPanicError(types.ParseErrorDecoderPanic, "", "", r), fmt.Sprintf("Parse error: %v", r))
 		}
 	}()
 
-	adapt := adapter.NewTransactionAdapter(tx, config)
+	reg := dp.registry.Load()
+	adapt := adapter.NewTransactionAdapterContext(ctx, tx, config)
 	txUtils := utils.NewTransactionUtils(adapt)
 	instrClassifier := classifier.NewInstructionClassifier(adapt)
 
@@ -454,11 +518,21 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 	dexInfo := txUtils.GetDexInfo(instrClassifier)
 	allProgramIds := instrClassifier.GetAllProgramIds()
 
+	// Trace instruction handling when requested
+	tr := newTracer(config.Trace, adapt, instrClassifier, traceProgramIds(instrClassifier, allProgramIds))
+	tr.setDexProgram(dexInfo.ProgramId)
+	defer func() { result.Trace = tr.finish(result, adapt) }()
+
 	result.Timestamp = adapt.BlockTime()
 	result.Signature = adapt.Signature()
 	result.Signer = adapt.Signers()
 	result.ComputeUnits = adapt.ComputeUnits()
 	result.TxStatus = adapt.TxStatus()
+	result.TxError = adapt.TxError()
+	reg.resolveTxError(result.TxError)
+	if config.CallTree {
+		result.CallTree = adapt.CallTree()
+	}
 
 	// Check program ID filter
 	if len(config.ProgramIds) > 0 {
@@ -475,8 +549,8 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 			}
 		}
 		if !found {
-			result.State = false
-			result.Msg = "No matching program ids"
+			failResult(result, types.NewParseError(types.ParseErrorFiltered, "", "", errNoMatchingProgramIds), errNoMatchingProgramIds.Error())
+			tr.skipAll(types.TraceReasonTransactionFiltered)
 			return result
 		}
 	}
@@ -496,8 +570,8 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 			}
 		}
 		if !found {
-			result.State = false
-			result.Msg = "No matching accounts include"
+			failResult(result, types.NewParseError(types.ParseErrorFiltered, "", "", errNoMatchingAccounts), errNoMatchingAccounts.Error())
+			tr.skipAll(types.TraceReasonTransactionFiltered)
 			return result
 		}
 	}
@@ -507,8 +581,8 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 		for _, excludeAccount := range config.AccountExclude {
 			for _, accountKey := range adapt.AccountKeys {
 				if excludeAccount == accountKey {
-					result.State = false
-					result.Msg = "Account excluded"
+					failResult(result, types.NewParseError(types.ParseErrorFiltered, "", "", errAccountExcluded), errAccountExcluded.Error())
+					tr.skipAll(types.TraceReasonTransactionFiltered)
 					return result
 				}
 			}
@@ -517,9 +591,13 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 
 	// Get transfer actions
 	transferActions := txUtils.GetTransferActions([]string{"mintTo", "burn", "mintToChecked", "burnChecked"})
+	tr.setTransferActions(transferActions)
 
 	// Process fee
 	result.Fee = adapt.Fee()
+	result.ComputeBudget = adapt.ComputeBudget()
+	result.BaseFee, result.PriorityFee = adapt.FeeSplit(result.ComputeBudget)
+	result.Tips = txUtils.GetTips(config.TipAccounts)
 
 	// Process balance changes
 	result.SolBalanceChange = adapt.GetAccountSolBalanceChanges(false)[adapt.Signer()]
@@ -551,28 +629,36 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 	if dexInfo.ProgramId != "" && containsString(jupiterProgramIds, dexInfo.ProgramId) {
 		if shouldParseTrades {
 			jupiterInstructions := instrClassifier.GetInstructions(dexInfo.ProgramId)
-			if factory, ok := dp.tradeParserFactories[dexInfo.ProgramId]; ok {
+			if factory, ok := reg.trade[dexInfo.ProgramId]; ok {
 				dexInfoWithAMM := types.DexInfo{
 					ProgramId: dexInfo.ProgramId,
 					AMM:       constants.GetProgramName(dexInfo.ProgramId),
 					Route:     dexInfo.Route,
 				}
-				parser := factory(adapt, dexInfoWithAMM, transferActions, jupiterInstructions)
-				trades := parser.ProcessTrades()
+				var trades []types.TradeInfo
+				tr.handled(dexInfo.ProgramId, types.TraceHandlerTrade)
+				runParser(result, config, dexInfo.ProgramId, jupiterInstructions, func() {
+					trades = factory(adapt, dexInfoWithAMM, transferActions, jupiterInstructions).ProcessTrades()
+				})
+				tr.emitted(dexInfo.ProgramId, tradeIdxs(trades)...)
 				if len(trades) > 0 {
+					txUtils.AttachPoolInfo(trades, nil)
 					shouldAggregate := config.ShouldAggregateTrades() || effectiveParseType.AggregateTrade
 					if shouldAggregate {
 						aggregateTrade := utils.GetFinalSwap(trades, &dexInfo)
 						if aggregateTrade != nil {
 							result.AggregateTrade = txUtils.AttachTradeFee(aggregateTrade)
+							utils.AttachTips(result.AggregateTrade, result.Tips)
 						}
 					} else {
 						result.Trades = append(result.Trades, trades...)
 					}
+					result.Arbitrage = utils.GetArbitrage(result, trades)
 				}
 			}
 		}
 		if len(result.Trades) > 0 || result.AggregateTrade != nil {
+			tr.jupiterEarlyReturn(dexInfo.ProgramId)
 			return result
 		}
 	}
@@ -581,26 +667,37 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 	for _, programId := range allProgramIds {
 		// Check program ID filters
 		if len(config.ProgramIds) > 0 && !containsString(config.ProgramIds, programId) {
+			tr.skipProgram(programId, types.TraceReasonProgramFiltered)
 			continue
 		}
 		if len(config.IgnoreProgramIds) > 0 && containsString(config.IgnoreProgramIds, programId) {
+			tr.skipProgram(programId, types.TraceReasonProgramFiltered)
 			continue
 		}
+		if !reg.hasParser(programId) && !(shouldParseTrades && config.TryUnknownDEX) {
+			tr.skipProgram(programId, types.TraceReasonNoParser)
+		}
 
 		classifiedInstructions := instrClassifier.GetInstructions(programId)
 
 		// Process trades
 		if shouldParseTrades {
-			if factory, ok := dp.tradeParserFactories[programId]; ok {
+			if factory, ok := reg.trade[programId]; ok {
 				dexInfoForProgram := types.DexInfo{
 					ProgramId: programId,
 					AMM:       constants.GetProgramName(programId),
 					Route:     dexInfo.Route,
 				}
-				parser := factory(adapt, dexInfoForProgram, transferActions, classifiedInstructions)
-				result.Trades = append(result.Trades, parser.ProcessTrades()...)
+				tr.handled(programId, types.TraceHandlerTrade)
+				runParser(result, config, programId, classifiedInstructions, func() {
+					parser := factory(adapt, dexInfoForProgram, transferActions, classifiedInstructions)
+					trades := parser.ProcessTrades()
+					tr.emitted(programId, tradeIdxs(trades)...)
+					result.Trades = append(result.Trades, trades...)
+				})
 			} else if config.TryUnknownDEX {
 				// Try to parse unknown DEX programs
+				tr.handled(programId, types.TraceHandlerUnknownDEX)
 				for key, transfers := range transferActions {
 					if len(transfers) >= 2 && keyStartsWith(key, programId) {
 						hasSupported := false
@@ -619,6 +716,7 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 							trade := txUtils.ProcessSwapData(transfers, dexInfoForProgram, true)
 							if trade != nil {
 								result.Trades = append(result.Trades, *txUtils.AttachTokenTransferInfo(trade, transferActions))
+								tr.emitted(programId, trade.Idx)
 							}
 						}
 					}
@@ -628,18 +726,31 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 
 		// Process liquidity
 		if shouldParseLiquidity {
-			if factory, ok := dp.liquidityParserFactories[programId]; ok {
-				parser := factory(adapt, transferActions, classifiedInstructions)
-				liquidities := parser.ProcessLiquidity()
-				result.Liquidities = append(result.Liquidities, txUtils.AttachUserBalanceToLPs(liquidities)...)
+			if factory, ok := reg.liquidity[programId]; ok {
+				tr.handled(programId, types.TraceHandlerLiquidity)
+				runParser(result, config, programId, classifiedInstructions, func() {
+					parser := factory(adapt, transferActions, classifiedInstructions)
+					liquidities := parser.ProcessLiquidity()
+					for _, lp := range liquidities {
+						tr.emitted(programId, lp.Idx)
+					}
+					result.Liquidities = append(result.Liquidities, txUtils.AttachUserBalanceToLPs(liquidities)...)
+				})
 			}
 		}
 
 		// Process meme events
 		if shouldParseMemeEvents {
-			if factory, ok := dp.memeEventParserFactories[programId]; ok {
-				parser := factory(adapt, transferActions)
-				result.MemeEvents = append(result.MemeEvents, parser.ProcessEvents()...)
+			if factory, ok := reg.meme[programId]; ok {
+				tr.handled(programId, types.TraceHandlerMemeEvent)
+				runParser(result, config, programId, classifiedInstructions, func() {
+					parser := factory(adapt, transferActions)
+					events := parser.ProcessEvents()
+					for _, event := range events {
+						tr.emitted(programId, event.Idx)
+					}
+					result.MemeEvents = append(result.MemeEvents, events...)
+				})
 			}
 		}
 	}
@@ -648,21 +759,47 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 	if shouldParseAltEvents {
 		altInstructions := instrClassifier.GetInstructions(constants.ALT_PROGRAM_ID)
 		if len(altInstructions) > 0 {
-			altParser := alt.NewAltEventParser(adapt, altInstructions)
-			result.AltEvents = append(result.AltEvents, altParser.ProcessEvents()...)
+			tr.handled(constants.ALT_PROGRAM_ID, types.TraceHandlerAlt)
+			runParser(result, config, constants.ALT_PROGRAM_ID, altInstructions, func() {
+				altParser := alt.NewAltEventParser(adapt, altInstructions)
+				events := altParser.ProcessEvents()
+				for _, event := range events {
+					tr.emitted(constants.ALT_PROGRAM_ID, event.Idx)
+				}
+				result.AltEvents = append(result.AltEvents, events...)
+			})
 		}
 	}
 
 	// Deduplicate trades
 	if len(result.Trades) > 0 {
 		result.Trades = deduplicateTrades(result.Trades)
+	}
+
+	// Enrich trades and liquidity events with fetched pool info
+	txUtils.AttachPoolInfo(result.Trades, result.Liquidities)
+
+	// Aggregate trades
+	if len(result.Trades) > 0 {
 		shouldAggregate := config.ShouldAggregateTrades() || effectiveParseType.AggregateTrade
 		if shouldAggregate {
 			aggregateTrade := utils.GetFinalSwap(result.Trades, &dexInfo)
 			if aggregateTrade != nil {
 				result.AggregateTrade = txUtils.AttachTradeFee(aggregateTrade)
+				utils.AttachTips(result.AggregateTrade, result.Tips)
 			}
 		}
+		result.Arbitrage = utils.GetArbitrage(result, result.Trades)
+	}
+
+	// Failed transactions have no inner instructions to rebuild trades from; classify failed
+	// arbitrage from the outer Jupiter routes instead
+	if shouldParseTrades && result.Arbitrage == nil && result.TxStatus == types.TransactionStatusFailed {
+		if instructions := instrClassifier.GetInstructions(constants.DEX_PROGRAMS.JUPITER.ID); len(instructions) > 0 {
+			runParser(result, config, constants.DEX_PROGRAMS.JUPITER.ID, instructions, func() {
+				result.Arbitrage = utils.GetFailedRouteArbitrage(result, jupiter.RouteTrades(adapt, instructions))
+			})
+		}
 	}
 
 	// Process transfers if no trades and no liquidity
@@ -670,9 +807,16 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 		if shouldParseTransfers {
 			if dexInfo.ProgramId != "" {
 				classifiedInstructions := instrClassifier.GetInstructions(dexInfo.ProgramId)
-				if factory, ok := dp.transferParserFactories[dexInfo.ProgramId]; ok {
-					parser := factory(adapt, dexInfo, transferActions, classifiedInstructions)
-					result.Transfers = append(result.Transfers, parser.ProcessTransfers()...)
+				if factory, ok := reg.transfer[dexInfo.ProgramId]; ok {
+					tr.handled(dexInfo.ProgramId, types.TraceHandlerTransfer)
+					runParser(result, config, dexInfo.ProgramId, classifiedInstructions, func() {
+						parser := factory(adapt, dexInfo, transferActions, classifiedInstructions)
+						transfers := parser.ProcessTransfers()
+						for _, transfer := range transfers {
+							tr.emitted(dexInfo.ProgramId, transfer.Idx)
+						}
+						result.Transfers = append(result.Transfers, transfers...)
+					})
 				}
 			}
 			if len(result.Transfers) == 0 {
@@ -689,6 +833,95 @@ func (dp *DexParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *
 
 // Helper functions
 
+var (
+	// errBatchStopped marks batch results skipped because the callback requested early termination
+	errBatchStopped = errors.New("batch stopped by callback")
+
+	errNilTransaction       = errors.New("nil transaction")
+	errNoMatchingProgramIds = errors.New("No matching program ids")
+	errNoMatchingAccounts   = errors.New("No matching accounts include")
+	errAccountExcluded      = errors.New("Account excluded")
+)
+
+// failResult marks the whole transaction as failed with a structured error and legacy message
+func failResult(result *types.ParseResult, err *types.ParseError, msg string) {
+	result.State = false
+	result.Msg = msg
+	result.Errors = append(result.Errors, err)
+}
+
+// runParser runs one program parser, recording a panic as a DECODER_PANIC error for that
+// program so the results of other programs are kept
+func runParser(result *types.ParseResult, config *types.ParseConfig, programId string, instructions []types.ClassifiedInstruction, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if config.ThrowError {
+				panic(r)
+			}
+			idx := ""
+			if len(instructions) > 0 {
+				idx = utils.FormatIdx(instructions[0].OuterIndex, instructions[0].InnerIndex)
+			}
+			result.Errors = append(result.Errors, types.NewPanicError(types.ParseErrorDecoderPanic, programId, idx, r))
+		}
+	}()
+	fn()
+}
+
+// traceProgramIds returns the programs whose instructions are traced
+func traceProgramIds(instrClassifier *classifier.InstructionClassifier, programIds []string) []string {
+	if instrClassifier.HasProgram(constants.ALT_PROGRAM_ID) && !containsString(programIds, constants.ALT_PROGRAM_ID) {
+		return append(append([]string(nil), programIds...), constants.ALT_PROGRAM_ID)
+	}
+	return programIds
+}
+
+// tradeIdxs returns the instruction indexes of trades
+func tradeIdxs(trades []types.TradeInfo) []string {
+	idxs := make([]string, len(trades))
+	for i, trade := range trades {
+		idxs[i] = trade.Idx
+	}
+	return idxs
+}
+
+// isSupportedVersion reports whether the transaction version is legacy or v0
+func isSupportedVersion(version interface{}) bool {
+	switch v := version.(type) {
+	case nil:
+		return true
+	case string:
+		return v == "" || v == "legacy" || v == "0"
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float64:
+		return v == 0
+	case uint8:
+		return v == 0
+	default:
+		return false
+	}
+}
+
+// newSkippedResult creates a result for a transaction that was never parsed
+func newSkippedResult(tx *adapter.SolanaTransaction, err error) *types.ParseResult {
+	result := types.NewParseResult()
+	result.State = false
+	result.Skipped = true
+	result.Msg = fmt.Sprintf("skipped: %v", err)
+	if tx != nil {
+		result.Slot = tx.Slot
+		if len(tx.Transaction.Signatures) > 0 {
+			result.Signature = tx.Transaction.Signatures[0]
+		}
+	}
+	return result
+}
+
 func containsString(slice []string, val string) bool {
 	for _, item := range slice {
 		if item == val {
@@ -0,0 +1,137 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// altKey identifies one address slot of an address lookup table
+type altKey struct {
+	table string
+	index int
+}
+
+// CachedALTsFetcher caches resolved lookup table addresses per (table, index) and merges
+// lookups for the same table from concurrent transactions into one Fetch call
+type CachedALTsFetcher struct {
+	filter types.FetchFilterType
+	loader *loader[altKey, string]
+}
+
+// NewCachedALTsFetcher wraps inner with caching and batching
+func NewCachedALTsFetcher(inner *types.ALTsFetcher, config Config) *CachedALTsFetcher {
+	return &CachedALTsFetcher{
+		filter: inner.Filter,
+		loader: newLoader(config, func(ctx context.Context, keys []altKey) (map[altKey]string, error) {
+			return fetchALTAddresses(ctx, inner, keys)
+		}),
+	}
+}
+
+// fetchALTAddresses requests every key of a batch as writable indexes of one lookup per table
+func fetchALTAddresses(ctx context.Context, inner *types.ALTsFetcher, keys []altKey) (map[altKey]string, error) {
+	var lookups []types.AddressTableLookup
+	position := make(map[string]int)
+	for _, key := range keys {
+		i, ok := position[key.table]
+		if !ok {
+			i = len(lookups)
+			position[key.table] = i
+			lookups = append(lookups, types.AddressTableLookup{AccountKey: key.table})
+		}
+		lookups[i].WritableIndexes = append(lookups[i].WritableIndexes, key.index)
+	}
+
+	loaded, err := inner.FetchWithContext(ctx, lookups)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[altKey]string, len(keys))
+	for _, lookup := range lookups {
+		addresses := loaded[lookup.AccountKey]
+		if addresses == nil {
+			continue
+		}
+		for i, index := range lookup.WritableIndexes {
+			if i < len(addresses.Writable) && addresses.Writable[i] != "" {
+				result[altKey{table: lookup.AccountKey, index: index}] = addresses.Writable[i]
+			}
+		}
+	}
+	return result, nil
+}
+
+// Fetch resolves lookups with the same contract as types.ALTsFetcher.Fetch; a table is
+// omitted when none of its addresses could be resolved, unresolved slots are left empty
+func (f *CachedALTsFetcher) Fetch(alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+	return f.FetchContext(context.Background(), alts)
+}
+
+// FetchContext is Fetch that returns once ctx is done; the shared fetch is cancelled only
+// when no other caller still waits on it
+func (f *CachedALTsFetcher) FetchContext(ctx context.Context, alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+	var keys []altKey
+	for _, lookup := range alts {
+		for _, index := range lookup.WritableIndexes {
+			keys = append(keys, altKey{table: lookup.AccountKey, index: index})
+		}
+		for _, index := range lookup.ReadonlyIndexes {
+			keys = append(keys, altKey{table: lookup.AccountKey, index: index})
+		}
+	}
+
+	addresses, err := f.loader.load(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.LoadedAddresses, len(alts))
+	for _, lookup := range alts {
+		loaded := &types.LoadedAddresses{
+			Writable: selectAddresses(addresses, lookup.AccountKey, lookup.WritableIndexes),
+			Readonly: selectAddresses(addresses, lookup.AccountKey, lookup.ReadonlyIndexes),
+		}
+		if hasAddress(loaded.Writable) || hasAddress(loaded.Readonly) {
+			result[lookup.AccountKey] = loaded
+		}
+	}
+	return result, nil
+}
+
+// selectAddresses returns the resolved address for each index of table ("" if unresolved)
+func selectAddresses(addresses map[altKey]string, table string, indexes []int) []string {
+	selected := make([]string, len(indexes))
+	for i, index := range indexes {
+		selected[i] = addresses[altKey{table: table, index: index}]
+	}
+	return selected
+}
+
+// hasAddress reports whether any address is non-empty
+func hasAddress(addresses []string) bool {
+	for _, address := range addresses {
+		if address != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetcher returns a types.ALTsFetcher backed by this cache, keeping the inner filter
+func (f *CachedALTsFetcher) Fetcher() *types.ALTsFetcher {
+	fetcher := types.NewALTsFetcher(f.filter, f.Fetch)
+	fetcher.FetchContext = f.FetchContext
+	return fetcher
+}
+
+// Stats returns a snapshot of the cache counters
+func (f *CachedALTsFetcher) Stats() Stats {
+	return f.loader.stats()
+}
+
+// Purge drops all cached addresses
+func (f *CachedALTsFetcher) Purge() {
+	f.loader.purge()
+}
@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"container/list"
+	"time"
+)
+
+// lruEntry is a cached value with its expiry time (zero if it never expires)
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// lruCache is a size-bounded LRU cache with optional TTL; callers must synchronize access
+type lruCache[K comparable, V any] struct {
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+// newLRUCache creates a cache holding at most size entries; size <= 0 disables caching
+func newLRUCache[K comparable, V any](size int, ttl time.Duration) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// get returns the cached value for key if present and not expired
+func (c *lruCache[K, V]) get(key K, now time.Time) (V, bool) {
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[K, V])
+	if !entry.expires.IsZero() && now.After(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// add stores value for key with the cache TTL and returns the number of evicted entries
+func (c *lruCache[K, V]) add(key K, value V, now time.Time) int {
+	return c.addTTL(key, value, now, c.ttl)
+}
+
+// addTTL is add with an entry-specific TTL (0 means no expiry)
+func (c *lruCache[K, V]) addTTL(key K, value V, now time.Time, ttl time.Duration) int {
+	if c.size <= 0 {
+		return 0
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return 0
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry[K, V]{key: key, value: value, expires: expires})
+
+	evicted := 0
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+		evicted++
+	}
+	return evicted
+}
+
+// len returns the number of cached entries, including expired ones not yet evicted
+func (c *lruCache[K, V]) len() int {
+	return c.ll.Len()
+}
+
+// purge removes all entries
+func (c *lruCache[K, V]) purge() {
+	c.ll.Init()
+	c.items = make(map[K]*list.Element)
+}
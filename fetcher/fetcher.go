@@ -0,0 +1,66 @@
+// Package fetcher provides caching, de-duplicating and batching decorators for the
+// ALT, token account and pool info fetchers in types, so that concurrent parses
+// (e.g. ParseBatch with many workers) share one RPC budget.
+package fetcher
+
+import (
+	"time"
+)
+
+// Config controls cache size, expiry and batching of a cached fetcher
+type Config struct {
+	// Size is the maximum number of cached keys (0 disables caching)
+	Size int
+
+	// TTL is how long a cached value stays valid (0 means no expiry)
+	TTL time.Duration
+
+	// BatchWindow is how long to collect keys from concurrent callers before one Fetch call
+	// (0 fetches immediately, still de-duplicating concurrent requests)
+	BatchWindow time.Duration
+
+	// MaxBatchSize flushes a batch early once it holds this many keys (0 means unlimited)
+	MaxBatchSize int
+
+	// NegativeTTL is how long keys the underlying fetcher returned nothing for are cached, so
+	// repeated lookups of a missing account don't each cost a Fetch call (0 disables it).
+	// Negative entries count towards Size.
+	NegativeTTL time.Duration
+
+	// FetchTimeout bounds each underlying Fetch call (0 means no timeout). A call is also
+	// cancelled once every caller waiting on it has given up.
+	FetchTimeout time.Duration
+}
+
+// DefaultConfig returns the default cached fetcher configuration
+func DefaultConfig() Config {
+	return Config{
+		Size:         10000,
+		TTL:          5 * time.Minute,
+		BatchWindow:  2 * time.Millisecond,
+		MaxBatchSize: 100,
+		NegativeTTL:  5 * time.Second,
+		FetchTimeout: 30 * time.Second,
+	}
+}
+
+// Stats contains cached fetcher counters
+type Stats struct {
+	Hits        uint64 // Keys served from cache
+	Misses      uint64 // Keys not found in cache
+	Shared      uint64 // Misses that joined an already in-flight request
+	Fetches     uint64 // Calls made to the underlying fetcher
+	FetchedKeys uint64 // Keys requested from the underlying fetcher
+	Errors      uint64 // Underlying fetcher calls that failed
+	Evictions   uint64 // Entries evicted by the size bound
+	Size        int    // Current number of cached entries
+}
+
+// HitRate returns the fraction of keys served from cache
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
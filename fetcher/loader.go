@@ -0,0 +1,293 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// call tracks a single in-flight key shared by every waiter requesting it
+type call[K comparable, V any] struct {
+	done  chan struct{}
+	batch *batch[K, V]
+	value V
+	found bool
+	err   error
+}
+
+// batch collects keys queued during one batching window. Its context is cancelled once every
+// caller waiting on it has given up.
+type batch[K comparable, V any] struct {
+	keys     []K
+	calls    []*call[K, V]
+	timer    *time.Timer
+	ctx      context.Context
+	cancel   context.CancelFunc
+	waiters  int
+	finished bool
+}
+
+// cached is a cache entry; found is false for a key the fetch function returned nothing for
+type cached[V any] struct {
+	value V
+	found bool
+}
+
+// loader combines an LRU cache, singleflight de-duplication and micro-batching
+// in front of a bulk fetch function
+type loader[K comparable, V any] struct {
+	fetch  func(ctx context.Context, keys []K) (map[K]V, error)
+	config Config
+
+	mu       sync.Mutex
+	cache    *lruCache[K, cached[V]]
+	inflight map[K]*call[K, V]
+	pending  *batch[K, V]
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	shared      atomic.Uint64
+	fetches     atomic.Uint64
+	fetchedKeys atomic.Uint64
+	errors      atomic.Uint64
+	evictions   atomic.Uint64
+}
+
+// newLoader creates a loader for the given bulk fetch function
+func newLoader[K comparable, V any](config Config, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:    fetch,
+		config:   config,
+		cache:    newLRUCache[K, cached[V]](config.Size, config.TTL),
+		inflight: make(map[K]*call[K, V]),
+	}
+}
+
+// load resolves keys from the cache, joins in-flight requests for the rest and queues
+// the remaining keys into the current batch. Keys that couldn't be fetched are absent
+// from the result; the first fetch error seen is returned alongside any resolved values.
+// Batches run on their own goroutines, so a done ctx always returns promptly; the shared
+// fetch is cancelled only when no other caller still waits on it.
+func (l *loader[K, V]) load(ctx context.Context, keys []K) (map[K]V, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[K]V, len(keys))
+	waits := make(map[K]*call[K, V])
+	batches := make(map[*batch[K, V]]struct{})
+	var ready []*batch[K, V]
+
+	now := time.Now()
+	l.mu.Lock()
+	for _, key := range keys {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		if _, ok := waits[key]; ok {
+			continue
+		}
+		if entry, ok := l.cache.get(key, now); ok {
+			l.hits.Add(1)
+			if entry.found {
+				result[key] = entry.value
+			}
+			continue
+		}
+		l.misses.Add(1)
+		c, ok := l.inflight[key]
+		if ok {
+			l.shared.Add(1)
+		} else {
+			c = &call[K, V]{done: make(chan struct{})}
+			l.inflight[key] = c
+			if b := l.enqueue(key, c); b != nil {
+				ready = append(ready, b)
+			}
+		}
+		waits[key] = c
+		if _, ok := batches[c.batch]; !ok {
+			batches[c.batch] = struct{}{}
+			c.batch.waiters++
+		}
+	}
+	if l.config.BatchWindow <= 0 && l.pending != nil {
+		ready = append(ready, l.detach())
+	}
+	l.mu.Unlock()
+
+	for _, b := range ready {
+		go l.run(b)
+	}
+
+	var firstErr error
+	for key, c := range waits {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			l.abandon(batches)
+			return result, ctx.Err()
+		}
+		if c.err != nil {
+			if firstErr == nil {
+				firstErr = c.err
+			}
+			continue
+		}
+		if c.found {
+			result[key] = c.value
+		}
+	}
+	return result, firstErr
+}
+
+// enqueue adds key to the pending batch, returning the batch if it reached MaxBatchSize
+func (l *loader[K, V]) enqueue(key K, c *call[K, V]) *batch[K, V] {
+	if l.pending == nil {
+		b := &batch[K, V]{}
+		b.ctx, b.cancel = context.WithCancel(context.Background())
+		if l.config.BatchWindow > 0 {
+			b.timer = time.AfterFunc(l.config.BatchWindow, func() { l.flush(b) })
+		}
+		l.pending = b
+	}
+	c.batch = l.pending
+	l.pending.keys = append(l.pending.keys, key)
+	l.pending.calls = append(l.pending.calls, c)
+	if l.config.MaxBatchSize > 0 && len(l.pending.keys) >= l.config.MaxBatchSize {
+		return l.detach()
+	}
+	return nil
+}
+
+// detach removes the pending batch so no further keys join it; callers must hold mu
+func (l *loader[K, V]) detach() *batch[K, V] {
+	b := l.pending
+	l.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	return b
+}
+
+// abandon releases a caller's interest in batches. A batch nobody waits on any more is
+// cancelled and its keys leave inflight, so later callers start a fresh fetch.
+func (l *loader[K, V]) abandon(batches map[*batch[K, V]]struct{}) {
+	var orphaned []*batch[K, V]
+	l.mu.Lock()
+	for b := range batches {
+		b.waiters--
+		if b.waiters > 0 || b.finished {
+			continue
+		}
+		b.cancel()
+		for i, key := range b.keys {
+			if l.inflight[key] == b.calls[i] {
+				delete(l.inflight, key)
+			}
+		}
+		if l.pending == b {
+			orphaned = append(orphaned, l.detach())
+		}
+	}
+	l.mu.Unlock()
+
+	for _, b := range orphaned {
+		go l.run(b)
+	}
+}
+
+// flush runs b when its batching window expires, unless it was already detached
+func (l *loader[K, V]) flush(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	l.run(b)
+}
+
+// run performs one bulk fetch for b and releases every waiter. A batch cancelled before it
+// started is released without fetching.
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	defer b.cancel()
+
+	var values map[K]V
+	err := b.ctx.Err()
+	if err == nil {
+		ctx := b.ctx
+		if l.config.FetchTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, l.config.FetchTimeout)
+			defer cancel()
+		}
+		values, err = l.safeFetch(ctx, b.keys)
+		l.fetches.Add(1)
+		l.fetchedKeys.Add(uint64(len(b.keys)))
+		if err != nil {
+			l.errors.Add(1)
+		}
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	b.finished = true
+	for i, key := range b.keys {
+		c := b.calls[i]
+		c.err = err
+		if err == nil {
+			c.value, c.found = values[key]
+			if c.found {
+				l.evictions.Add(uint64(l.cache.add(key, cached[V]{value: c.value, found: true}, now)))
+			} else if l.config.NegativeTTL > 0 {
+				l.evictions.Add(uint64(l.cache.addTTL(key, cached[V]{}, now, l.config.NegativeTTL)))
+			}
+		}
+		if l.inflight[key] == c {
+			delete(l.inflight, key)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, c := range b.calls {
+		close(c.done)
+	}
+}
+
+// safeFetch calls the fetch function, converting a panic into an error so waiters are released
+func (l *loader[K, V]) safeFetch(ctx context.Context, keys []K) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("fetch panic: %v", r)
+		}
+	}()
+	return l.fetch(ctx, keys)
+}
+
+// stats returns a snapshot of the loader counters
+func (l *loader[K, V]) stats() Stats {
+	l.mu.Lock()
+	size := l.cache.len()
+	l.mu.Unlock()
+	return Stats{
+		Hits:        l.hits.Load(),
+		Misses:      l.misses.Load(),
+		Shared:      l.shared.Load(),
+		Fetches:     l.fetches.Load(),
+		FetchedKeys: l.fetchedKeys.Load(),
+		Errors:      l.errors.Load(),
+		Evictions:   l.evictions.Load(),
+		Size:        size,
+	}
+}
+
+// purge drops all cached entries; in-flight requests are unaffected
+func (l *loader[K, V]) purge() {
+	l.mu.Lock()
+	l.cache.purge()
+	l.mu.Unlock()
+}
@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// CachedPoolInfoFetcher caches pool info by pool key and merges requests from
+// concurrent transactions into one Fetch call
+type CachedPoolInfoFetcher struct {
+	filter types.FetchFilterType
+	loader *loader[string, *types.PoolInfo]
+}
+
+// NewCachedPoolInfoFetcher wraps inner with caching and batching
+func NewCachedPoolInfoFetcher(inner *types.PoolInfoFetcher, config Config) *CachedPoolInfoFetcher {
+	return &CachedPoolInfoFetcher{
+		filter: inner.Filter,
+		loader: newLoader(config, func(ctx context.Context, keys []string) (map[string]*types.PoolInfo, error) {
+			infos, err := inner.FetchWithContext(ctx, keys)
+			if err != nil {
+				return nil, err
+			}
+			return alignedToMap(keys, infos), nil
+		}),
+	}
+}
+
+// Fetch returns pool info aligned with poolKeys (nil for pools that couldn't be fetched)
+func (f *CachedPoolInfoFetcher) Fetch(poolKeys []string) ([]*types.PoolInfo, error) {
+	return f.FetchContext(context.Background(), poolKeys)
+}
+
+// FetchContext is Fetch that returns once ctx is done; the shared fetch is cancelled only
+// when no other caller still waits on it
+func (f *CachedPoolInfoFetcher) FetchContext(ctx context.Context, poolKeys []string) ([]*types.PoolInfo, error) {
+	infos, err := f.loader.load(ctx, poolKeys)
+	if err != nil {
+		return nil, err
+	}
+	return mapToAligned(poolKeys, infos), nil
+}
+
+// Fetcher returns a types.PoolInfoFetcher backed by this cache, keeping the inner filter
+func (f *CachedPoolInfoFetcher) Fetcher() *types.PoolInfoFetcher {
+	fetcher := types.NewPoolInfoFetcher(f.filter, f.Fetch)
+	fetcher.FetchContext = f.FetchContext
+	return fetcher
+}
+
+// Stats returns a snapshot of the cache counters
+func (f *CachedPoolInfoFetcher) Stats() Stats {
+	return f.loader.stats()
+}
+
+// Purge drops all cached pools
+func (f *CachedPoolInfoFetcher) Purge() {
+	f.loader.purge()
+}
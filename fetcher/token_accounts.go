@@ -0,0 +1,80 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// CachedTokenAccountsFetcher caches token account info by account key and merges
+// requests from concurrent transactions into one Fetch call
+type CachedTokenAccountsFetcher struct {
+	filter types.FetchFilterType
+	loader *loader[string, *types.TokenAccountInfo]
+}
+
+// NewCachedTokenAccountsFetcher wraps inner with caching and batching
+func NewCachedTokenAccountsFetcher(inner *types.TokenAccountsFetcher, config Config) *CachedTokenAccountsFetcher {
+	return &CachedTokenAccountsFetcher{
+		filter: inner.Filter,
+		loader: newLoader(config, func(ctx context.Context, keys []string) (map[string]*types.TokenAccountInfo, error) {
+			infos, err := inner.FetchWithContext(ctx, keys)
+			if err != nil {
+				return nil, err
+			}
+			return alignedToMap(keys, infos), nil
+		}),
+	}
+}
+
+// Fetch returns token account info aligned with accountKeys (nil for accounts that couldn't be fetched)
+func (f *CachedTokenAccountsFetcher) Fetch(accountKeys []string) ([]*types.TokenAccountInfo, error) {
+	return f.FetchContext(context.Background(), accountKeys)
+}
+
+// FetchContext is Fetch that returns once ctx is done; the shared fetch is cancelled only
+// when no other caller still waits on it
+func (f *CachedTokenAccountsFetcher) FetchContext(ctx context.Context, accountKeys []string) ([]*types.TokenAccountInfo, error) {
+	infos, err := f.loader.load(ctx, accountKeys)
+	if err != nil {
+		return nil, err
+	}
+	return mapToAligned(accountKeys, infos), nil
+}
+
+// Fetcher returns a types.TokenAccountsFetcher backed by this cache, keeping the inner filter
+func (f *CachedTokenAccountsFetcher) Fetcher() *types.TokenAccountsFetcher {
+	fetcher := types.NewTokenAccountsFetcher(f.filter, f.Fetch)
+	fetcher.FetchContext = f.FetchContext
+	return fetcher
+}
+
+// Stats returns a snapshot of the cache counters
+func (f *CachedTokenAccountsFetcher) Stats() Stats {
+	return f.loader.stats()
+}
+
+// Purge drops all cached token accounts
+func (f *CachedTokenAccountsFetcher) Purge() {
+	f.loader.purge()
+}
+
+// alignedToMap converts a result slice aligned with keys into a map, skipping nil values
+func alignedToMap[V any](keys []string, values []*V) map[string]*V {
+	result := make(map[string]*V, len(keys))
+	for i, key := range keys {
+		if i < len(values) && values[i] != nil {
+			result[key] = values[i]
+		}
+	}
+	return result
+}
+
+// mapToAligned converts a map back into a slice aligned with keys
+func mapToAligned[V any](keys []string, values map[string]*V) []*V {
+	result := make([]*V, len(keys))
+	for i, key := range keys {
+		result[i] = values[key]
+	}
+	return result
+}
@@ -0,0 +1,530 @@
+package dexparser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("protobuf: truncated message")
+
+// protoReader reads protobuf wire format fields; byte fields alias the input buffer
+type protoReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *protoReader) done() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *protoReader) varint() (uint64, error) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if r.pos >= len(r.buf) {
+			return 0, errProtoTruncated
+		}
+		b := r.buf[r.pos]
+		r.pos++
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("protobuf: varint overflow")
+}
+
+// field reads the next field key
+func (r *protoReader) field() (num int, wireType int, err error) {
+	key, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	num, wireType = int(key>>3), int(key&7)
+	if num == 0 {
+		return 0, 0, errors.New("protobuf: invalid field number 0")
+	}
+	return num, wireType, nil
+}
+
+func (r *protoReader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.buf)-r.pos) {
+		return nil, errProtoTruncated
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+func (r *protoReader) fixed64() (uint64, error) {
+	if len(r.buf)-r.pos < 8 {
+		return 0, errProtoTruncated
+	}
+	v := binary.LittleEndian.Uint64(r.buf[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+// skip discards a field value of the given wire type
+func (r *protoReader) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case wireVarint:
+		_, err = r.varint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	case wireFixed32:
+		if len(r.buf)-r.pos < 4 {
+			return errProtoTruncated
+		}
+		r.pos += 4
+	default:
+		return fmt.Errorf("protobuf: unsupported wire type %d", wireType)
+	}
+	return err
+}
+
+// expect checks the wire type of a known field
+func expect(num, wireType, want int) error {
+	if wireType != want {
+		return fmt.Errorf("protobuf: field %d has wire type %d, want %d", num, wireType, want)
+	}
+	return nil
+}
+
+// decodeMessage calls fn for every field of a message; fn returns false for fields it does not handle
+func decodeMessage(data []byte, fn func(r *protoReader, num, wireType int) (bool, error)) error {
+	r := &protoReader{buf: data}
+	for !r.done() {
+		num, wireType, err := r.field()
+		if err != nil {
+			return err
+		}
+		handled, err := fn(r, num, wireType)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", num, err)
+		}
+		if !handled {
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readUint64s reads a repeated uint64 field in packed or unpacked encoding
+func (r *protoReader) readUint64s(wireType int, values []uint64) ([]uint64, error) {
+	if wireType == wireVarint {
+		v, err := r.varint()
+		return append(values, v), err
+	}
+	packed, err := r.bytes()
+	if err != nil {
+		return values, err
+	}
+	pr := &protoReader{buf: packed}
+	for !pr.done() {
+		v, err := pr.varint()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// DecodeYellowstoneTransaction decodes a serialized SubscribeUpdateTransaction and returns the transaction and its slot.
+// Byte fields of the result alias data, which must not be modified while the transaction is in use.
+func DecodeYellowstoneTransaction(data []byte) (*YellowstoneTransaction, uint64, error) {
+	var info []byte
+	var slot uint64
+	err := decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				info, err = r.bytes()
+			}
+		case 2:
+			if err = expect(num, wireType, wireVarint); err == nil {
+				slot, err = r.varint()
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("SubscribeUpdateTransaction: %w", err)
+	}
+	if info == nil {
+		return nil, 0, errors.New("SubscribeUpdateTransaction: missing transaction")
+	}
+	tx, err := DecodeYellowstoneTransactionInfo(info)
+	return tx, slot, err
+}
+
+// DecodeYellowstoneTransactionInfo decodes a serialized SubscribeUpdateTransactionInfo.
+// Byte fields of the result alias data, which must not be modified while the transaction is in use.
+func DecodeYellowstoneTransactionInfo(data []byte) (*YellowstoneTransaction, error) {
+	tx := &YellowstoneTransaction{}
+	err := decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		var err error
+		var b []byte
+		switch num {
+		case 1: // signature
+			if err = expect(num, wireType, wireBytes); err == nil {
+				tx.Signature, err = r.bytes()
+			}
+		case 2: // is_vote
+			var v uint64
+			if err = expect(num, wireType, wireVarint); err == nil {
+				v, err = r.varint()
+				tx.IsVote = v != 0
+			}
+		case 3: // transaction
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					err = decodeTransactionData(b, &tx.Transaction)
+				}
+			}
+		case 4: // meta
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					err = decodeTransactionMeta(b, &tx.Meta)
+				}
+			}
+		case 5: // index
+			if err = expect(num, wireType, wireVarint); err == nil {
+				tx.Index, err = r.varint()
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SubscribeUpdateTransactionInfo: %w", err)
+	}
+	return tx, nil
+}
+
+// decodeTransactionData decodes solana.storage.ConfirmedBlock.Transaction
+func decodeTransactionData(data []byte, tx *YellowstoneTransactionData) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		if wireType != wireBytes || (num != 1 && num != 2) {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		if num == 1 {
+			tx.Signatures = append(tx.Signatures, b)
+			return true, nil
+		}
+		return true, decodeMessageData(b, &tx.Message)
+	})
+}
+
+// decodeMessageData decodes solana.storage.ConfirmedBlock.Message
+func decodeMessageData(data []byte, msg *YellowstoneMessageData) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		if num == 5 { // versioned
+			if err := expect(num, wireType, wireVarint); err != nil {
+				return true, err
+			}
+			v, err := r.varint()
+			msg.Versioned = v != 0
+			return true, err
+		}
+		if num < 1 || num > 6 {
+			return false, nil
+		}
+		if err := expect(num, wireType, wireBytes); err != nil {
+			return true, err
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch num {
+		case 1:
+			err = decodeHeader(b, &msg.Header)
+		case 2:
+			msg.AccountKeys = append(msg.AccountKeys, b)
+		case 3:
+			msg.RecentBlockhash = b
+		case 4:
+			var ix YellowstoneInstruction
+			err = decodeInstruction(b, &ix)
+			msg.Instructions = append(msg.Instructions, ix)
+		case 6:
+			var lookup YellowstoneAddressTableLookup
+			err = decodeLookup(b, &lookup)
+			msg.AddressTableLookups = append(msg.AddressTableLookups, lookup)
+		}
+		return true, err
+	})
+}
+
+// decodeHeader decodes solana.storage.ConfirmedBlock.MessageHeader
+func decodeHeader(data []byte, header *YellowstoneHeader) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		if wireType != wireVarint || num > 3 {
+			return false, nil
+		}
+		v, err := r.varint()
+		switch num {
+		case 1:
+			header.NumRequiredSignatures = int(v)
+		case 2:
+			header.NumReadonlySignedAccounts = int(v)
+		case 3:
+			header.NumReadonlyUnsignedAccounts = int(v)
+		}
+		return true, err
+	})
+}
+
+// decodeInstruction decodes CompiledInstruction and InnerInstruction, which share fields 1-3;
+// only InnerInstruction carries stack_height (4)
+func decodeInstruction(data []byte, ix *YellowstoneInstruction) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		var err error
+		switch {
+		case num == 1 && wireType == wireVarint:
+			var v uint64
+			v, err = r.varint()
+			ix.ProgramIdIndex = int(v)
+		case num == 2 && wireType == wireBytes:
+			ix.Accounts, err = r.bytes()
+		case num == 3 && wireType == wireBytes:
+			ix.Data, err = r.bytes()
+		case num == 4 && wireType == wireVarint:
+			var v uint64
+			v, err = r.varint()
+			height := uint32(v)
+			ix.StackHeight = &height
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// decodeLookup decodes solana.storage.ConfirmedBlock.MessageAddressTableLookup
+func decodeLookup(data []byte, lookup *YellowstoneAddressTableLookup) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		if wireType != wireBytes || num > 3 {
+			return false, nil
+		}
+		b, err := r.bytes()
+		switch num {
+		case 1:
+			lookup.AccountKey = b
+		case 2:
+			lookup.WritableIndexes = b
+		case 3:
+			lookup.ReadonlyIndexes = b
+		}
+		return true, err
+	})
+}
+
+// decodeTransactionMeta decodes solana.storage.ConfirmedBlock.TransactionStatusMeta
+func decodeTransactionMeta(data []byte, meta *YellowstoneTransactionMeta) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		var err error
+		var b []byte
+		switch num {
+		case 1: // err: TransactionError{bytes err = 1}, bincode encoded
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					err = decodeMessage(b, func(r *protoReader, num, wireType int) (bool, error) {
+						if num != 1 || wireType != wireBytes {
+							return false, nil
+						}
+						raw, err := r.bytes()
+						meta.Err = raw
+						return true, err
+					})
+				}
+			}
+		case 2:
+			if err = expect(num, wireType, wireVarint); err == nil {
+				meta.Fee, err = r.varint()
+			}
+		case 3:
+			meta.PreBalances, err = r.readUint64s(wireType, meta.PreBalances)
+		case 4:
+			meta.PostBalances, err = r.readUint64s(wireType, meta.PostBalances)
+		case 5:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					var set YellowstoneInnerInstructionSet
+					err = decodeInnerInstructions(b, &set)
+					meta.InnerInstructions = append(meta.InnerInstructions, set)
+				}
+			}
+		case 6:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					meta.LogMessages = append(meta.LogMessages, string(b))
+				}
+			}
+		case 7, 8:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					var balance YellowstoneTokenBalance
+					err = decodeTokenBalance(b, &balance)
+					if num == 7 {
+						meta.PreTokenBalances = append(meta.PreTokenBalances, balance)
+					} else {
+						meta.PostTokenBalances = append(meta.PostTokenBalances, balance)
+					}
+				}
+			}
+		case 12, 13:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					if num == 12 {
+						meta.LoadedAddresses.Writable = append(meta.LoadedAddresses.Writable, b)
+					} else {
+						meta.LoadedAddresses.Readonly = append(meta.LoadedAddresses.Readonly, b)
+					}
+				}
+			}
+		case 14:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				if b, err = r.bytes(); err == nil {
+					meta.ReturnData = &YellowstoneReturnData{}
+					err = decodeMessage(b, func(r *protoReader, num, wireType int) (bool, error) {
+						if wireType != wireBytes || num > 2 {
+							return false, nil
+						}
+						v, err := r.bytes()
+						if num == 1 {
+							meta.ReturnData.ProgramId = v
+						} else {
+							meta.ReturnData.Data = v
+						}
+						return true, err
+					})
+				}
+			}
+		case 16:
+			if err = expect(num, wireType, wireVarint); err == nil {
+				meta.ComputeUnitsConsumed, err = r.varint()
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// decodeInnerInstructions decodes solana.storage.ConfirmedBlock.InnerInstructions
+func decodeInnerInstructions(data []byte, set *YellowstoneInnerInstructionSet) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		switch {
+		case num == 1 && wireType == wireVarint:
+			v, err := r.varint()
+			set.Index = int(v)
+			return true, err
+		case num == 2 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			var ix YellowstoneInstruction
+			err = decodeInstruction(b, &ix)
+			set.Instructions = append(set.Instructions, ix)
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+// decodeTokenBalance decodes solana.storage.ConfirmedBlock.TokenBalance
+func decodeTokenBalance(data []byte, balance *YellowstoneTokenBalance) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		if num == 1 && wireType == wireVarint {
+			v, err := r.varint()
+			balance.AccountIndex = int(v)
+			return true, err
+		}
+		if wireType != wireBytes || num < 2 || num > 4 {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch num {
+		case 2:
+			balance.Mint = string(b)
+		case 3:
+			err = decodeUiTokenAmount(b, &balance.UiTokenAmount)
+		case 4:
+			balance.Owner = string(b)
+		}
+		return true, err
+	})
+}
+
+// decodeUiTokenAmount decodes solana.storage.ConfirmedBlock.UiTokenAmount
+func decodeUiTokenAmount(data []byte, amount *YellowstoneTokenAmount) error {
+	return decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		switch {
+		case num == 1 && wireType == wireFixed64:
+			v, err := r.fixed64()
+			uiAmount := math.Float64frombits(v)
+			amount.UiAmount = &uiAmount
+			return true, err
+		case num == 2 && wireType == wireVarint:
+			v, err := r.varint()
+			amount.Decimals = int(v)
+			return true, err
+		case (num == 3 || num == 4) && wireType == wireBytes:
+			b, err := r.bytes()
+			if num == 3 {
+				amount.Amount = string(b)
+			} else {
+				amount.UiAmountString = string(b)
+			}
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+// ParseYellowstoneBytes decodes a serialized SubscribeUpdateTransaction and parses it.
+// blockTime is not part of transaction updates; pass the block time if known, or 0.
+func (dp *DexParser) ParseYellowstoneBytes(data []byte, blockTime int64, config *types.ParseConfig) *types.ParseResult {
+	tx, slot, err := DecodeYellowstoneTransaction(data)
+	if err != nil {
+		result := types.NewParseResult()
+		failResult(result, types.NewParseError(types.ParseErrorMalformedInput, "", "", err), "Parse error: "+err.Error())
+		return result
+	}
+	return dp.ParseAll(ConvertYellowstoneTransaction(tx, slot, blockTime), config)
+}
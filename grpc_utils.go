@@ -2,16 +2,17 @@ package dexparser
 
 import (
 	"encoding/base64"
-	"encoding/hex"
 
 	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
 	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
 )
 
 // YellowstoneTransaction represents a transaction from Yellowstone gRPC (Helius Laserstream/Triton)
 type YellowstoneTransaction struct {
 	Signature   []byte
 	IsVote      bool
+	Index       uint64 // position within the block
 	Transaction YellowstoneTransactionData
 	Meta        YellowstoneTransactionMeta
 }
@@ -44,6 +45,7 @@ type YellowstoneInstruction struct {
 	ProgramIdIndex int
 	Accounts       []byte
 	Data           []byte
+	StackHeight    *uint32 // set on inner instructions only
 }
 
 // YellowstoneAddressTableLookup represents address table lookup from gRPC
@@ -103,104 +105,68 @@ type YellowstoneReturnData struct {
 }
 
 // ConvertYellowstoneTransaction converts a Yellowstone gRPC transaction to SolanaTransaction format
-// that can be used with DexParser.ParseAll() or ShredParser.ParseAll()
+// that can be used with DexParser.ParseAll() or ShredParser.ParseAll().
+// The result is identical to a getTransaction response fetched with "json" encoding: base58 keys,
+// signatures and instruction data, v0 lookups with loaded addresses in meta, and meta.err as
+// JSON-RPC reports it, so gRPC-fed and RPC-fed transactions parse the same way.
 func ConvertYellowstoneTransaction(grpc *YellowstoneTransaction, slot uint64, blockTime int64) *adapter.SolanaTransaction {
 	if grpc == nil {
 		return nil
 	}
+	msg := grpc.Transaction.Message
 
-	// Convert signatures
 	signatures := make([]string, len(grpc.Transaction.Signatures))
 	for i, sig := range grpc.Transaction.Signatures {
-		signatures[i] = base64.StdEncoding.EncodeToString(sig)
+		signatures[i] = base58.Encode(sig)
 	}
 
-	// Convert account keys
-	accountKeys := make([]adapter.AccountKey, len(grpc.Transaction.Message.AccountKeys))
-	for i, key := range grpc.Transaction.Message.AccountKeys {
-		accountKeys[i] = adapter.AccountKey{
-			Pubkey: hex.EncodeToString(key),
-		}
+	accountKeys := make([]adapter.AccountKey, len(msg.AccountKeys))
+	for i, key := range msg.AccountKeys {
+		accountKeys[i] = adapter.AccountKey{Pubkey: base58.Encode(key)}
 	}
 
-	// Convert instructions
-	instructions := make([]interface{}, len(grpc.Transaction.Message.Instructions))
-	for i, inst := range grpc.Transaction.Message.Instructions {
-		accounts := make([]int, len(inst.Accounts))
-		for j, acc := range inst.Accounts {
-			accounts[j] = int(acc)
-		}
-		instructions[i] = map[string]interface{}{
-			"programIdIndex": inst.ProgramIdIndex,
-			"accounts":       accounts,
-			"data":           base64.StdEncoding.EncodeToString(inst.Data),
+	instructions := make([]interface{}, len(msg.Instructions))
+	for i, inst := range msg.Instructions {
+		instructions[i] = convertYellowstoneInstruction(inst)
+	}
+
+	// RPC only reports lookups, and the version number, for v0 messages
+	var version interface{} = "legacy"
+	var lookups []adapter.AddressTableLookup
+	if msg.Versioned {
+		version = float64(0)
+		lookups = make([]adapter.AddressTableLookup, len(msg.AddressTableLookups))
+		for i, lookup := range msg.AddressTableLookups {
+			lookups[i] = adapter.AddressTableLookup{
+				AccountKey:      base58.Encode(lookup.AccountKey),
+				WritableIndexes: bytesToInts(lookup.WritableIndexes),
+				ReadonlyIndexes: bytesToInts(lookup.ReadonlyIndexes),
+			}
 		}
 	}
 
-	// Convert inner instructions
-	var innerInstructions []adapter.InnerInstructionSet
-	for _, innerSet := range grpc.Meta.InnerInstructions {
+	innerInstructions := make([]adapter.InnerInstructionSet, len(grpc.Meta.InnerInstructions))
+	for i, innerSet := range grpc.Meta.InnerInstructions {
 		innerInsts := make([]interface{}, len(innerSet.Instructions))
 		for j, inst := range innerSet.Instructions {
-			accounts := make([]int, len(inst.Accounts))
-			for k, acc := range inst.Accounts {
-				accounts[k] = int(acc)
-			}
-			innerInsts[j] = map[string]interface{}{
-				"programIdIndex": inst.ProgramIdIndex,
-				"accounts":       accounts,
-				"data":           base64.StdEncoding.EncodeToString(inst.Data),
-			}
+			innerInsts[j] = convertYellowstoneInstruction(inst)
 		}
-		innerInstructions = append(innerInstructions, adapter.InnerInstructionSet{
+		innerInstructions[i] = adapter.InnerInstructionSet{
 			Index:        innerSet.Index,
 			Instructions: innerInsts,
-		})
-	}
-
-	// Convert token balances
-	preTokenBalances := make([]adapter.TokenBalance, len(grpc.Meta.PreTokenBalances))
-	for i, bal := range grpc.Meta.PreTokenBalances {
-		preTokenBalances[i] = adapter.TokenBalance{
-			AccountIndex: bal.AccountIndex,
-			Mint:         bal.Mint,
-			Owner:        bal.Owner,
-			UiTokenAmount: types.TokenAmount{
-				Amount:   bal.UiTokenAmount.Amount,
-				Decimals: uint8(bal.UiTokenAmount.Decimals),
-				UIAmount: bal.UiTokenAmount.UiAmount,
-			},
 		}
 	}
 
-	postTokenBalances := make([]adapter.TokenBalance, len(grpc.Meta.PostTokenBalances))
-	for i, bal := range grpc.Meta.PostTokenBalances {
-		postTokenBalances[i] = adapter.TokenBalance{
-			AccountIndex: bal.AccountIndex,
-			Mint:         bal.Mint,
-			Owner:        bal.Owner,
-			UiTokenAmount: types.TokenAmount{
-				Amount:   bal.UiTokenAmount.Amount,
-				Decimals: uint8(bal.UiTokenAmount.Decimals),
-				UIAmount: bal.UiTokenAmount.UiAmount,
-			},
-		}
+	loadedAddresses := &adapter.LoadedAddresses{
+		Writable: encodeKeys(grpc.Meta.LoadedAddresses.Writable),
+		Readonly: encodeKeys(grpc.Meta.LoadedAddresses.Readonly),
 	}
 
-	// Convert loaded addresses
-	var loadedAddresses *adapter.LoadedAddresses
-	if len(grpc.Meta.LoadedAddresses.Writable) > 0 || len(grpc.Meta.LoadedAddresses.Readonly) > 0 {
-		writable := make([]string, len(grpc.Meta.LoadedAddresses.Writable))
-		for i, addr := range grpc.Meta.LoadedAddresses.Writable {
-			writable[i] = hex.EncodeToString(addr)
-		}
-		readonly := make([]string, len(grpc.Meta.LoadedAddresses.Readonly))
-		for i, addr := range grpc.Meta.LoadedAddresses.Readonly {
-			readonly[i] = hex.EncodeToString(addr)
-		}
-		loadedAddresses = &adapter.LoadedAddresses{
-			Writable: writable,
-			Readonly: readonly,
+	var returnData *adapter.ReturnData
+	if grpc.Meta.ReturnData != nil {
+		returnData = &adapter.ReturnData{
+			ProgramId: base58.Encode(grpc.Meta.ReturnData.ProgramId),
+			Data:      []string{base64.StdEncoding.EncodeToString(grpc.Meta.ReturnData.Data), "base64"},
 		}
 	}
 
@@ -211,26 +177,98 @@ func ConvertYellowstoneTransaction(grpc *YellowstoneTransaction, slot uint64, bl
 			Signatures: signatures,
 			Message: adapter.TransactionMessage{
 				Header: &adapter.MessageHeader{
-					NumRequiredSignatures:       grpc.Transaction.Message.Header.NumRequiredSignatures,
-					NumReadonlySignedAccounts:   grpc.Transaction.Message.Header.NumReadonlySignedAccounts,
-					NumReadonlyUnsignedAccounts: grpc.Transaction.Message.Header.NumReadonlyUnsignedAccounts,
+					NumRequiredSignatures:       msg.Header.NumRequiredSignatures,
+					NumReadonlySignedAccounts:   msg.Header.NumReadonlySignedAccounts,
+					NumReadonlyUnsignedAccounts: msg.Header.NumReadonlyUnsignedAccounts,
 				},
-				AccountKeys:  accountKeys,
-				Instructions: instructions,
+				AccountKeys:         accountKeys,
+				RecentBlockhash:     base58.Encode(msg.RecentBlockhash),
+				Instructions:        instructions,
+				AddressTableLookups: lookups,
 			},
 		},
 		Meta: &adapter.TransactionMeta{
+			Err:                  convertYellowstoneError(grpc.Meta.Err),
 			Fee:                  grpc.Meta.Fee,
-			PreBalances:          grpc.Meta.PreBalances,
-			PostBalances:         grpc.Meta.PostBalances,
-			PreTokenBalances:     preTokenBalances,
-			PostTokenBalances:    postTokenBalances,
+			PreBalances:          append([]uint64{}, grpc.Meta.PreBalances...),
+			PostBalances:         append([]uint64{}, grpc.Meta.PostBalances...),
+			PreTokenBalances:     convertYellowstoneTokenBalances(grpc.Meta.PreTokenBalances),
+			PostTokenBalances:    convertYellowstoneTokenBalances(grpc.Meta.PostTokenBalances),
 			InnerInstructions:    innerInstructions,
-			LogMessages:          grpc.Meta.LogMessages,
+			LogMessages:          append([]string{}, grpc.Meta.LogMessages...),
 			LoadedAddresses:      loadedAddresses,
 			ComputeUnitsConsumed: &computeUnits,
+			ReturnData:           returnData,
 		},
 		Slot:      slot,
+		Index:     grpc.Index,
 		BlockTime: &blockTime,
+		Version:   version,
+		IsVote:    grpc.IsVote,
+	}
+}
+
+// convertYellowstoneInstruction builds the instruction object of a "json" encoded RPC response,
+// with numbers as float64 as json.Unmarshal produces them
+func convertYellowstoneInstruction(inst YellowstoneInstruction) map[string]interface{} {
+	accounts := make([]interface{}, len(inst.Accounts))
+	for i, acc := range inst.Accounts {
+		accounts[i] = float64(acc)
+	}
+	var stackHeight interface{}
+	if inst.StackHeight != nil {
+		stackHeight = float64(*inst.StackHeight)
+	}
+	return map[string]interface{}{
+		"programIdIndex": float64(inst.ProgramIdIndex),
+		"accounts":       accounts,
+		"data":           base58.Encode(inst.Data),
+		"stackHeight":    stackHeight,
+	}
+}
+
+// convertYellowstoneError decodes a bincode meta.err into its JSON-RPC form.
+// Undecodable bytes are kept as they are so the transaction still reports as failed.
+func convertYellowstoneError(err interface{}) interface{} {
+	raw, ok := err.([]byte)
+	if !ok {
+		return err
+	}
+	if decoded, decodeErr := adapter.DecodeTransactionError(raw); decodeErr == nil {
+		return decoded
+	}
+	return raw
+}
+
+func convertYellowstoneTokenBalances(balances []YellowstoneTokenBalance) []adapter.TokenBalance {
+	result := make([]adapter.TokenBalance, len(balances))
+	for i, bal := range balances {
+		result[i] = adapter.TokenBalance{
+			AccountIndex: bal.AccountIndex,
+			Mint:         bal.Mint,
+			Owner:        bal.Owner,
+			UiTokenAmount: types.TokenAmount{
+				Amount:   bal.UiTokenAmount.Amount,
+				Decimals: uint8(bal.UiTokenAmount.Decimals),
+				UIAmount: bal.UiTokenAmount.UiAmount,
+			},
+		}
+	}
+	return result
+}
+
+func encodeKeys(keys [][]byte) []string {
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = base58.Encode(key)
+	}
+	return result
+}
+
+func bytesToInts(b []byte) []int {
+	result := make([]int, len(b))
+	for i, v := range b {
+		result[i] = int(v)
 	}
+	return result
 }
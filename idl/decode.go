@@ -0,0 +1,289 @@
+package idl
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// maxDepth bounds nesting of defined types so recursive IDL types cannot loop forever
+const maxDepth = 32
+
+// Decoded is a decoded instruction or event.
+//
+// Values maps field names (as written in the IDL) to Go values: bool, uint8..uint64,
+// int8..int64, float32/float64, *big.Int for u128/i128, string, []byte for bytes,
+// base58 string for pubkeys, []any for vec/array, nil for empty options and
+// map[string]any for structs. Unit enum variants decode to the variant name and
+// variants with fields to map[string]any{variant: fields}.
+type Decoded struct {
+	Name     string            `json:"name"`
+	Values   map[string]any    `json:"values"`
+	Accounts map[string]string `json:"accounts,omitempty"` // instruction accounts by IDL name
+}
+
+// Into copies the decoded values into a struct or map pointed to by v.
+// Struct fields are matched by `idl:"name"` tag, then `json` tag, then by name ignoring case and underscores.
+func (d *Decoded) Into(v any) error {
+	return assign(v, d.Values)
+}
+
+// DecodeInstruction decodes instruction data; accounts, if given, are named after the IDL instruction accounts
+func (idl *IDL) DecodeInstruction(data []byte, accounts []string) (*Decoded, error) {
+	ix := idl.matchInstruction(data)
+	if ix == nil {
+		return nil, ErrUnknownDiscriminator
+	}
+	values, err := idl.decodeFields(data[len(ix.Discriminator):], ix.Args)
+	if err != nil {
+		return nil, fmt.Errorf("idl: decode instruction %s: %w", ix.Name, err)
+	}
+
+	decoded := &Decoded{Name: ix.Name, Values: values}
+	if len(accounts) > 0 {
+		decoded.Accounts = make(map[string]string, len(ix.Accounts))
+		for i, name := range ix.Accounts {
+			if i >= len(accounts) {
+				break
+			}
+			decoded.Accounts[name] = accounts[i]
+		}
+	}
+	return decoded, nil
+}
+
+// DecodeEvent decodes event data, either as logged via "Program data:" or wrapped in an emit_cpi! instruction
+func (idl *IDL) DecodeEvent(data []byte) (*Decoded, error) {
+	data = bytes.TrimPrefix(data, EventIxTag)
+	ev := idl.matchEvent(data)
+	if ev == nil {
+		return nil, ErrUnknownDiscriminator
+	}
+	values, err := idl.decodeFields(data[len(ev.Discriminator):], ev.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("idl: decode event %s: %w", ev.Name, err)
+	}
+	return &Decoded{Name: ev.Name, Values: values}, nil
+}
+
+// DecodeType decodes Borsh data as the named type from the IDL types section
+func (idl *IDL) DecodeType(name string, data []byte) (any, error) {
+	reader := utils.GetBinaryReader(data)
+	defer reader.Release()
+	return idl.decodeValue(reader, &Type{Kind: KindDefined, Name: name}, 0)
+}
+
+// decodeFields decodes data as a sequence of fields
+func (idl *IDL) decodeFields(data []byte, fields []Field) (map[string]any, error) {
+	reader := utils.GetBinaryReader(data)
+	defer reader.Release()
+	return idl.readFields(reader, fields, 0)
+}
+
+func (idl *IDL) readFields(reader *utils.BinaryReader, fields []Field, depth int) (map[string]any, error) {
+	values := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v, err := idl.decodeValue(reader, f.Type, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		values[f.Name] = v
+	}
+	return values, nil
+}
+
+// decodeValue reads one Borsh value of type t
+func (idl *IDL) decodeValue(reader *utils.BinaryReader, t *Type, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nesting deeper than %d", ErrUnsupportedType, maxDepth)
+	}
+
+	switch t.Kind {
+	case KindBool:
+		return reader.ReadBool()
+	case KindU8:
+		return reader.ReadU8()
+	case KindI8:
+		v, err := reader.ReadU8()
+		return int8(v), err
+	case KindU16:
+		return reader.ReadU16()
+	case KindI16:
+		v, err := reader.ReadU16()
+		return int16(v), err
+	case KindU32:
+		return reader.ReadU32()
+	case KindI32:
+		v, err := reader.ReadU32()
+		return int32(v), err
+	case KindF32:
+		v, err := reader.ReadU32()
+		return math.Float32frombits(v), err
+	case KindU64:
+		return reader.ReadU64()
+	case KindI64:
+		return reader.ReadI64()
+	case KindF64:
+		v, err := reader.ReadU64()
+		return math.Float64frombits(v), err
+	case KindU128:
+		lo, hi, err := reader.ReadU128()
+		if err != nil {
+			return nil, err
+		}
+		v := new(big.Int).SetUint64(hi)
+		return v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(lo)), nil
+	case KindI128:
+		lo, hi, err := reader.ReadI128()
+		if err != nil {
+			return nil, err
+		}
+		v := big.NewInt(hi)
+		return v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(lo)), nil
+	case KindString:
+		return reader.ReadString()
+	case KindBytes:
+		n, err := reader.ReadU32()
+		if err != nil {
+			return nil, err
+		}
+		return reader.ReadFixedArray(int(n))
+	case KindPubkey:
+		return reader.ReadPubkey()
+	case KindOption, KindCOption:
+		var present bool
+		if t.Kind == KindOption {
+			tag, err := reader.ReadU8()
+			if err != nil {
+				return nil, err
+			}
+			present = tag != 0
+		} else {
+			tag, err := reader.ReadU32()
+			if err != nil {
+				return nil, err
+			}
+			present = tag != 0
+		}
+		if !present {
+			return nil, nil
+		}
+		return idl.decodeValue(reader, t.Elem, depth+1)
+	case KindVec:
+		n, err := reader.ReadU32()
+		if err != nil {
+			return nil, err
+		}
+		// Zero-size elements consume no data, so their count is bounded by the data as well
+		size := idl.minSize(t.Elem, depth)
+		if int64(n)*max(size, 1) > int64(reader.Remaining()) {
+			return nil, fmt.Errorf("vec length %d exceeds remaining data", n)
+		}
+		return idl.decodeSeq(reader, t.Elem, int(n), depth)
+	case KindArray:
+		return idl.decodeSeq(reader, t.Elem, t.Len, depth)
+	case KindDefined:
+		def, ok := idl.Types[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: undefined type %q", ErrUnsupportedType, t.Name)
+		}
+		return idl.decodeDefined(reader, def, depth+1)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedType, t.Kind)
+}
+
+func (idl *IDL) decodeSeq(reader *utils.BinaryReader, elem *Type, n, depth int) (any, error) {
+	values := make([]any, 0, min(n, reader.Remaining()))
+	for i := 0; i < n; i++ {
+		v, err := idl.decodeValue(reader, elem, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (idl *IDL) decodeDefined(reader *utils.BinaryReader, def *TypeDef, depth int) (any, error) {
+	switch def.Kind {
+	case "struct":
+		return idl.readFields(reader, def.Fields, depth)
+	case "enum":
+		tag, err := reader.ReadU8()
+		if err != nil {
+			return nil, err
+		}
+		if int(tag) >= len(def.Variants) {
+			return nil, fmt.Errorf("enum %s: variant %d out of range", def.Name, tag)
+		}
+		variant := def.Variants[tag]
+		if len(variant.Fields) == 0 {
+			return variant.Name, nil
+		}
+		fields, err := idl.readFields(reader, variant.Fields, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s::%s: %w", def.Name, variant.Name, err)
+		}
+		return map[string]any{variant.Name: fields}, nil
+	default:
+		return idl.decodeValue(reader, def.Alias, depth)
+	}
+}
+
+// minSize returns the minimum encoded size of a type, used to reject impossible vec lengths.
+// Sizes are capped at math.MaxInt32 so nested arrays cannot overflow.
+func (idl *IDL) minSize(t *Type, depth int) int64 {
+	if t == nil || depth > maxDepth {
+		return 0
+	}
+	switch t.Kind {
+	case KindBool, KindU8, KindI8, KindOption:
+		return 1
+	case KindU16, KindI16:
+		return 2
+	case KindU32, KindI32, KindF32, KindString, KindBytes, KindVec, KindCOption:
+		return 4
+	case KindU64, KindI64, KindF64:
+		return 8
+	case KindU128, KindI128:
+		return 16
+	case KindPubkey:
+		return 32
+	case KindArray:
+		return min(int64(t.Len)*idl.minSize(t.Elem, depth+1), math.MaxInt32)
+	case KindDefined:
+		def, ok := idl.Types[t.Name]
+		if !ok {
+			return 0
+		}
+		switch def.Kind {
+		case "struct":
+			return idl.fieldsSize(def.Fields, depth+1)
+		case "enum":
+			// The tag, plus the smallest variant
+			var smallest int64 = math.MaxInt32
+			for _, variant := range def.Variants {
+				smallest = min(smallest, idl.fieldsSize(variant.Fields, depth+1))
+			}
+			if len(def.Variants) == 0 {
+				smallest = 0
+			}
+			return min(1+smallest, math.MaxInt32)
+		default:
+			return idl.minSize(def.Alias, depth+1)
+		}
+	}
+	return 0
+}
+
+// fieldsSize returns the minimum encoded size of a sequence of fields
+func (idl *IDL) fieldsSize(fields []Field, depth int) int64 {
+	var size int64
+	for _, field := range fields {
+		size = min(size+idl.minSize(field.Type, depth), math.MaxInt32)
+	}
+	return size
+}
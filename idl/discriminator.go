@@ -0,0 +1,49 @@
+package idl
+
+import (
+	"crypto/sha256"
+	"strings"
+	"unicode"
+)
+
+// EventIxTag prefixes the data of self-CPI instructions emitted by Anchor's emit_cpi!
+var EventIxTag = []byte{228, 69, 165, 46, 81, 203, 154, 29}
+
+// InstructionDiscriminator returns the Anchor discriminator of an instruction: sha256("global:<snake_name>")[:8]
+func InstructionDiscriminator(name string) []byte {
+	return hashPrefix("global:" + toSnakeCase(name))
+}
+
+// EventDiscriminator returns the Anchor discriminator of an event: sha256("event:<Name>")[:8]
+func EventDiscriminator(name string) []byte {
+	return hashPrefix("event:" + name)
+}
+
+// AccountDiscriminator returns the Anchor discriminator of an account: sha256("account:<Name>")[:8]
+func AccountDiscriminator(name string) []byte {
+	return hashPrefix("account:" + name)
+}
+
+func hashPrefix(preimage string) []byte {
+	sum := sha256.Sum256([]byte(preimage))
+	return sum[:8]
+}
+
+// toSnakeCase converts legacy camelCase instruction names the way Anchor does (buyExactIn -> buy_exact_in)
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
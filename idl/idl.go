@@ -0,0 +1,416 @@
+// Package idl decodes Anchor program instructions and events from the program's IDL JSON.
+//
+// Both the legacy (pre-0.30) and the current Anchor IDL formats are supported. Decoded
+// instructions and events can be read as map[string]any, copied into caller structs with
+// Decoded.Into, or turned into TradeInfo/PoolEvent values through a declarative Mapping.
+package idl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-json"
+)
+
+// TypeKind is the kind of an IDL type
+type TypeKind string
+
+const (
+	KindBool    TypeKind = "bool"
+	KindU8      TypeKind = "u8"
+	KindI8      TypeKind = "i8"
+	KindU16     TypeKind = "u16"
+	KindI16     TypeKind = "i16"
+	KindU32     TypeKind = "u32"
+	KindI32     TypeKind = "i32"
+	KindF32     TypeKind = "f32"
+	KindU64     TypeKind = "u64"
+	KindI64     TypeKind = "i64"
+	KindF64     TypeKind = "f64"
+	KindU128    TypeKind = "u128"
+	KindI128    TypeKind = "i128"
+	KindString  TypeKind = "string"
+	KindBytes   TypeKind = "bytes"
+	KindPubkey  TypeKind = "pubkey"
+	KindVec     TypeKind = "vec"
+	KindOption  TypeKind = "option"
+	KindCOption TypeKind = "coption"
+	KindArray   TypeKind = "array"
+	KindDefined TypeKind = "defined"
+)
+
+// primitiveKinds lists the kinds that can appear as a plain string in IDL JSON
+var primitiveKinds = map[string]TypeKind{
+	"bool": KindBool, "u8": KindU8, "i8": KindI8, "u16": KindU16, "i16": KindI16,
+	"u32": KindU32, "i32": KindI32, "f32": KindF32, "u64": KindU64, "i64": KindI64,
+	"f64": KindF64, "u128": KindU128, "i128": KindI128, "string": KindString,
+	"bytes": KindBytes, "pubkey": KindPubkey, "publicKey": KindPubkey,
+}
+
+var (
+	// ErrUnknownDiscriminator is returned when data matches no instruction or event of the IDL
+	ErrUnknownDiscriminator = errors.New("idl: unknown discriminator")
+	// ErrUnsupportedType is returned for IDL types the decoder cannot handle (e.g. generics)
+	ErrUnsupportedType = errors.New("idl: unsupported type")
+)
+
+// Type is an IDL type reference
+type Type struct {
+	Kind TypeKind `json:"kind"`
+	Elem *Type    `json:"elem,omitempty"` // element of vec, option, coption and array
+	Len  int      `json:"len,omitempty"`  // length of array
+	Name string   `json:"name,omitempty"` // name of a defined type
+}
+
+// Field is a named struct field, instruction argument or event field
+type Field struct {
+	Name string `json:"name"`
+	Type *Type  `json:"type"`
+}
+
+// Variant is an enum variant; unit variants have no fields
+type Variant struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// TypeDef is a type from the IDL "types" section
+type TypeDef struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"` // struct, enum or type (alias)
+	Fields   []Field   `json:"fields,omitempty"`
+	Variants []Variant `json:"variants,omitempty"`
+	Alias    *Type     `json:"alias,omitempty"`
+}
+
+// Instruction is a program instruction
+type Instruction struct {
+	Name          string   `json:"name"`
+	Discriminator []byte   `json:"discriminator"`
+	Accounts      []string `json:"accounts"` // account names in instruction order, nested groups flattened
+	Args          []Field  `json:"args"`
+}
+
+// Event is a program event
+type Event struct {
+	Name          string  `json:"name"`
+	Discriminator []byte  `json:"discriminator"`
+	Fields        []Field `json:"fields"`
+}
+
+// IDL is a parsed Anchor IDL
+type IDL struct {
+	Address      string              `json:"address"`
+	Name         string              `json:"name"`
+	Version      string              `json:"version"`
+	Instructions []Instruction       `json:"instructions"`
+	Events       []Event             `json:"events"`
+	Types        map[string]*TypeDef `json:"types"`
+}
+
+// Load reads and parses an IDL JSON file
+func Load(path string) (*IDL, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses IDL JSON in the legacy or current Anchor format
+func Parse(data []byte) (*IDL, error) {
+	var raw rawIDL
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("idl: %w", err)
+	}
+
+	idl := &IDL{
+		Address: raw.Address,
+		Name:    raw.Name,
+		Version: raw.Version,
+		Types:   make(map[string]*TypeDef, len(raw.Types)),
+	}
+	if idl.Address == "" {
+		idl.Address = raw.Metadata.Address
+	}
+	if idl.Name == "" {
+		idl.Name = raw.Metadata.Name
+	}
+	if idl.Version == "" {
+		idl.Version = raw.Metadata.Version
+	}
+
+	for _, rt := range raw.Types {
+		def, err := rt.parse()
+		if err != nil {
+			return nil, err
+		}
+		idl.Types[def.Name] = def
+	}
+
+	for _, ri := range raw.Instructions {
+		args, err := parseFields(ri.Args)
+		if err != nil {
+			return nil, fmt.Errorf("idl: instruction %s: %w", ri.Name, err)
+		}
+		disc := intsToBytes(ri.Discriminator)
+		if len(disc) == 0 {
+			disc = InstructionDiscriminator(ri.Name)
+		}
+		idl.Instructions = append(idl.Instructions, Instruction{
+			Name:          ri.Name,
+			Discriminator: disc,
+			Accounts:      flattenAccounts(ri.Accounts, nil),
+			Args:          args,
+		})
+	}
+
+	for _, re := range raw.Events {
+		fields, err := parseFields(re.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("idl: event %s: %w", re.Name, err)
+		}
+		// Current IDLs describe event fields in the types section
+		if len(re.Fields) == 0 {
+			if def, ok := idl.Types[re.Name]; ok {
+				fields = def.Fields
+			}
+		}
+		disc := intsToBytes(re.Discriminator)
+		if len(disc) == 0 {
+			disc = EventDiscriminator(re.Name)
+		}
+		idl.Events = append(idl.Events, Event{Name: re.Name, Discriminator: disc, Fields: fields})
+	}
+
+	return idl, nil
+}
+
+// Instruction returns the instruction with the given name, or nil
+func (idl *IDL) Instruction(name string) *Instruction {
+	for i := range idl.Instructions {
+		if idl.Instructions[i].Name == name {
+			return &idl.Instructions[i]
+		}
+	}
+	return nil
+}
+
+// Event returns the event with the given name, or nil
+func (idl *IDL) Event(name string) *Event {
+	for i := range idl.Events {
+		if idl.Events[i].Name == name {
+			return &idl.Events[i]
+		}
+	}
+	return nil
+}
+
+// matchInstruction returns the instruction whose discriminator prefixes data
+func (idl *IDL) matchInstruction(data []byte) *Instruction {
+	for i := range idl.Instructions {
+		if disc := idl.Instructions[i].Discriminator; len(disc) > 0 && bytes.HasPrefix(data, disc) {
+			return &idl.Instructions[i]
+		}
+	}
+	return nil
+}
+
+// matchEvent returns the event whose discriminator prefixes data
+func (idl *IDL) matchEvent(data []byte) *Event {
+	for i := range idl.Events {
+		if disc := idl.Events[i].Discriminator; len(disc) > 0 && bytes.HasPrefix(data, disc) {
+			return &idl.Events[i]
+		}
+	}
+	return nil
+}
+
+// rawIDL is the union of the legacy and current IDL JSON layouts
+type rawIDL struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Metadata struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Address string `json:"address"`
+	} `json:"metadata"`
+	Instructions []rawInstruction `json:"instructions"`
+	Events       []rawEvent       `json:"events"`
+	Types        []rawTypeDef     `json:"types"`
+}
+
+type rawInstruction struct {
+	Name          string            `json:"name"`
+	Discriminator []int             `json:"discriminator"`
+	Accounts      []rawAccount      `json:"accounts"`
+	Args          []json.RawMessage `json:"args"`
+}
+
+type rawAccount struct {
+	Name     string       `json:"name"`
+	Accounts []rawAccount `json:"accounts"`
+}
+
+type rawEvent struct {
+	Name          string            `json:"name"`
+	Discriminator []int             `json:"discriminator"`
+	Fields        []json.RawMessage `json:"fields"`
+}
+
+type rawTypeDef struct {
+	Name string `json:"name"`
+	Type struct {
+		Kind     string            `json:"kind"`
+		Fields   []json.RawMessage `json:"fields"`
+		Variants []struct {
+			Name   string            `json:"name"`
+			Fields []json.RawMessage `json:"fields"`
+		} `json:"variants"`
+		Alias json.RawMessage `json:"alias"`
+		Value json.RawMessage `json:"value"`
+	} `json:"type"`
+}
+
+// parse converts a raw type definition
+func (rt *rawTypeDef) parse() (*TypeDef, error) {
+	def := &TypeDef{Name: rt.Name, Kind: rt.Type.Kind}
+	var err error
+	switch rt.Type.Kind {
+	case "struct":
+		def.Fields, err = parseFields(rt.Type.Fields)
+	case "enum":
+		for _, rv := range rt.Type.Variants {
+			fields, ferr := parseFields(rv.Fields)
+			if ferr != nil {
+				err = ferr
+				break
+			}
+			def.Variants = append(def.Variants, Variant{Name: rv.Name, Fields: fields})
+		}
+	case "type", "alias":
+		alias := rt.Type.Alias
+		if len(alias) == 0 {
+			alias = rt.Type.Value
+		}
+		def.Kind = "type"
+		def.Alias, err = parseType(alias)
+	default:
+		err = fmt.Errorf("%w: type kind %q", ErrUnsupportedType, rt.Type.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("idl: type %s: %w", rt.Name, err)
+	}
+	return def, nil
+}
+
+// parseFields parses named fields, or tuple fields named "0", "1", ...
+func parseFields(raw []json.RawMessage) ([]Field, error) {
+	fields := make([]Field, 0, len(raw))
+	for i, r := range raw {
+		var named struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		}
+		if r = bytes.TrimSpace(r); len(r) > 0 && r[0] == '{' {
+			if err := json.Unmarshal(r, &named); err != nil {
+				return nil, err
+			}
+		}
+		if named.Name != "" && len(named.Type) > 0 {
+			t, err := parseType(named.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", named.Name, err)
+			}
+			fields = append(fields, Field{Name: named.Name, Type: t})
+			continue
+		}
+		t, err := parseType(r)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", i, err)
+		}
+		fields = append(fields, Field{Name: fmt.Sprint(i), Type: t})
+	}
+	return fields, nil
+}
+
+// parseType parses a type given as a string or as a vec/option/coption/array/defined object
+func parseType(raw json.RawMessage) (*Type, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		kind, ok := primitiveKinds[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedType, name)
+		}
+		return &Type{Kind: kind}, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, raw)
+	}
+	for _, kind := range []TypeKind{KindVec, KindOption, KindCOption} {
+		if elem, ok := obj[string(kind)]; ok {
+			t, err := parseType(elem)
+			if err != nil {
+				return nil, err
+			}
+			return &Type{Kind: kind, Elem: t}, nil
+		}
+	}
+	if arr, ok := obj[string(KindArray)]; ok {
+		var parts []json.RawMessage
+		if err := json.Unmarshal(arr, &parts); err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("%w: array %s", ErrUnsupportedType, arr)
+		}
+		var n int
+		if err := json.Unmarshal(parts[1], &n); err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: array length %s", ErrUnsupportedType, parts[1])
+		}
+		t, err := parseType(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: KindArray, Elem: t, Len: n}, nil
+	}
+	if defined, ok := obj[string(KindDefined)]; ok {
+		var name string
+		if err := json.Unmarshal(defined, &name); err != nil {
+			var ref struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(defined, &ref); err != nil || ref.Name == "" {
+				return nil, fmt.Errorf("%w: defined %s", ErrUnsupportedType, defined)
+			}
+			name = ref.Name
+		}
+		return &Type{Kind: KindDefined, Name: name}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, raw)
+}
+
+// flattenAccounts lists account names depth-first, flattening nested account groups
+func flattenAccounts(accounts []rawAccount, out []string) []string {
+	for _, a := range accounts {
+		if len(a.Accounts) > 0 {
+			out = flattenAccounts(a.Accounts, out)
+			continue
+		}
+		out = append(out, a.Name)
+	}
+	return out
+}
+
+func intsToBytes(ints []int) []byte {
+	if len(ints) == 0 {
+		return nil
+	}
+	b := make([]byte, len(ints))
+	for i, v := range ints {
+		b[i] = byte(v)
+	}
+	return b
+}
@@ -0,0 +1,222 @@
+package idl
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// assign copies a decoded value into the value pointed to by target
+func assign(target any, value any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("idl: Into requires a non-nil pointer")
+	}
+	return setValue(rv.Elem(), value)
+}
+
+// setValue converts a decoded value to dst's type and stores it
+func setValue(dst reflect.Value, value any) error {
+	if value == nil {
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return setValue(dst.Elem(), value)
+	}
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if dst.Type() == bigIntType {
+		n, ok := toBigInt(value)
+		if !ok {
+			return fmt.Errorf("idl: cannot convert %T to big.Int", value)
+		}
+		dst.Set(reflect.ValueOf(*n))
+		return nil
+	}
+
+	src := reflect.ValueOf(value)
+	switch dst.Kind() {
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		switch v := value.(type) {
+		case string:
+			dst.SetString(v)
+			return nil
+		case *big.Int:
+			dst.SetString(v.String())
+			return nil
+		}
+		if isNumber(src) {
+			dst.SetString(fmt.Sprint(value))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := toBigInt(value); ok && n.IsInt64() && !dst.OverflowInt(n.Int64()) {
+			dst.SetInt(n.Int64())
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := toBigInt(value); ok && n.IsUint64() && !dst.OverflowUint(n.Uint64()) {
+			dst.SetUint(n.Uint64())
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(src.Float())
+			return nil
+		}
+		if n, ok := toBigInt(value); ok {
+			f, _ := new(big.Float).SetInt(n).Float64()
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		if items, ok := value.([]any); ok {
+			out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+			for i, item := range items {
+				if err := setValue(out.Index(i), item); err != nil {
+					return fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+			dst.Set(out)
+			return nil
+		}
+	case reflect.Array:
+		if items, ok := value.([]any); ok && len(items) == dst.Len() {
+			for i, item := range items {
+				if err := setValue(dst.Index(i), item); err != nil {
+					return fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := value.(map[string]any); ok && dst.Type().Key().Kind() == reflect.String {
+			if dst.IsNil() {
+				dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+			}
+			for k, item := range m {
+				elem := reflect.New(dst.Type().Elem()).Elem()
+				if err := setValue(elem, item); err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+				dst.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+			}
+			return nil
+		}
+	case reflect.Struct:
+		if m, ok := value.(map[string]any); ok {
+			return setStruct(dst, m)
+		}
+	}
+	return fmt.Errorf("idl: cannot convert %T to %s", value, dst.Type())
+}
+
+// setStruct fills exported struct fields from decoded values
+func setStruct(dst reflect.Value, values map[string]any) error {
+	normalized := make(map[string]string, len(values))
+	for k := range values {
+		normalized[normalizeName(k)] = k
+	}
+
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, ok := "", false
+		if tag := tagName(field.Tag.Get("idl")); tag != "" {
+			key, ok = tag, true
+		} else if tag := tagName(field.Tag.Get("json")); tag != "" {
+			key, ok = normalized[normalizeName(tag)]
+		} else {
+			key, ok = normalized[normalizeName(field.Name)]
+		}
+		if !ok {
+			continue
+		}
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if err := setValue(dst.Field(i), value); err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// tagName returns the name part of a struct tag, or "" for missing and "-" tags
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// normalizeName lowercases a name and drops underscores so sol_amount matches SolAmount and solAmount
+func normalizeName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// isNumber reports whether v is an integer or float kind
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toBigInt converts decoded integers (and *big.Int) to *big.Int
+func toBigInt(value any) (*big.Int, bool) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, true
+	case uint8:
+		return new(big.Int).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Int).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Int).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Int).SetUint64(v), true
+	case int8:
+		return big.NewInt(int64(v)), true
+	case int16:
+		return big.NewInt(int64(v)), true
+	case int32:
+		return big.NewInt(int64(v)), true
+	case int64:
+		return big.NewInt(v), true
+	case int:
+		return big.NewInt(int64(v)), true
+	case uint:
+		return new(big.Int).SetUint64(uint64(v)), true
+	}
+	return nil, false
+}
@@ -0,0 +1,431 @@
+package idl
+
+import (
+	"bytes"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// Mapping declares how decoded instructions and events become trades and pool events.
+//
+// Field references name a value of the source: a field of the event (or of the instruction
+// arguments when no event is mapped), "args.<name>" for instruction arguments,
+// "accounts.<name>" for instruction accounts, and dotted paths into structs and vecs (fees.0).
+type Mapping struct {
+	Trades     []TradeMapping     `json:"trades,omitempty"`
+	PoolEvents []PoolEventMapping `json:"poolEvents,omitempty"`
+}
+
+// TradeMapping builds a BUY/SELL trade of BaseMint against QuoteMint.
+// With both Event and Instruction set, the event is paired with the closest preceding
+// instruction of that name in the same outer instruction.
+type TradeMapping struct {
+	Event         string          `json:"event,omitempty"`
+	Instruction   string          `json:"instruction,omitempty"`
+	Type          types.TradeType `json:"type,omitempty"`  // fixed BUY or SELL
+	IsBuy         string          `json:"isBuy,omitempty"` // bool field reference; overrides Type
+	User          string          `json:"user,omitempty"`  // defaults to the transaction signer
+	Pool          string          `json:"pool,omitempty"`
+	BaseMint      string          `json:"baseMint"`
+	QuoteMint     string          `json:"quoteMint,omitempty"` // defaults to SOL
+	BaseAmount    string          `json:"baseAmount"`
+	QuoteAmount   string          `json:"quoteAmount"`
+	BaseDecimals  string          `json:"baseDecimals,omitempty"`  // defaults to the decimals known to the transaction
+	QuoteDecimals string          `json:"quoteDecimals,omitempty"` // defaults to the decimals known to the transaction
+	Fee           string          `json:"fee,omitempty"`           // fee amount in the quote token
+}
+
+// PoolEventMapping builds a pool event; Token0 is the base token and Token1 the quote token
+type PoolEventMapping struct {
+	Event        string              `json:"event,omitempty"`
+	Instruction  string              `json:"instruction,omitempty"`
+	Type         types.PoolEventType `json:"type"`
+	User         string              `json:"user,omitempty"` // defaults to the transaction signer
+	Pool         string              `json:"pool"`
+	Config       string              `json:"config,omitempty"`
+	LpMint       string              `json:"lpMint,omitempty"`
+	Token0Mint   string              `json:"token0Mint,omitempty"`
+	Token1Mint   string              `json:"token1Mint,omitempty"`
+	Token0Amount string              `json:"token0Amount,omitempty"`
+	Token1Amount string              `json:"token1Amount,omitempty"`
+	LpAmount     string              `json:"lpAmount,omitempty"`
+}
+
+// Program decodes one program's instructions through its IDL and Mapping
+type Program struct {
+	IDL       *IDL
+	ProgramId string
+	AMM       string
+	Mapping   Mapping
+}
+
+// NewProgram creates a Program for the IDL address, named after the known program name or the IDL name
+func NewProgram(idl *IDL, mapping Mapping) *Program {
+	amm := constants.GetProgramName(idl.Address)
+	if amm == "" {
+		amm = idl.Name
+	}
+	return &Program{IDL: idl, ProgramId: idl.Address, AMM: amm, Mapping: mapping}
+}
+
+// NewTradeParser matches dexparser.TradeParserFactory, e.g. dexparser.WithTradeParser(p.ProgramId, p.NewTradeParser)
+func (p *Program) NewTradeParser(
+	adapter *adapter.TransactionAdapter,
+	dexInfo types.DexInfo,
+	transferActions map[string][]types.TransferData,
+	classifiedInstructions []types.ClassifiedInstruction,
+) parsers.TradeParser {
+	return &programParser{program: p, BaseParser: parsers.NewBaseParser(adapter, dexInfo, transferActions, classifiedInstructions)}
+}
+
+// NewLiquidityParser matches dexparser.LiquidityParserFactory
+func (p *Program) NewLiquidityParser(
+	adapter *adapter.TransactionAdapter,
+	transferActions map[string][]types.TransferData,
+	classifiedInstructions []types.ClassifiedInstruction,
+) parsers.LiquidityParser {
+	return &programParser{program: p, BaseParser: parsers.NewBaseParser(adapter, types.DexInfo{}, transferActions, classifiedInstructions)}
+}
+
+// programParser implements parsers.TradeParser and parsers.LiquidityParser for a Program
+type programParser struct {
+	*parsers.BaseParser
+	program *Program
+}
+
+// decodedSource is a decoded event and/or the instruction it belongs to
+type decodedSource struct {
+	event       *Decoded
+	instruction *Decoded
+	paired      map[string]*Decoded // instructions preceding an event, by name
+	idx         string
+}
+
+// sources decodes the program's instructions and events and pairs events with their instructions.
+// Events come from emit_cpi! self-invocations and from "Program data:" log lines.
+func (p *programParser) sources() []decodedSource {
+	var result []decodedSource
+	recent := make(map[int]map[string]*Decoded)
+	var decoded []decodedInstruction
+
+	for _, ci := range p.ClassifiedInstructions {
+		if ci.ProgramId != p.program.ProgramId {
+			continue
+		}
+		data := p.Adapter.GetInstructionData(ci.Instruction)
+		idx := formatIdx(ci.OuterIndex, ci.InnerIndex)
+
+		if bytes.HasPrefix(data, EventIxTag) {
+			if ev, err := p.program.IDL.DecodeEvent(data); err == nil {
+				result = append(result, decodedSource{event: ev, idx: idx})
+				result[len(result)-1].pairWith(recent[ci.OuterIndex])
+			}
+			continue
+		}
+
+		ix, err := p.program.IDL.DecodeInstruction(data, p.Adapter.GetInstructionAccounts(ci.Instruction))
+		if err != nil {
+			continue
+		}
+		if recent[ci.OuterIndex] == nil {
+			recent[ci.OuterIndex] = make(map[string]*Decoded)
+		}
+		recent[ci.OuterIndex][ix.Name] = ix
+		decoded = append(decoded, decodedInstruction{outer: ci.OuterIndex, inner: ci.InnerIndex, ix: ix})
+		result = append(result, decodedSource{instruction: ix, idx: idx})
+	}
+
+	for _, event := range p.Adapter.GetLogEvents(p.program.ProgramId) {
+		if event.Type != types.LogEventProgramData {
+			continue
+		}
+		ev, err := p.program.IDL.DecodeEvent(event.Data)
+		if err != nil {
+			continue
+		}
+		// pair with the emitting instruction and the ones before it in the same outer instruction
+		paired := make(map[string]*Decoded)
+		for _, d := range decoded {
+			if d.outer == event.OuterIndex && d.inner <= event.InnerIndex {
+				paired[d.ix.Name] = d.ix
+			}
+		}
+		result = append(result, decodedSource{event: ev, paired: paired, idx: formatIdx(event.OuterIndex, event.InnerIndex)})
+	}
+	return result
+}
+
+// decodedInstruction is a decoded instruction with its position in the transaction
+type decodedInstruction struct {
+	outer int
+	inner int
+	ix    *Decoded
+}
+
+// formatIdx formats an instruction position the way trade parsers do, with outer instructions as "n-0"
+func formatIdx(outer, inner int) string {
+	if inner < 0 {
+		inner = 0
+	}
+	return utils.FormatIdx(outer, inner)
+}
+
+// pairWith snapshots the instructions decoded so far in the same outer instruction
+func (s *decodedSource) pairWith(instructions map[string]*Decoded) {
+	s.paired = make(map[string]*Decoded, len(instructions))
+	for name, ix := range instructions {
+		s.paired[name] = ix
+	}
+}
+
+// matches selects the source for a mapping and returns it with the paired instruction filled in
+func (s decodedSource) matches(event, instruction string) (decodedSource, bool) {
+	if event != "" {
+		if s.event == nil || s.event.Name != event {
+			return s, false
+		}
+		if instruction != "" {
+			s.instruction = s.paired[instruction]
+		}
+		return s, true
+	}
+	return s, s.instruction != nil && s.instruction.Name == instruction
+}
+
+// ProcessTrades builds trades from the mapped instructions and events
+func (p *programParser) ProcessTrades() []types.TradeInfo {
+	var trades []types.TradeInfo
+	for _, src := range p.sources() {
+		for i := range p.program.Mapping.Trades {
+			m := &p.program.Mapping.Trades[i]
+			s, ok := src.matches(m.Event, m.Instruction)
+			if !ok {
+				continue
+			}
+			if trade := p.buildTrade(m, s); trade != nil {
+				trades = append(trades, *trade)
+			}
+		}
+	}
+	return trades
+}
+
+// ProcessLiquidity builds pool events from the mapped instructions and events
+func (p *programParser) ProcessLiquidity() []types.PoolEvent {
+	var events []types.PoolEvent
+	for _, src := range p.sources() {
+		for i := range p.program.Mapping.PoolEvents {
+			m := &p.program.Mapping.PoolEvents[i]
+			s, ok := src.matches(m.Event, m.Instruction)
+			if !ok {
+				continue
+			}
+			if event := p.buildPoolEvent(m, s); event != nil {
+				events = append(events, *event)
+			}
+		}
+	}
+	return events
+}
+
+func (p *programParser) buildTrade(m *TradeMapping, s decodedSource) *types.TradeInfo {
+	tradeType := m.Type
+	if m.IsBuy != "" {
+		isBuy, ok := s.value(m.IsBuy).(bool)
+		if !ok {
+			return nil
+		}
+		tradeType = types.TradeTypeSell
+		if isBuy {
+			tradeType = types.TradeTypeBuy
+		}
+	}
+	if tradeType != types.TradeTypeBuy && tradeType != types.TradeTypeSell {
+		return nil
+	}
+
+	baseMint := s.str(m.BaseMint)
+	quoteMint := constants.TOKENS.SOL
+	if m.QuoteMint != "" {
+		quoteMint = s.str(m.QuoteMint)
+	}
+	baseAmount, quoteAmount := s.amount(m.BaseAmount), s.amount(m.QuoteAmount)
+	if baseMint == "" || quoteMint == "" || baseAmount == nil || quoteAmount == nil {
+		return nil
+	}
+
+	base := p.tokenInfo(baseMint, baseAmount, s.decimals(m.BaseDecimals))
+	quote := p.tokenInfo(quoteMint, quoteAmount, s.decimals(m.QuoteDecimals))
+	input, output := quote, base
+	if tradeType == types.TradeTypeSell {
+		input, output = base, quote
+	}
+
+	user := s.str(m.User)
+	if user == "" {
+		user = p.Adapter.Signer()
+	}
+	programId := p.DexInfo.ProgramId
+	if programId == "" {
+		programId = p.program.ProgramId
+	}
+	amm := p.DexInfo.AMM
+	if amm == "" {
+		amm = p.program.AMM
+	}
+
+	trade := &types.TradeInfo{
+		Type:        tradeType,
+		InputToken:  input,
+		OutputToken: output,
+		User:        user,
+		ProgramId:   programId,
+		AMM:         amm,
+		Route:       p.DexInfo.Route,
+		Slot:        p.Adapter.Slot(),
+		Timestamp:   p.Adapter.BlockTime(),
+		Signature:   p.Adapter.Signature(),
+		Idx:         s.idx,
+	}
+	if pool := s.str(m.Pool); pool != "" {
+		trade.Pool = []string{pool}
+	}
+	if fee := s.amount(m.Fee); fee != nil {
+		trade.Fee = &types.FeeInfo{
+			Mint:      quote.Mint,
+			Amount:    types.ConvertToUIAmount(fee, quote.Decimals),
+			AmountRaw: fee.String(),
+			Decimals:  quote.Decimals,
+			Dex:       amm,
+		}
+	}
+
+	return p.Utils.AttachTokenTransferInfo(trade, p.TransferActions)
+}
+
+func (p *programParser) buildPoolEvent(m *PoolEventMapping, s decodedSource) *types.PoolEvent {
+	pool := s.str(m.Pool)
+	if pool == "" {
+		return nil
+	}
+
+	event := &types.PoolEvent{
+		PoolEventBase: p.Adapter.GetPoolEventBase(m.Type, p.program.ProgramId),
+		PoolId:        pool,
+		Config:        s.str(m.Config),
+		PoolLpMint:    s.str(m.LpMint),
+		Token0Mint:    s.str(m.Token0Mint),
+		Token1Mint:    s.str(m.Token1Mint),
+	}
+	event.AMM = p.program.AMM
+	event.Idx = s.idx
+	if user := s.str(m.User); user != "" {
+		event.User = user
+	}
+
+	if amount := s.amount(m.Token0Amount); amount != nil {
+		decimals := p.Adapter.GetTokenDecimals(event.Token0Mint)
+		event.Token0AmountRaw = amount.String()
+		event.Token0Amount = utils.Ptr(types.ConvertToUIAmount(amount, decimals))
+		event.Token0Decimals = utils.Ptr(decimals)
+	}
+	if amount := s.amount(m.Token1Amount); amount != nil {
+		decimals := p.Adapter.GetTokenDecimals(event.Token1Mint)
+		event.Token1AmountRaw = amount.String()
+		event.Token1Amount = utils.Ptr(types.ConvertToUIAmount(amount, decimals))
+		event.Token1Decimals = utils.Ptr(decimals)
+	}
+	if amount := s.amount(m.LpAmount); amount != nil {
+		decimals := p.Adapter.GetTokenDecimals(event.PoolLpMint)
+		event.LpAmountRaw = amount.String()
+		event.LpAmount = utils.Ptr(types.ConvertToUIAmount(amount, decimals))
+	}
+	return event
+}
+
+// tokenInfo builds token info, taking decimals from the transaction when the mapping has none
+func (p *programParser) tokenInfo(mint string, amount *big.Int, decimals *uint8) types.TokenInfo {
+	d := p.Adapter.GetTokenDecimals(mint)
+	if decimals != nil {
+		d = *decimals
+	}
+	return types.TokenInfo{
+		Mint:      mint,
+		Amount:    types.ConvertToUIAmount(amount, d),
+		AmountRaw: amount.String(),
+		Decimals:  d,
+	}
+}
+
+// value resolves a field reference against the source
+func (s decodedSource) value(ref string) any {
+	if ref == "" {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(ref, "accounts."); ok {
+		if s.instruction == nil {
+			return nil
+		}
+		if account, ok := s.instruction.Accounts[name]; ok {
+			return account
+		}
+		return nil
+	}
+
+	values := map[string]any(nil)
+	if path, ok := strings.CutPrefix(ref, "args."); ok {
+		ref = path
+		if s.instruction != nil {
+			values = s.instruction.Values
+		}
+	} else if s.event != nil {
+		values = s.event.Values
+	} else if s.instruction != nil {
+		values = s.instruction.Values
+	}
+
+	var current any = values
+	for _, part := range strings.Split(ref, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[part]
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			current = v[i]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func (s decodedSource) str(ref string) string {
+	v, _ := s.value(ref).(string)
+	return v
+}
+
+func (s decodedSource) amount(ref string) *big.Int {
+	n, ok := toBigInt(s.value(ref))
+	if !ok {
+		return nil
+	}
+	return n
+}
+
+func (s decodedSource) decimals(ref string) *uint8 {
+	n := s.amount(ref)
+	if n == nil || !n.IsUint64() || n.Uint64() > 255 {
+		return nil
+	}
+	return utils.Ptr(uint8(n.Uint64()))
+}
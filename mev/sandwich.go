@@ -0,0 +1,271 @@
+// Package mev detects MEV patterns in ordered trades, such as sandwich attacks within a block.
+//
+// Detection works on the TradeInfo values of parsed transactions together with their position
+// in the block, either on a whole types.BlockResult or incrementally on a sliding window.
+package mev
+
+import (
+	"math"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// Config configures sandwich detection; nil means defaults
+type Config struct {
+	// LinkedSigners groups signers controlled by the same attacker, whose front- and back-runs
+	// may come from different wallets
+	LinkedSigners [][]string
+
+	// Window is the number of recent trades kept to match back-runs against (default 256)
+	Window int
+
+	// AmountTolerance is the allowed relative difference between the base amounts of the
+	// front-run and the back-run (default 0.1)
+	AmountTolerance float64
+}
+
+const (
+	defaultWindow          = 256
+	defaultAmountTolerance = 0.1
+)
+
+// TradeRef identifies a trade and its quote-normalized amounts
+type TradeRef struct {
+	Slot        uint64          `json:"slot"`
+	Position    int             `json:"position"` // position of the transaction in the block
+	Signature   string          `json:"signature"`
+	Idx         string          `json:"idx"`
+	Signer      string          `json:"signer"`
+	Side        types.TradeType `json:"side"`        // BUY or SELL of the base token
+	BaseAmount  float64         `json:"baseAmount"`  // UI amount
+	QuoteAmount float64         `json:"quoteAmount"` // UI amount
+}
+
+// Victim is a trade executed between a front-run and a back-run
+type Victim struct {
+	TradeRef
+	// Loss is the extra quote paid on a buy, or quote missed on a sell, estimated at the
+	// front-run's average price
+	Loss float64 `json:"loss"`
+}
+
+// Sandwich is a front-run and a back-run by the same attacker around victim trades on one pool
+type Sandwich struct {
+	Slot      uint64 `json:"slot"`
+	Pool      string `json:"pool"`
+	AMM       string `json:"amm,omitempty"`
+	BaseMint  string `json:"baseMint"`
+	QuoteMint string `json:"quoteMint"`
+	Attacker  string `json:"attacker"` // signer of the front-run
+
+	FrontRun TradeRef `json:"frontRun"`
+	BackRun  TradeRef `json:"backRun"`
+	Victims  []Victim `json:"victims"`
+
+	// VictimLoss is the total loss of the victims in the quote token
+	VictimLoss float64 `json:"victimLoss"`
+
+	// AttackerProfit is the quote received minus the quote spent over the front- and back-run,
+	// before transaction fees and tips
+	AttackerProfit float64 `json:"attackerProfit"`
+}
+
+// windowTrade is a trade in the detection window
+type windowTrade struct {
+	TradeRef
+	pool      string
+	amm       string
+	baseMint  string
+	quoteMint string
+	group     string
+	used      bool // already part of a reported sandwich as front- or back-run
+}
+
+// Detector finds sandwiches in results added in block order. It is not safe for concurrent use.
+type Detector struct {
+	window    int
+	tolerance float64
+	groups    map[string]string
+	slot      uint64
+	trades    []*windowTrade
+}
+
+// NewDetector creates a Detector
+func NewDetector(cfg *Config) *Detector {
+	d := &Detector{
+		window:    defaultWindow,
+		tolerance: defaultAmountTolerance,
+		groups:    make(map[string]string),
+	}
+	if cfg == nil {
+		return d
+	}
+	if cfg.Window > 0 {
+		d.window = cfg.Window
+	}
+	if cfg.AmountTolerance > 0 {
+		d.tolerance = cfg.AmountTolerance
+	}
+	for _, group := range cfg.LinkedSigners {
+		if len(group) == 0 {
+			continue
+		}
+		for _, signer := range group {
+			d.groups[signer] = group[0]
+		}
+	}
+	return d
+}
+
+// DetectBlock returns the sandwiches in a parsed block
+func DetectBlock(block *types.BlockResult, cfg *Config) []Sandwich {
+	if block == nil {
+		return nil
+	}
+	d := NewDetector(cfg)
+	var sandwiches []Sandwich
+	for _, tx := range block.Transactions {
+		sandwiches = append(sandwiches, d.Add(tx.Index, tx.Result)...)
+	}
+	return sandwiches
+}
+
+// Add adds the trades of a transaction at the given position in its slot and returns the
+// sandwiches it completes as a back-run. Results must be added in block order; the window is
+// cleared when the slot changes. Failed and skipped transactions are ignored.
+func (d *Detector) Add(position int, result *types.ParseResult) []Sandwich {
+	if result == nil || result.Skipped || result.TxStatus == types.TransactionStatusFailed {
+		return nil
+	}
+	if result.Slot != d.slot {
+		d.slot = result.Slot
+		d.trades = d.trades[:0]
+	}
+
+	signer := ""
+	if len(result.Signer) > 0 {
+		signer = result.Signer[0]
+	}
+	var sandwiches []Sandwich
+	for _, trade := range result.Trades {
+		wt := d.newWindowTrade(position, result, signer, trade)
+		if wt == nil {
+			continue
+		}
+		if sandwich, ok := d.match(wt); ok {
+			sandwiches = append(sandwiches, sandwich)
+		}
+		d.trades = append(d.trades, wt)
+	}
+	if excess := len(d.trades) - d.window; excess > 0 {
+		d.trades = append(d.trades[:0], d.trades[excess:]...)
+	}
+	return sandwiches
+}
+
+// newWindowTrade normalizes a trade against its quote token, or returns nil if it has none
+func (d *Detector) newWindowTrade(position int, result *types.ParseResult, signer string, trade types.TradeInfo) *windowTrade {
+	base, quote, buy, ok := utils.OrientTrade(trade)
+	if !ok || base.Amount <= 0 || quote.Amount <= 0 {
+		return nil
+	}
+	side := types.TradeTypeSell
+	if buy {
+		side = types.TradeTypeBuy
+	}
+
+	user := trade.User
+	if user == "" {
+		user = signer
+	}
+	group := user
+	if linked, ok := d.groups[user]; ok {
+		group = linked
+	}
+	pool := trade.ProgramId + ":" + base.Mint
+	if len(trade.Pool) > 0 && trade.Pool[0] != "" {
+		pool = trade.Pool[0]
+	}
+
+	return &windowTrade{
+		TradeRef: TradeRef{
+			Slot:        result.Slot,
+			Position:    position,
+			Signature:   result.Signature,
+			Idx:         trade.Idx,
+			Signer:      user,
+			Side:        side,
+			BaseAmount:  base.Amount,
+			QuoteAmount: quote.Amount,
+		},
+		pool:      pool,
+		amm:       trade.AMM,
+		baseMint:  base.Mint,
+		quoteMint: quote.Mint,
+		group:     group,
+	}
+}
+
+// match looks for the latest unused front-run that back completes, with victims in between
+func (d *Detector) match(back *windowTrade) (Sandwich, bool) {
+	for i := len(d.trades) - 1; i >= 0; i-- {
+		front := d.trades[i]
+		if front.used || front.group != back.group || front.pool != back.pool || front.Side == back.Side ||
+			front.Position >= back.Position || !d.amountsMatch(front.BaseAmount, back.BaseAmount) {
+			continue
+		}
+
+		var victims []Victim
+		for _, v := range d.trades[i+1:] {
+			if v.pool != front.pool || v.Side != front.Side || v.group == front.group ||
+				v.Position <= front.Position || v.Position >= back.Position {
+				continue
+			}
+			victims = append(victims, Victim{TradeRef: v.TradeRef, Loss: victimLoss(front, v)})
+		}
+		if len(victims) == 0 {
+			continue
+		}
+
+		front.used, back.used = true, true
+		sandwich := Sandwich{
+			Slot:      back.Slot,
+			Pool:      front.pool,
+			AMM:       front.amm,
+			BaseMint:  front.baseMint,
+			QuoteMint: front.quoteMint,
+			Attacker:  front.Signer,
+			FrontRun:  front.TradeRef,
+			BackRun:   back.TradeRef,
+			Victims:   victims,
+		}
+		for _, v := range victims {
+			sandwich.VictimLoss += v.Loss
+		}
+		if front.Side == types.TradeTypeBuy {
+			sandwich.AttackerProfit = back.QuoteAmount - front.QuoteAmount
+		} else {
+			sandwich.AttackerProfit = front.QuoteAmount - back.QuoteAmount
+		}
+		return sandwich, true
+	}
+	return Sandwich{}, false
+}
+
+func (d *Detector) amountsMatch(front, back float64) bool {
+	return math.Abs(front-back) <= d.tolerance*math.Max(front, back)
+}
+
+// victimLoss estimates the victim's loss at the front-run's average price, which the victim
+// would have approached without the front-run moving the pool first
+func victimLoss(front, victim *windowTrade) float64 {
+	price := front.QuoteAmount / front.BaseAmount
+	var loss float64
+	if victim.Side == types.TradeTypeBuy {
+		loss = victim.QuoteAmount - victim.BaseAmount*price
+	} else {
+		loss = victim.BaseAmount*price - victim.QuoteAmount
+	}
+	return math.Max(loss, 0)
+}
@@ -81,6 +81,17 @@ func (bp *BaseParser) GetTransfersForInstruction(programId string, outerIndex in
 	return result
 }
 
+// GetEventsForInstruction returns the log events a program emitted while executing the given instruction
+func (bp *BaseParser) GetEventsForInstruction(programId string, outerIndex int, innerIndex int) []types.ClassifiedEvent {
+	var result []types.ClassifiedEvent
+	for _, event := range bp.Adapter.GetLogEvents(programId) {
+		if event.OuterIndex == outerIndex && event.InnerIndex == innerIndex {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
 // GetInstructionData returns instruction data
 func (bp *BaseParser) GetInstructionData(instruction interface{}) []byte {
 	return bp.Adapter.GetInstructionData(instruction)
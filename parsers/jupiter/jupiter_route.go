@@ -0,0 +1,128 @@
+package jupiter
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// routeLayout describes where a Jupiter V6 route instruction keeps its user and mints, and which
+// amounts end its arguments
+type routeLayout struct {
+	discriminator       []byte
+	user                int  // account index of the user transfer authority
+	source, destination int  // account indexes of the source and destination mints
+	sourceTokenAccount  bool // source is the user's token account instead of the mint
+	tokenLedger         bool // the input amount is read from a token ledger, not an argument
+	exactOut            bool // the amounts are the output amount and the quoted input amount
+}
+
+var routeLayouts = []routeLayout{
+	{discriminator: constants.DISCRIMINATORS.JUPITER.ROUTE, user: 1, source: 2, destination: 5, sourceTokenAccount: true},
+	{discriminator: constants.DISCRIMINATORS.JUPITER.ROUTE_WITH_TOKEN_LEDGER, user: 1, source: 2, destination: 5, sourceTokenAccount: true, tokenLedger: true},
+	{discriminator: constants.DISCRIMINATORS.JUPITER.ROUTE_EXACT_OUT, user: 1, source: 5, destination: 6, exactOut: true},
+	{discriminator: constants.DISCRIMINATORS.JUPITER.SHARE_ACCOUNTS_ROUTE, user: 2, source: 7, destination: 8},
+	{discriminator: constants.DISCRIMINATORS.JUPITER.SHARE_ACCOUNTS_ROUTE_WITH_TOKEN_LEDGER, user: 2, source: 7, destination: 8, tokenLedger: true},
+	{discriminator: constants.DISCRIMINATORS.JUPITER.SHARE_ACCOUNTS_EXACT_OUT_ROUTE, user: 2, source: 7, destination: 8, exactOut: true},
+}
+
+// RouteTrades returns the swaps requested by the outer Jupiter V6 route instructions, with the
+// amounts from the instruction arguments: the quoted output, or the quoted input for exact-out
+// routes. Unlike ProcessTrades it needs no inner instructions, so it also covers failed
+// transactions.
+func RouteTrades(adapter *adapter.TransactionAdapter, instructions []types.ClassifiedInstruction) []types.TradeInfo {
+	var trades []types.TradeInfo
+	for _, ci := range instructions {
+		if ci.InnerIndex >= 0 {
+			continue
+		}
+		data := adapter.GetInstructionData(ci.Instruction)
+		if len(data) < 8 {
+			continue
+		}
+		for _, layout := range routeLayouts {
+			if !bytes.Equal(data[:8], layout.discriminator) {
+				continue
+			}
+			if trade := decodeRouteTrade(adapter, ci, data[8:], layout); trade != nil {
+				trades = append(trades, *trade)
+			}
+			break
+		}
+	}
+	return trades
+}
+
+func decodeRouteTrade(adapter *adapter.TransactionAdapter, ci types.ClassifiedInstruction, args []byte, layout routeLayout) *types.TradeInfo {
+	accounts := adapter.GetInstructionAccounts(ci.Instruction)
+	if len(accounts) <= max(layout.user, layout.source, layout.destination) {
+		return nil
+	}
+	inputMint, outputMint := accounts[layout.source], accounts[layout.destination]
+	if layout.sourceTokenAccount {
+		inputMint = adapter.GetSplTokenMint(inputMint)
+	}
+	if inputMint == "" || outputMint == "" {
+		return nil
+	}
+
+	// The arguments end with the amounts, slippage_bps (u16) and platform_fee_bps (u8)
+	count := 2
+	if layout.tokenLedger {
+		count = 1
+	}
+	size := count*8 + 3
+	if len(args) < size {
+		return nil
+	}
+	reader := utils.GetBinaryReader(args[len(args)-size:])
+	defer reader.Release()
+	amounts := make([]uint64, count)
+	for i := range amounts {
+		amounts[i], _ = reader.ReadU64()
+	}
+	slippageBps, _ := reader.ReadU16()
+	if reader.HasError() {
+		return nil
+	}
+	var inputAmount, outputAmount uint64
+	switch {
+	case layout.tokenLedger:
+		outputAmount = amounts[0]
+	case layout.exactOut:
+		outputAmount, inputAmount = amounts[0], amounts[1]
+	default:
+		inputAmount, outputAmount = amounts[0], amounts[1]
+	}
+
+	tokenInfo := func(mint string, amount uint64) types.TokenInfo {
+		decimals := adapter.GetTokenDecimals(mint)
+		return types.TokenInfo{
+			Mint:      mint,
+			Amount:    types.ConvertToUIAmountUint64(amount, decimals),
+			AmountRaw: strconv.FormatUint(amount, 10),
+			Decimals:  decimals,
+		}
+	}
+	slippage := int(slippageBps)
+	return &types.TradeInfo{
+		Type:        utils.GetTradeType(inputMint, outputMint),
+		Pool:        []string{},
+		User:        accounts[layout.user],
+		InputToken:  tokenInfo(inputMint, inputAmount),
+		OutputToken: tokenInfo(outputMint, outputAmount),
+		ProgramId:   constants.DEX_PROGRAMS.JUPITER.ID,
+		AMM:         constants.DEX_PROGRAMS.JUPITER.Name,
+		AMMs:        []string{},
+		Route:       constants.DEX_PROGRAMS.JUPITER.Name,
+		SlippageBps: &slippage,
+		Slot:        adapter.Slot(),
+		Timestamp:   adapter.BlockTime(),
+		Signature:   adapter.Signature(),
+		Idx:         utils.FormatIdx(ci.OuterIndex, ci.InnerIndex),
+	}
+}
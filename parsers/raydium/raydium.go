@@ -44,6 +44,9 @@ func (p *RaydiumParser) ProcessTrades() []types.TradeInfo {
 				trade := p.Utils.ProcessSwapData(transfers[:2], dexInfo, false)
 
 				if trade != nil {
+					if ci.ProgramId == constants.DEX_PROGRAMS.RAYDIUM_V4.ID {
+						p.applyRayLog(trade, ci)
+					}
 					pool := p.getPoolAddress(ci.Instruction, ci.ProgramId)
 					if pool != "" {
 						trade.Pool = []string{pool}
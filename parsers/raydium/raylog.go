@@ -0,0 +1,95 @@
+package raydium
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// RayLogType is the first byte of a Raydium AMM v4 ray_log payload
+type RayLogType uint8
+
+const (
+	RayLogInit        RayLogType = 0
+	RayLogDeposit     RayLogType = 1
+	RayLogWithdraw    RayLogType = 2
+	RayLogSwapBaseIn  RayLogType = 3
+	RayLogSwapBaseOut RayLogType = 4
+)
+
+// rayLogSwapSize is the encoded size of both swap logs: type byte and seven u64 fields
+const rayLogSwapSize = 1 + 7*8
+
+// RaySwapLog is a decoded SwapBaseIn or SwapBaseOut ray_log.
+// For SwapBaseIn AmountIn is exact and AmountOut is the realized output;
+// for SwapBaseOut AmountOut is exact and AmountIn is the deducted input.
+type RaySwapLog struct {
+	Type       RayLogType
+	AmountIn   uint64
+	AmountOut  uint64
+	Limit      uint64 // minimum_out for SwapBaseIn, max_in for SwapBaseOut
+	Direction  uint64 // 1: coin to pc, 2: pc to coin
+	UserSource uint64 // user source token balance before the swap
+	PoolCoin   uint64
+	PoolPc     uint64
+}
+
+// DecodeRaySwapLog decodes the payload of a "Program log: ray_log:" swap line
+func DecodeRaySwapLog(data []byte) (*RaySwapLog, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty ray_log")
+	}
+	logType := RayLogType(data[0])
+	if logType != RayLogSwapBaseIn && logType != RayLogSwapBaseOut {
+		return nil, fmt.Errorf("ray_log type %d is not a swap", logType)
+	}
+	if len(data) < rayLogSwapSize {
+		return nil, fmt.Errorf("ray_log swap too short: %d bytes", len(data))
+	}
+
+	reader := utils.GetBinaryReader(data[1:])
+	defer reader.Release()
+
+	fields := make([]uint64, 7)
+	for i := range fields {
+		fields[i], _ = reader.ReadU64()
+	}
+
+	// SwapBaseIn:  amount_in, minimum_out, direction, user_source, pool_coin, pool_pc, out_amount
+	// SwapBaseOut: max_in, amount_out, direction, user_source, pool_coin, pool_pc, deduct_in
+	log := &RaySwapLog{
+		Type:       logType,
+		Direction:  fields[2],
+		UserSource: fields[3],
+		PoolCoin:   fields[4],
+		PoolPc:     fields[5],
+	}
+	if logType == RayLogSwapBaseIn {
+		log.AmountIn, log.Limit, log.AmountOut = fields[0], fields[1], fields[6]
+	} else {
+		log.Limit, log.AmountOut, log.AmountIn = fields[0], fields[1], fields[6]
+	}
+	return log, nil
+}
+
+// applyRayLog replaces the transfer amounts of a Raydium V4 trade with the exact amounts of the
+// ray_log swap line the instruction logged, if any. Mints and decimals still come from the transfers.
+func (p *RaydiumParser) applyRayLog(trade *types.TradeInfo, ci types.ClassifiedInstruction) {
+	for _, event := range p.GetEventsForInstruction(ci.ProgramId, ci.OuterIndex, ci.InnerIndex) {
+		if event.Type != types.LogEventRayLog {
+			continue
+		}
+		swap, err := DecodeRaySwapLog(event.Data)
+		if err != nil {
+			continue
+		}
+		trade.InputToken.AmountRaw = strconv.FormatUint(swap.AmountIn, 10)
+		trade.InputToken.Amount = types.ConvertToUIAmountUint64(swap.AmountIn, trade.InputToken.Decimals)
+		trade.OutputToken.AmountRaw = strconv.FormatUint(swap.AmountOut, 10)
+		trade.OutputToken.Amount = types.ConvertToUIAmountUint64(swap.AmountOut, trade.OutputToken.Decimals)
+		return
+	}
+}
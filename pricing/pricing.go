@@ -0,0 +1,276 @@
+// Package pricing values trades in USD from reference prices derived from the parsed trades
+// themselves, without external price feeds.
+//
+// An Engine keeps a rolling SOL/USD reference from SOL/stablecoin trades and the latest quote
+// price of every token traded against SOL or a stablecoin. Prices only depend on the trades fed
+// in and their timestamps, so results are deterministic and reproducible offline.
+package pricing
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// Config configures an Engine
+type Config struct {
+	// Staleness is how far apart, by trade timestamp, a price and the trade it values may be
+	// (0 means prices never go stale)
+	Staleness time.Duration
+
+	// Window is the number of recent SOL/USD observations the reference is the median of
+	// (at least 1)
+	Window int
+
+	// MaxDeviation is the relative deviation from the current price above which an observation
+	// is rejected as an outlier, for the SOL/USD reference and token prices alike (0 disables
+	// outlier rejection). After a larger move the price follows once minSamples consecutive
+	// rejected observations agree with each other.
+	MaxDeviation float64
+}
+
+// DefaultConfig returns the default engine configuration
+func DefaultConfig() Config {
+	return Config{
+		Staleness:    5 * time.Minute,
+		Window:       32,
+		MaxDeviation: 0.1,
+	}
+}
+
+// minSamples is the number of fresh SOL/USD observations needed before outliers are rejected,
+// and the number of consecutive agreeing outliers that replace a price
+const minSamples = 3
+
+// observation is a price seen in a trade
+type observation struct {
+	price     float64
+	timestamp int64
+}
+
+// quotePrice is the latest price of a token in a quote token
+type quotePrice struct {
+	quoteMint string
+	price     float64
+	timestamp int64
+	rejected  []observation // consecutive outliers in quoteMint
+}
+
+// Engine maintains reference prices from observed trades and values trades with them.
+// It is safe for concurrent use; feed trades in timestamp order for reproducible prices.
+type Engine struct {
+	config Config
+
+	mu          sync.Mutex
+	sol         []observation
+	solRejected []observation // consecutive SOL/USD outliers
+	tokens      map[string]quotePrice
+}
+
+// NewEngine creates an Engine
+func NewEngine(config Config) *Engine {
+	if config.Window <= 0 {
+		config.Window = 1
+	}
+	return &Engine{
+		config: config,
+		tokens: make(map[string]quotePrice),
+	}
+}
+
+// Observe updates the reference prices from a trade against SOL or a stablecoin
+func (e *Engine) Observe(trade types.TradeInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observe(trade)
+}
+
+// Apply observes a trade and sets its PriceQuote, PriceUSD and VolumeUSD. PriceQuote and
+// PriceUSD are set for trades against SOL or a stablecoin; other trades only get a VolumeUSD if
+// one side has a fresh USD price.
+func (e *Engine) Apply(trade *types.TradeInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observe(*trade)
+	e.value(trade)
+}
+
+// ApplyResult applies the trades of a successful result in order and values its aggregate trade
+func (e *Engine) ApplyResult(result *types.ParseResult) {
+	if result == nil || result.TxStatus == types.TransactionStatusFailed {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range result.Trades {
+		e.observe(result.Trades[i])
+		e.value(&result.Trades[i])
+	}
+	if result.AggregateTrade != nil {
+		e.value(result.AggregateTrade)
+	}
+}
+
+// ApplyBlock applies the results of a block in block order
+func (e *Engine) ApplyBlock(block *types.BlockResult) {
+	if block == nil {
+		return
+	}
+	for _, tx := range block.Transactions {
+		e.ApplyResult(tx.Result)
+	}
+}
+
+// SOLPrice returns the SOL/USD reference at timestamp, the median of the fresh observations
+func (e *Engine) SOLPrice(timestamp int64) (float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.solPrice(timestamp)
+}
+
+// PriceUSD returns the USD price of mint at timestamp. USD stablecoins are worth 1, SOL is
+// worth the reference and other tokens are priced through their latest quote-token trade.
+func (e *Engine) PriceUSD(mint string, timestamp int64) (float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.priceUSD(mint, timestamp)
+}
+
+func (e *Engine) observe(trade types.TradeInfo) {
+	base, quote, _, ok := utils.OrientTrade(trade)
+	if !ok || base.Amount <= 0 || quote.Amount <= 0 {
+		return
+	}
+	baseMint, quoteMint := base.Mint, quote.Mint
+	price := quote.Amount / base.Amount
+	o := observation{price: price, timestamp: trade.Timestamp}
+
+	switch {
+	case baseMint == constants.TOKENS.SOL && constants.IsUSDStablecoin(quoteMint):
+		if reference, ok := e.solPrice(trade.Timestamp); ok && e.freshCount(trade.Timestamp) >= minSamples && e.outlier(price, reference) {
+			if !e.reject(&e.solRejected, o) {
+				return
+			}
+			e.sol = append(e.sol[:0], e.solRejected...)
+			e.solRejected = e.solRejected[:0]
+			return
+		}
+		e.solRejected = e.solRejected[:0]
+		e.sol = append(e.sol, o)
+		if excess := len(e.sol) - e.config.Window; excess > 0 {
+			e.sol = append(e.sol[:0], e.sol[excess:]...)
+		}
+	default:
+		current, ok := e.tokens[baseMint]
+		if ok && current.quoteMint == quoteMint && e.fresh(current.timestamp, trade.Timestamp) && e.outlier(price, current.price) {
+			if e.reject(&current.rejected, o) {
+				latest := current.rejected[len(current.rejected)-1]
+				current = quotePrice{quoteMint: quoteMint, price: latest.price, timestamp: latest.timestamp}
+			}
+			e.tokens[baseMint] = current
+			return
+		}
+		e.tokens[baseMint] = quotePrice{quoteMint: quoteMint, price: price, timestamp: trade.Timestamp}
+	}
+}
+
+// outlier reports whether price deviates from reference by more than MaxDeviation
+func (e *Engine) outlier(price, reference float64) bool {
+	return e.config.MaxDeviation > 0 && math.Abs(price/reference-1) > e.config.MaxDeviation
+}
+
+// reject adds an outlier to a run of consecutive outliers and reports whether the run has
+// minSamples observations that agree with each other, i.e. the price has really moved.
+// An outlier that disagrees with the run starts a new one.
+func (e *Engine) reject(run *[]observation, o observation) bool {
+	if len(*run) > 0 && e.outlier(o.price, (*run)[0].price) {
+		*run = (*run)[:0]
+	}
+	*run = append(*run, o)
+	return len(*run) >= minSamples
+}
+
+func (e *Engine) value(trade *types.TradeInfo) {
+	base, quote, _, ok := utils.OrientTrade(*trade)
+	if !ok {
+		for _, token := range []types.TokenInfo{trade.InputToken, trade.OutputToken} {
+			if usd, ok := e.priceUSD(token.Mint, trade.Timestamp); ok {
+				trade.VolumeUSD = token.Amount * usd
+				return
+			}
+		}
+		return
+	}
+	if base.Amount <= 0 {
+		return
+	}
+	trade.PriceQuote = quote.Amount / base.Amount
+	if usd, ok := e.priceUSD(quote.Mint, trade.Timestamp); ok {
+		trade.PriceUSD = trade.PriceQuote * usd
+		trade.VolumeUSD = quote.Amount * usd
+	}
+}
+
+func (e *Engine) solPrice(timestamp int64) (float64, bool) {
+	prices := make([]float64, 0, len(e.sol))
+	for _, o := range e.sol {
+		if e.fresh(o.timestamp, timestamp) {
+			prices = append(prices, o.price)
+		}
+	}
+	if len(prices) == 0 {
+		return 0, false
+	}
+	sort.Float64s(prices)
+	middle := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return (prices[middle-1] + prices[middle]) / 2, true
+	}
+	return prices[middle], true
+}
+
+func (e *Engine) freshCount(timestamp int64) int {
+	count := 0
+	for _, o := range e.sol {
+		if e.fresh(o.timestamp, timestamp) {
+			count++
+		}
+	}
+	return count
+}
+
+// priceUSD follows quote prices towards USD; each step moves to a higher ranked quote token
+func (e *Engine) priceUSD(mint string, timestamp int64) (float64, bool) {
+	mint = constants.NormalizeMint(mint)
+	switch {
+	case constants.IsUSDStablecoin(mint):
+		return 1, true
+	case mint == constants.TOKENS.SOL:
+		return e.solPrice(timestamp)
+	}
+	quote, ok := e.tokens[mint]
+	if !ok || !e.fresh(quote.timestamp, timestamp) {
+		return 0, false
+	}
+	usd, ok := e.priceUSD(quote.quoteMint, timestamp)
+	if !ok {
+		return 0, false
+	}
+	return quote.price * usd, true
+}
+
+func (e *Engine) fresh(observed, timestamp int64) bool {
+	if e.config.Staleness == 0 {
+		return true
+	}
+	age := time.Duration(timestamp-observed) * time.Second
+	if age < 0 {
+		age = -age
+	}
+	return age <= e.config.Staleness
+}
@@ -0,0 +1,218 @@
+package dexparser
+
+import (
+	"sort"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// registry holds parser factories by program ID; a registry is never modified once published
+type registry struct {
+	trade     map[string]TradeParserFactory
+	liquidity map[string]LiquidityParserFactory
+	transfer  map[string]TransferParserFactory
+	meme      map[string]MemeEventParserFactory
+	errors    map[string]map[uint32]string // user error tables, consulted before constants.PROGRAM_ERRORS
+}
+
+// newRegistry creates an empty registry
+func newRegistry() *registry {
+	return &registry{
+		trade:     make(map[string]TradeParserFactory, 20),
+		liquidity: make(map[string]LiquidityParserFactory, 10),
+		transfer:  make(map[string]TransferParserFactory, 5),
+		meme:      make(map[string]MemeEventParserFactory, 10),
+		errors:    make(map[string]map[uint32]string),
+	}
+}
+
+// clone returns a copy of the registry that can be modified before publishing
+func (r *registry) clone() *registry {
+	c := newRegistry()
+	for k, v := range r.trade {
+		c.trade[k] = v
+	}
+	for k, v := range r.liquidity {
+		c.liquidity[k] = v
+	}
+	for k, v := range r.transfer {
+		c.transfer[k] = v
+	}
+	for k, v := range r.meme {
+		c.meme[k] = v
+	}
+	for k, v := range r.errors {
+		c.errors[k] = v
+	}
+	return c
+}
+
+// hasParser reports whether any parser factory is registered for programId
+func (r *registry) hasParser(programId string) bool {
+	if _, ok := r.trade[programId]; ok {
+		return true
+	}
+	if _, ok := r.liquidity[programId]; ok {
+		return true
+	}
+	if _, ok := r.meme[programId]; ok {
+		return true
+	}
+	_, ok := r.transfer[programId]
+	return ok
+}
+
+// remove deletes every parser registered for programId
+func (r *registry) remove(programId string) {
+	delete(r.trade, programId)
+	delete(r.liquidity, programId)
+	delete(r.transfer, programId)
+	delete(r.meme, programId)
+}
+
+// addErrors merges an error table for programId; tables are replaced rather than modified as clones share them
+func (r *registry) addErrors(programId string, table map[uint32]string) {
+	merged := make(map[uint32]string, len(r.errors[programId])+len(table))
+	for code, name := range r.errors[programId] {
+		merged[code] = name
+	}
+	for code, name := range table {
+		merged[code] = name
+	}
+	r.errors[programId] = merged
+}
+
+// resolveTxError names a custom program error from the registered error tables. Codes missing
+// from a table keep the name constants.GetProgramErrorName already gave them.
+func (r *registry) resolveTxError(txErr *types.TxError) {
+	if txErr == nil || txErr.Code == nil {
+		return
+	}
+	table, ok := r.errors[txErr.ProgramId]
+	if !ok {
+		return
+	}
+	if name, ok := table[*txErr.Code]; ok {
+		txErr.Name = name
+	} else if txErr.Name == "" {
+		txErr.Name = "Custom"
+	}
+}
+
+// update publishes a modified copy of the registry, so parses already running keep their snapshot
+func (dp *DexParser) update(fn func(r *registry)) {
+	for {
+		current := dp.registry.Load()
+		next := current.clone()
+		fn(next)
+		if dp.registry.CompareAndSwap(current, next) {
+			return
+		}
+	}
+}
+
+// parserOptions collects NewDexParser options
+type parserOptions struct {
+	withoutDefaults bool
+	registrations   []func(r *registry)
+}
+
+// Option configures a DexParser created by NewDexParser
+type Option func(o *parserOptions)
+
+// WithoutDefaultParsers starts from an empty registry instead of the built-in parsers
+func WithoutDefaultParsers() Option {
+	return func(o *parserOptions) {
+		o.withoutDefaults = true
+	}
+}
+
+// WithTradeParser registers a trade parser for a program ID, replacing any default one
+func WithTradeParser(programId string, factory TradeParserFactory) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.trade[programId] = factory })
+	}
+}
+
+// WithLiquidityParser registers a liquidity parser for a program ID, replacing any default one
+func WithLiquidityParser(programId string, factory LiquidityParserFactory) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.liquidity[programId] = factory })
+	}
+}
+
+// WithTransferParser registers a transfer parser for a program ID, replacing any default one
+func WithTransferParser(programId string, factory TransferParserFactory) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.transfer[programId] = factory })
+	}
+}
+
+// WithMemeParser registers a meme event parser for a program ID, replacing any default one
+func WithMemeParser(programId string, factory MemeEventParserFactory) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.meme[programId] = factory })
+	}
+}
+
+// WithoutProgram removes every parser registered for a program ID up to this option
+func WithoutProgram(programId string) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.remove(programId) })
+	}
+}
+
+// WithErrorTable registers custom error names for a program ID, overriding the built-in constants.PROGRAM_ERRORS.
+// Codes missing from the table keep their built-in or Anchor framework name.
+func WithErrorTable(programId string, table map[uint32]string) Option {
+	return func(o *parserOptions) {
+		o.registrations = append(o.registrations, func(r *registry) { r.addErrors(programId, table) })
+	}
+}
+
+// ProgramCapabilities reports which parser kinds are registered for a program ID
+type ProgramCapabilities struct {
+	ProgramId string `json:"programId"`
+	Name      string `json:"name"`
+	Trade     bool   `json:"trade"`
+	Liquidity bool   `json:"liquidity"`
+	Transfer  bool   `json:"transfer"`
+	MemeEvent bool   `json:"memeEvent"`
+}
+
+// RegisteredPrograms returns the capabilities of every registered program, sorted by program ID
+func (dp *DexParser) RegisteredPrograms() []ProgramCapabilities {
+	r := dp.registry.Load()
+
+	byProgram := make(map[string]*ProgramCapabilities)
+	get := func(programId string) *ProgramCapabilities {
+		caps, ok := byProgram[programId]
+		if !ok {
+			caps = &ProgramCapabilities{ProgramId: programId, Name: constants.GetProgramName(programId)}
+			byProgram[programId] = caps
+		}
+		return caps
+	}
+	for programId := range r.trade {
+		get(programId).Trade = true
+	}
+	for programId := range r.liquidity {
+		get(programId).Liquidity = true
+	}
+	for programId := range r.transfer {
+		get(programId).Transfer = true
+	}
+	for programId := range r.meme {
+		get(programId).MemeEvent = true
+	}
+
+	result := make([]ProgramCapabilities, 0, len(byProgram))
+	for _, caps := range byProgram {
+		result = append(result, *caps)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ProgramId < result[j].ProgramId
+	})
+	return result
+}
@@ -0,0 +1,135 @@
+package dexparser
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+// minEntrySize is num_hashes, hash and the transaction count of an entry without transactions
+const minEntrySize = 8 + 32 + 8
+
+var errEntriesTruncated = errors.New("entries: truncated data")
+
+// Entry is a ledger entry: a PoH tick when it has no transactions, or a batch of transactions
+type Entry struct {
+	NumHashes    uint64
+	Hash         string
+	Transactions []*adapter.SolanaTransaction
+}
+
+// DecodeEntries decodes a bincode-serialized Vec<Entry>, the payload of Jito ShredStream entries
+// and of deshredded slot data. Transactions are decoded from the wire format and carry the slot;
+// they have no meta, since entries are seen before execution.
+func DecodeEntries(data []byte, slot uint64) ([]Entry, error) {
+	count, rest, err := bincodeLength(data, minEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, count)
+	for i := range entries {
+		if len(rest) < minEntrySize {
+			return nil, fmt.Errorf("entry %d: %w", i, errEntriesTruncated)
+		}
+		entry := &entries[i]
+		entry.NumHashes = binary.LittleEndian.Uint64(rest)
+		entry.Hash = base58.Encode(rest[8:40])
+
+		var txCount int
+		txCount, rest, err = bincodeLength(rest[40:], 1)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		entry.Transactions = make([]*adapter.SolanaTransaction, txCount)
+		for j := range entry.Transactions {
+			tx, n, err := adapter.ReadWireTransaction(rest)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d transaction %d: %w", i, j, err)
+			}
+			tx.Slot = slot
+			entry.Transactions[j] = tx
+			rest = rest[n:]
+		}
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("entries: %d trailing bytes", len(rest))
+	}
+	return entries, nil
+}
+
+// bincodeLength reads a u64 length prefix, rejecting lengths the remaining data cannot hold
+// at minSize bytes per element
+func bincodeLength(data []byte, minSize int) (int, []byte, error) {
+	if len(data) < 8 {
+		return 0, nil, errEntriesTruncated
+	}
+	n := binary.LittleEndian.Uint64(data)
+	rest := data[8:]
+	if n > uint64(len(rest)/minSize) {
+		return 0, nil, fmt.Errorf("entries: length %d exceeds the %d remaining bytes", n, len(rest))
+	}
+	return int(n), rest, nil
+}
+
+// DecodeShredstreamEntry decodes a Jito ShredStream Entry message (slot = 1, entries = 2)
+// and returns its slot and the bincode entries payload
+func DecodeShredstreamEntry(data []byte) (uint64, []byte, error) {
+	var slot uint64
+	var entries []byte
+	err := decodeMessage(data, func(r *protoReader, num, wireType int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			if err = expect(num, wireType, wireVarint); err == nil {
+				slot, err = r.varint()
+			}
+		case 2:
+			if err = expect(num, wireType, wireBytes); err == nil {
+				entries, err = r.bytes()
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("shredstream Entry: %w", err)
+	}
+	return slot, entries, nil
+}
+
+// ParseEntries decodes a bincode Vec<Entry> of the given slot and parses every transaction with
+// ParseAll, returning the results in ledger order. Nothing is parsed if the entries are malformed.
+func (p *ShredParser) ParseEntries(data []byte, slot uint64, config *types.ParseConfig) ([]*types.ParseShredResult, error) {
+	return p.ParseEntriesContext(context.Background(), data, slot, config)
+}
+
+// ParseEntriesContext is ParseEntries passing ctx to fetcher calls; transactions left when ctx
+// is done are marked Skipped
+func (p *ShredParser) ParseEntriesContext(ctx context.Context, data []byte, slot uint64, config *types.ParseConfig) ([]*types.ParseShredResult, error) {
+	entries, err := DecodeEntries(data, slot)
+	if err != nil {
+		return nil, err
+	}
+	var results []*types.ParseShredResult
+	for _, entry := range entries {
+		for _, tx := range entry.Transactions {
+			results = append(results, p.ParseAllContext(ctx, tx, config))
+		}
+	}
+	return results, nil
+}
+
+// ParseShredstreamEntry parses a serialized Jito ShredStream Entry message, see ParseEntries
+func (p *ShredParser) ParseShredstreamEntry(data []byte, config *types.ParseConfig) ([]*types.ParseShredResult, error) {
+	slot, entries, err := DecodeShredstreamEntry(data)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseEntries(entries, slot, config)
+}
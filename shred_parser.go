@@ -1,47 +1,95 @@
 package dexparser
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"sync/atomic"
 
 	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
 	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
 	"github.com/DefaultPerson/solana-dex-parser-go/constants"
-	"github.com/DefaultPerson/solana-dex-parser-go/parsers/jupiter"
-	"github.com/DefaultPerson/solana-dex-parser-go/parsers/meteora"
-	"github.com/DefaultPerson/solana-dex-parser-go/parsers/photon"
-	"github.com/DefaultPerson/solana-dex-parser-go/parsers/raydium"
-	"github.com/DefaultPerson/solana-dex-parser-go/parsers/systoken"
 	"github.com/DefaultPerson/solana-dex-parser-go/types"
 	"github.com/DefaultPerson/solana-dex-parser-go/utils"
 )
 
 // ShredInstructionParser interface for instruction parsers
 type ShredInstructionParser interface {
+	// ProcessInstructions returns instructions in the legacy format
 	ProcessInstructions() []interface{}
+	// ProcessTypedInstructions returns typed ParsedShredInstruction results
+	ProcessTypedInstructions() []types.ParsedShredInstruction
 }
 
-// ShredParser parses Solana Shred transactions (pre-execution instruction analysis)
+// ShredParser parses Solana Shred transactions (pre-execution instruction analysis).
+// It is safe for concurrent use; each parse works on an immutable snapshot of the parser registry.
 type ShredParser struct {
+	registry atomic.Pointer[shredRegistry]
 }
 
-// NewShredParser creates a new ShredParser
-func NewShredParser() *ShredParser {
-	return &ShredParser{}
+// NewShredParser creates a new ShredParser with the default shred parsers and the given options
+func NewShredParser(opts ...ShredOption) *ShredParser {
+	o := &shredParserOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	r := make(shredRegistry, 16)
+	if !o.withoutDefaults {
+		registerDefaultShredParsers(r)
+	}
+	for _, apply := range o.registrations {
+		apply(r)
+	}
+
+	p := &ShredParser{}
+	p.registry.Store(&r)
+	return p
 }
 
 // ParseAll parses both trades and liquidity events from transaction
 func (p *ShredParser) ParseAll(tx *adapter.SolanaTransaction, config *types.ParseConfig) *types.ParseShredResult {
-	return p.parseWithClassifier(tx, config)
+	return p.parseWithClassifier(context.Background(), tx, config)
+}
+
+// ParseAllContext parses both trades and liquidity events from transaction, passing ctx to fetcher calls.
+// If ctx is already done the transaction is not parsed and the result is marked Skipped.
+func (p *ShredParser) ParseAllContext(ctx context.Context, tx *adapter.SolanaTransaction, config *types.ParseConfig) *types.ParseShredResult {
+	return p.parseWithClassifier(ctx, tx, config)
 }
 
 // parseWithClassifier parses transaction with specific type
-func (p *ShredParser) parseWithClassifier(tx *adapter.SolanaTransaction, config *types.ParseConfig) *types.ParseShredResult {
+func (p *ShredParser) parseWithClassifier(ctx context.Context, tx *adapter.SolanaTransaction, config *types.ParseConfig) (result *types.ParseShredResult) {
+	if tx == nil {
+		return &types.ParseShredResult{
+			State:              false,
+			Instructions:       make(map[string][]interface{}),
+			ParsedInstructions: make([]types.ParsedShredInstruction, 0),
+			Msg:                "Parse error: nil transaction",
+			Errors:             []*types.ParseError{types.NewParseError(types.ParseErrorMalformedInput, "", "", errNilTransaction)},
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		result := &types.ParseShredResult{
+			State:              false,
+			Skipped:            true,
+			Slot:               tx.Slot,
+			Instructions:       make(map[string][]interface{}),
+			ParsedInstructions: make([]types.ParsedShredInstruction, 0),
+			Msg:                fmt.Sprintf("skipped: %v", err),
+		}
+		if len(tx.Transaction.Signatures) > 0 {
+			result.Signature = tx.Transaction.Signatures[0]
+		}
+		return result
+	}
+
 	if config == nil {
 		config = &types.ParseConfig{TryUnknownDEX: true}
 	}
 
-	result := &types.ParseShredResult{
+	result = &types.ParseShredResult{
 		State:              true,
 		Signature:          "",
 		Instructions:       make(map[string][]interface{}),
@@ -59,10 +107,12 @@ func (p *ShredParser) parseWithClassifier(tx *adapter.SolanaTransaction, config
 			}
 			result.State = false
 			result.Msg = fmt.Sprintf("Parse error: %s %v", sig, r)
+			result.Errors = append(result.Errors, types.NewPanicError(types.ParseErrorDecoderPanic, "", "", r))
 		}
 	}()
 
-	txAdapter := adapter.NewTransactionAdapter(tx, config)
+	reg := *p.registry.Load()
+	txAdapter := adapter.NewTransactionAdapterContext(ctx, tx, config)
 	instructionClassifier := classifier.NewInstructionClassifier(txAdapter)
 
 	allProgramIds := instructionClassifier.GetAllProgramIds()
@@ -120,90 +170,40 @@ func (p *ShredParser) parseWithClassifier(tx *adapter.SolanaTransaction, config
 			}
 		}
 
-		var parser ShredInstructionParser
-		var programName string
-
-		switch programId {
-		case constants.DEX_PROGRAMS.PUMP_FUN.ID:
-			parser = NewPumpfunInstructionParser(txAdapter, instructionClassifier)
-			programName = utils.GetProgramName(programId)
-		case constants.DEX_PROGRAMS.PUMP_SWAP.ID:
-			parser = NewPumpswapInstructionParser(txAdapter, instructionClassifier)
-			programName = utils.GetProgramName(programId)
-		case constants.DEX_PROGRAMS.PHOTON.ID:
-			photonParser := photon.NewPhotonShredParser(txAdapter, instructionClassifier)
-			instructions := photonParser.ProcessInstructions()
-			if len(instructions) > 0 {
-				result.Instructions["Photon"] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, photonParser.ProcessTypedInstructions()...)
-			}
-			continue
-		case constants.SYSTEM_PROGRAM_ID:
-			sysParser := systoken.NewSystemTokenShredParser(txAdapter, instructionClassifier)
-			instructions := sysParser.ProcessNativeInstructions()
-			if len(instructions) > 0 {
-				result.Instructions["System"] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, sysParser.ProcessTypedNativeInstructions()...)
-			}
-			continue
-		case constants.TOKEN_PROGRAM_ID:
-			sysParser := systoken.NewSystemTokenShredParser(txAdapter, instructionClassifier)
-			instructions := sysParser.ProcessTokenInstructions()
-			if len(instructions) > 0 {
-				result.Instructions["Token"] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, sysParser.ProcessTypedTokenInstructions()...)
-			}
-			continue
-		case constants.TOKEN_2022_PROGRAM_ID:
-			sysParser := systoken.NewSystemTokenShredParser(txAdapter, instructionClassifier)
-			instructions := sysParser.ProcessToken2022Instructions()
-			if len(instructions) > 0 {
-				result.Instructions["Token2022"] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, sysParser.ProcessTypedToken2022Instructions()...)
-			}
-			continue
-		case constants.DEX_PROGRAMS.JUPITER.ID:
-			jupiterParser := jupiter.NewJupiterShredParser(txAdapter, instructionClassifier)
-			instructions := jupiterParser.ProcessInstructions()
-			if len(instructions) > 0 {
-				result.Instructions[constants.DEX_PROGRAMS.JUPITER.Name] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, jupiterParser.ProcessTypedInstructions()...)
-			}
-			continue
-		case constants.DEX_PROGRAMS.RAYDIUM_V4.ID:
-			raydiumParser := raydium.NewRaydiumV4ShredParser(txAdapter, instructionClassifier)
-			instructions := raydiumParser.ProcessInstructions()
-			if len(instructions) > 0 {
-				result.Instructions[constants.DEX_PROGRAMS.RAYDIUM_V4.Name] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, raydiumParser.ProcessTypedInstructions()...)
-			}
-			continue
-		case constants.DEX_PROGRAMS.RAYDIUM_LCP.ID:
-			lcpParser := raydium.NewLaunchpadShredParser(txAdapter, instructionClassifier)
-			instructions := lcpParser.ProcessInstructions()
-			if len(instructions) > 0 {
-				result.Instructions[constants.DEX_PROGRAMS.RAYDIUM_LCP.Name] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, lcpParser.ProcessTypedInstructions()...)
-			}
-			continue
-		case constants.DEX_PROGRAMS.METEORA_DBC.ID:
-			dbcParser := meteora.NewDBCShredParser(txAdapter, instructionClassifier)
-			instructions := dbcParser.ProcessInstructions()
-			if len(instructions) > 0 {
-				result.Instructions[constants.DEX_PROGRAMS.METEORA_DBC.Name] = instructions
-				result.ParsedInstructions = append(result.ParsedInstructions, dbcParser.ProcessTypedInstructions()...)
-			}
+		entry, ok := reg[programId]
+		if !ok {
 			continue
 		}
-
-		if parser != nil {
-			result.Instructions[programName] = parser.ProcessInstructions()
-		}
+		p.runProgramParser(result, config, programId, instructionClassifier, func() {
+			parser := entry.factory(txAdapter, instructionClassifier)
+			if instructions := parser.ProcessInstructions(); len(instructions) > 0 || entry.always {
+				result.Instructions[entry.name] = instructions
+			}
+			result.ParsedInstructions = append(result.ParsedInstructions, parser.ProcessTypedInstructions()...)
+		})
 	}
 
 	return result
 }
 
+// runProgramParser runs one program parser, recording a panic as a DECODER_PANIC error
+// so the instructions of other programs are kept
+func (p *ShredParser) runProgramParser(result *types.ParseShredResult, config *types.ParseConfig, programId string, instructionClassifier *classifier.InstructionClassifier, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if config.ThrowError {
+				panic(r)
+			}
+			idx := ""
+			if instructions := instructionClassifier.GetInstructions(programId); len(instructions) > 0 {
+				idx = utils.FormatIdx(instructions[0].OuterIndex, instructions[0].InnerIndex)
+			}
+			result.Errors = append(result.Errors, types.NewPanicError(types.ParseErrorDecoderPanic, programId, idx, r))
+		}
+	}()
+	fn()
+}
+
 // PumpfunInstruction represents a parsed Pumpfun instruction
 type PumpfunInstruction struct {
 	Type      string      `json:"type"`
@@ -276,39 +276,42 @@ func (p *PumpfunInstructionParser) ProcessInstructions() []interface{} {
 	return p.parseInstructions(instructions)
 }
 
-func (p *PumpfunInstructionParser) parseInstructions(instructions []types.ClassifiedInstruction) []interface{} {
-	var events []interface{}
+// ProcessTypedInstructions returns typed ParsedShredInstruction results
+func (p *PumpfunInstructionParser) ProcessTypedInstructions() []types.ParsedShredInstruction {
+	var events []types.ParsedShredInstruction
 
-	for _, ci := range instructions {
-		data := p.adapter.GetInstructionData(ci.Instruction)
-		if len(data) < 8 {
+	for _, ci := range p.classifier.GetInstructions(constants.DEX_PROGRAMS.PUMP_FUN.ID) {
+		eventType, eventData := p.decodeInstruction(ci)
+		if eventData == nil {
 			continue
 		}
-
-		disc := data[:8]
 		innerIdx := ci.InnerIndex
 		if innerIdx < 0 {
 			innerIdx = 0
 		}
+		events = append(events, types.ParsedShredInstruction{
+			ProgramID:   constants.DEX_PROGRAMS.PUMP_FUN.ID,
+			ProgramName: constants.DEX_PROGRAMS.PUMP_FUN.Name,
+			Action:      "pumpfun_" + strings.ToLower(eventType),
+			Data:        eventData,
+			Accounts:    p.adapter.GetInstructionAccounts(ci.Instruction),
+			Idx:         utils.FormatIdx(ci.OuterIndex, innerIdx),
+		})
+	}
+
+	return events
+}
+
+func (p *PumpfunInstructionParser) parseInstructions(instructions []types.ClassifiedInstruction) []interface{} {
+	var events []interface{}
 
-		var eventType string
-		var eventData interface{}
-
-		// Check discriminators
-		if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.CREATE) {
-			eventType = "CREATE"
-			eventData = p.decodeCreateInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.MIGRATE) {
-			eventType = "MIGRATE"
-			eventData = p.decodeMigrateInstruction(ci.Instruction)
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.BUY) {
-			eventType = "BUY"
-			eventData = p.decodeBuyInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.SELL) {
-			eventType = "SELL"
-			eventData = p.decodeSellInstruction(ci.Instruction, data[8:])
+	for _, ci := range instructions {
+		innerIdx := ci.InnerIndex
+		if innerIdx < 0 {
+			innerIdx = 0
 		}
 
+		eventType, eventData := p.decodeInstruction(ci)
 		if eventData != nil {
 			event := &PumpfunInstruction{
 				Type:      eventType,
@@ -333,6 +336,35 @@ func (p *PumpfunInstructionParser) parseInstructions(instructions []types.Classi
 	return events
 }
 
+// decodeInstruction decodes one Pumpfun instruction, returning nil data for unknown or malformed ones
+func (p *PumpfunInstructionParser) decodeInstruction(ci types.ClassifiedInstruction) (string, interface{}) {
+	data := p.adapter.GetInstructionData(ci.Instruction)
+	if len(data) < 8 {
+		return "", nil
+	}
+
+	// Check discriminators; typed nil pointers are returned as untyped nil
+	disc := data[:8]
+	if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.CREATE) {
+		if d := p.decodeCreateInstruction(ci.Instruction, data[8:]); d != nil {
+			return "CREATE", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.MIGRATE) {
+		if d := p.decodeMigrateInstruction(ci.Instruction); d != nil {
+			return "MIGRATE", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.BUY) {
+		if d := p.decodeBuyInstruction(ci.Instruction, data[8:]); d != nil {
+			return "BUY", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPFUN.SELL) {
+		if d := p.decodeSellInstruction(ci.Instruction, data[8:]); d != nil {
+			return "SELL", d
+		}
+	}
+	return "", nil
+}
+
 func (p *PumpfunInstructionParser) decodeBuyInstruction(instruction interface{}, data []byte) *PumpfunBuyData {
 	accounts := p.adapter.GetInstructionAccounts(instruction)
 	if len(accounts) < 7 {
@@ -540,42 +572,42 @@ func (p *PumpswapInstructionParser) ProcessInstructions() []interface{} {
 	return p.parseInstructions(instructions)
 }
 
-func (p *PumpswapInstructionParser) parseInstructions(instructions []types.ClassifiedInstruction) []interface{} {
-	var events []interface{}
+// ProcessTypedInstructions returns typed ParsedShredInstruction results
+func (p *PumpswapInstructionParser) ProcessTypedInstructions() []types.ParsedShredInstruction {
+	var events []types.ParsedShredInstruction
 
-	for _, ci := range instructions {
-		data := p.adapter.GetInstructionData(ci.Instruction)
-		if len(data) < 8 {
+	for _, ci := range p.classifier.GetInstructions(constants.DEX_PROGRAMS.PUMP_SWAP.ID) {
+		eventType, eventData := p.decodeInstruction(ci)
+		if eventData == nil {
 			continue
 		}
-
-		disc := data[:8]
 		innerIdx := ci.InnerIndex
 		if innerIdx < 0 {
 			innerIdx = 0
 		}
+		events = append(events, types.ParsedShredInstruction{
+			ProgramID:   constants.DEX_PROGRAMS.PUMP_SWAP.ID,
+			ProgramName: constants.DEX_PROGRAMS.PUMP_SWAP.Name,
+			Action:      "pumpswap_" + strings.ToLower(eventType),
+			Data:        eventData,
+			Accounts:    p.adapter.GetInstructionAccounts(ci.Instruction),
+			Idx:         utils.FormatIdx(ci.OuterIndex, innerIdx),
+		})
+	}
+
+	return events
+}
 
-		var eventType string
-		var eventData interface{}
-
-		// Check discriminators
-		if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.CREATE_POOL) {
-			eventType = "CREATE"
-			eventData = p.decodeCreateInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.ADD_LIQUIDITY) {
-			eventType = "ADD"
-			eventData = p.decodeAddLiquidityInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.REMOVE_LIQUIDITY) {
-			eventType = "REMOVE"
-			eventData = p.decodeRemoveLiquidityInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.BUY) {
-			eventType = "BUY"
-			eventData = p.decodeBuyInstruction(ci.Instruction, data[8:])
-		} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.SELL) {
-			eventType = "SELL"
-			eventData = p.decodeSellInstruction(ci.Instruction, data[8:])
+func (p *PumpswapInstructionParser) parseInstructions(instructions []types.ClassifiedInstruction) []interface{} {
+	var events []interface{}
+
+	for _, ci := range instructions {
+		innerIdx := ci.InnerIndex
+		if innerIdx < 0 {
+			innerIdx = 0
 		}
 
+		eventType, eventData := p.decodeInstruction(ci)
 		if eventData != nil {
 			event := &PumpswapInstruction{
 				Type:      eventType,
@@ -600,6 +632,39 @@ func (p *PumpswapInstructionParser) parseInstructions(instructions []types.Class
 	return events
 }
 
+// decodeInstruction decodes one Pumpswap instruction, returning nil data for unknown or malformed ones
+func (p *PumpswapInstructionParser) decodeInstruction(ci types.ClassifiedInstruction) (string, interface{}) {
+	data := p.adapter.GetInstructionData(ci.Instruction)
+	if len(data) < 8 {
+		return "", nil
+	}
+
+	// Check discriminators; typed nil pointers are returned as untyped nil
+	disc := data[:8]
+	if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.CREATE_POOL) {
+		if d := p.decodeCreateInstruction(ci.Instruction, data[8:]); d != nil {
+			return "CREATE", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.ADD_LIQUIDITY) {
+		if d := p.decodeAddLiquidityInstruction(ci.Instruction, data[8:]); d != nil {
+			return "ADD", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.REMOVE_LIQUIDITY) {
+		if d := p.decodeRemoveLiquidityInstruction(ci.Instruction, data[8:]); d != nil {
+			return "REMOVE", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.BUY) {
+		if d := p.decodeBuyInstruction(ci.Instruction, data[8:]); d != nil {
+			return "BUY", d
+		}
+	} else if bytesEqual(disc, constants.DISCRIMINATORS.PUMPSWAP.SELL) {
+		if d := p.decodeSellInstruction(ci.Instruction, data[8:]); d != nil {
+			return "SELL", d
+		}
+	}
+	return "", nil
+}
+
 func (p *PumpswapInstructionParser) decodeBuyInstruction(instruction interface{}, data []byte) *PumpswapBuyInstructionData {
 	accounts := p.adapter.GetInstructionAccounts(instruction)
 	if len(accounts) < 9 {
@@ -0,0 +1,181 @@
+package dexparser
+
+import (
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/jupiter"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/meteora"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/photon"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/raydium"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/systoken"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// ShredParserFactory creates a shred instruction parser for a program
+type ShredParserFactory func(
+	adapter *adapter.TransactionAdapter,
+	classifier *classifier.InstructionClassifier,
+) ShredInstructionParser
+
+// shredParserEntry is a registered shred parser and the key of its legacy Instructions group
+type shredParserEntry struct {
+	name    string
+	factory ShredParserFactory
+	always  bool // the Instructions key is set even without instructions, as Pumpfun and Pumpswap always did
+}
+
+// shredRegistry holds shred parser factories by program ID; a registry is never modified once published
+type shredRegistry map[string]shredParserEntry
+
+// clone returns a copy of the registry that can be modified before publishing
+func (r shredRegistry) clone() shredRegistry {
+	c := make(shredRegistry, len(r))
+	for k, v := range r {
+		c[k] = v
+	}
+	return c
+}
+
+// shredProgramName returns the legacy Instructions key for a program
+func shredProgramName(programId string) string {
+	if name := constants.GetProgramName(programId); name != "" {
+		return name
+	}
+	return programId
+}
+
+// shredParserFuncs adapts parsers with differently named methods to ShredInstructionParser
+type shredParserFuncs struct {
+	instructions      func() []interface{}
+	typedInstructions func() []types.ParsedShredInstruction
+}
+
+func (p *shredParserFuncs) ProcessInstructions() []interface{} {
+	return p.instructions()
+}
+
+func (p *shredParserFuncs) ProcessTypedInstructions() []types.ParsedShredInstruction {
+	return p.typedInstructions()
+}
+
+// registerDefaultShredParsers registers all default shred parsers
+func registerDefaultShredParsers(r shredRegistry) {
+	r[constants.DEX_PROGRAMS.PUMP_FUN.ID] = shredParserEntry{
+		name:   constants.DEX_PROGRAMS.PUMP_FUN.Name,
+		always: true,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return NewPumpfunInstructionParser(a, c)
+		},
+	}
+	r[constants.DEX_PROGRAMS.PUMP_SWAP.ID] = shredParserEntry{
+		name:   constants.DEX_PROGRAMS.PUMP_SWAP.Name,
+		always: true,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return NewPumpswapInstructionParser(a, c)
+		},
+	}
+	r[constants.DEX_PROGRAMS.PHOTON.ID] = shredParserEntry{
+		name: constants.DEX_PROGRAMS.PHOTON.Name,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return photon.NewPhotonShredParser(a, c)
+		},
+	}
+	r[constants.SYSTEM_PROGRAM_ID] = shredParserEntry{
+		name: "System",
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			p := systoken.NewSystemTokenShredParser(a, c)
+			return &shredParserFuncs{p.ProcessNativeInstructions, p.ProcessTypedNativeInstructions}
+		},
+	}
+	r[constants.TOKEN_PROGRAM_ID] = shredParserEntry{
+		name: "Token",
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			p := systoken.NewSystemTokenShredParser(a, c)
+			return &shredParserFuncs{p.ProcessTokenInstructions, p.ProcessTypedTokenInstructions}
+		},
+	}
+	r[constants.TOKEN_2022_PROGRAM_ID] = shredParserEntry{
+		name: "Token2022",
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			p := systoken.NewSystemTokenShredParser(a, c)
+			return &shredParserFuncs{p.ProcessToken2022Instructions, p.ProcessTypedToken2022Instructions}
+		},
+	}
+	r[constants.DEX_PROGRAMS.JUPITER.ID] = shredParserEntry{
+		name: constants.DEX_PROGRAMS.JUPITER.Name,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return jupiter.NewJupiterShredParser(a, c)
+		},
+	}
+	r[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] = shredParserEntry{
+		name: constants.DEX_PROGRAMS.RAYDIUM_V4.Name,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return raydium.NewRaydiumV4ShredParser(a, c)
+		},
+	}
+	r[constants.DEX_PROGRAMS.RAYDIUM_LCP.ID] = shredParserEntry{
+		name: constants.DEX_PROGRAMS.RAYDIUM_LCP.Name,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return raydium.NewLaunchpadShredParser(a, c)
+		},
+	}
+	r[constants.DEX_PROGRAMS.METEORA_DBC.ID] = shredParserEntry{
+		name: constants.DEX_PROGRAMS.METEORA_DBC.Name,
+		factory: func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) ShredInstructionParser {
+			return meteora.NewDBCShredParser(a, c)
+		},
+	}
+}
+
+// shredParserOptions collects NewShredParser options
+type shredParserOptions struct {
+	withoutDefaults bool
+	registrations   []func(r shredRegistry)
+}
+
+// ShredOption configures a ShredParser created by NewShredParser
+type ShredOption func(o *shredParserOptions)
+
+// WithoutDefaultShredParsers starts from an empty registry instead of the built-in shred parsers
+func WithoutDefaultShredParsers() ShredOption {
+	return func(o *shredParserOptions) {
+		o.withoutDefaults = true
+	}
+}
+
+// WithShredParser registers a shred parser for a program ID, replacing any default one
+func WithShredParser(programId string, factory ShredParserFactory) ShredOption {
+	return func(o *shredParserOptions) {
+		o.registrations = append(o.registrations, func(r shredRegistry) {
+			r[programId] = shredParserEntry{name: shredProgramName(programId), factory: factory}
+		})
+	}
+}
+
+// WithoutShredProgram removes the shred parser registered for a program ID up to this option
+func WithoutShredProgram(programId string) ShredOption {
+	return func(o *shredParserOptions) {
+		o.registrations = append(o.registrations, func(r shredRegistry) { delete(r, programId) })
+	}
+}
+
+// RegisterShredParser registers a shred parser for a program ID.
+// Instructions of programs without a known name are grouped under the program ID in the legacy Instructions map.
+func (p *ShredParser) RegisterShredParser(programId string, factory ShredParserFactory) {
+	p.update(func(r shredRegistry) {
+		r[programId] = shredParserEntry{name: shredProgramName(programId), factory: factory}
+	})
+}
+
+// update publishes a modified copy of the registry, so parses already running keep their snapshot
+func (p *ShredParser) update(fn func(r shredRegistry)) {
+	for {
+		current := p.registry.Load()
+		next := current.clone()
+		fn(next)
+		if p.registry.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package dexparser
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// StreamOrder specifies the order in which Stream delivers results
+type StreamOrder string
+
+const (
+	// StreamOrderArrival delivers results in the order transactions were received
+	StreamOrderArrival StreamOrder = "arrival"
+
+	// StreamOrderSlot delivers results sorted by slot, then index within the block. A slot is
+	// released once a later slot arrives, the input closes or the in-flight limit is reached.
+	StreamOrderSlot StreamOrder = "slot"
+
+	// StreamOrderCompletion delivers results as soon as they are parsed
+	StreamOrderCompletion StreamOrder = "completion"
+)
+
+// StreamOptions configures DexParser.Stream
+type StreamOptions struct {
+	// Config is the parse config applied to every transaction
+	Config *types.ParseConfig
+
+	// Workers is the number of parsing goroutines (default: runtime.NumCPU())
+	Workers int
+
+	// MaxInFlight bounds transactions read but not yet delivered (default: 4 * Workers).
+	// Workers is lowered to MaxInFlight when it is larger, since extra workers would sit idle.
+	MaxInFlight int
+
+	// Order selects the output order (default: StreamOrderArrival)
+	Order StreamOrder
+
+	// CorrelationID derives the ID attached to each result (default: first signature)
+	CorrelationID func(tx *adapter.SolanaTransaction) string
+}
+
+// StreamResult is a parse result delivered by Stream
+type StreamResult struct {
+	Seq           uint64                     // Arrival sequence number, starting at 0
+	CorrelationID string                     // ID derived from the input transaction
+	Tx            *adapter.SolanaTransaction // Input transaction
+	Result        *types.ParseResult         // Parse result
+}
+
+// streamItem tracks one transaction from arrival until delivery
+type streamItem struct {
+	StreamResult
+	done bool
+}
+
+// streamEvent notifies the stream coordinator of an arrival, a completion or the end of input
+type streamEvent struct {
+	item     *streamItem
+	complete bool
+	closed   bool
+}
+
+// Stream parses transactions read from in on a fixed worker pool and delivers results on the
+// returned channel, which is closed once in is closed and drained or ctx is done. At most
+// MaxInFlight transactions are held at a time, so a slow consumer slows reading from in.
+// After ctx is done no further transactions are read and undelivered results are dropped.
+func (dp *DexParser) Stream(ctx context.Context, in <-chan *adapter.SolanaTransaction, opts *StreamOptions) <-chan StreamResult {
+	o := StreamOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = 4 * o.Workers
+	} else if o.Workers > o.MaxInFlight {
+		o.Workers = o.MaxInFlight
+	}
+	if o.Order == "" {
+		o.Order = StreamOrderArrival
+	}
+	if o.CorrelationID == nil {
+		o.CorrelationID = signatureOf
+	}
+
+	out := make(chan StreamResult)
+	events := make(chan streamEvent)
+	jobs := make(chan *streamItem)
+	slots := make(chan struct{}, o.MaxInFlight)
+
+	var producers sync.WaitGroup
+
+	// Reader: admits a transaction only when an in-flight slot is free
+	producers.Add(1)
+	go func() {
+		defer producers.Done()
+		defer close(jobs)
+		defer func() { events <- streamEvent{closed: true} }()
+
+		var seq uint64
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			var tx *adapter.SolanaTransaction
+			var ok bool
+			select {
+			case tx, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			if tx == nil {
+				<-slots
+				continue
+			}
+
+			item := &streamItem{StreamResult: StreamResult{Seq: seq, CorrelationID: o.CorrelationID(tx), Tx: tx}}
+			seq++
+			events <- streamEvent{item: item}
+			jobs <- item
+		}
+	}()
+
+	// Workers
+	for w := 0; w < o.Workers; w++ {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for item := range jobs {
+				item.Result, _ = dp.parseOne(ctx, int(item.Seq), item.Tx, o.Config)
+				events <- streamEvent{item: item, complete: true}
+			}
+		}()
+	}
+
+	go func() {
+		producers.Wait()
+		close(events)
+	}()
+
+	// Coordinator: owns ordering state and delivers results
+	go func() {
+		defer close(out)
+		order := newStreamOrderer(o.Order, o.MaxInFlight)
+		emit := func(item *streamItem) {
+			if ctx.Err() == nil {
+				select {
+				case out <- item.StreamResult:
+				case <-ctx.Done():
+				}
+			}
+			<-slots
+		}
+
+		for ev := range events {
+			switch {
+			case ev.closed:
+				order.closed = true
+			case ev.complete:
+				ev.item.done = true
+			default:
+				order.add(ev.item)
+				continue
+			}
+			for _, item := range order.ready() {
+				emit(item)
+			}
+		}
+	}()
+
+	return out
+}
+
+// streamOrderer buffers stream items and releases them in the configured order
+type streamOrderer struct {
+	order       StreamOrder
+	maxInFlight int
+	closed      bool
+
+	pending []*streamItem // arrival order, or (slot, index, seq) order for StreamOrderSlot
+	maxSlot uint64
+}
+
+// newStreamOrderer creates an orderer for the given mode
+func newStreamOrderer(order StreamOrder, maxInFlight int) *streamOrderer {
+	return &streamOrderer{order: order, maxInFlight: maxInFlight}
+}
+
+// add registers an arrived item
+func (s *streamOrderer) add(item *streamItem) {
+	if s.order != StreamOrderSlot {
+		s.pending = append(s.pending, item)
+		return
+	}
+
+	if item.Tx.Slot > s.maxSlot {
+		s.maxSlot = item.Tx.Slot
+	}
+	i := sort.Search(len(s.pending), func(i int) bool {
+		return streamItemLess(item, s.pending[i])
+	})
+	s.pending = append(s.pending, nil)
+	copy(s.pending[i+1:], s.pending[i:])
+	s.pending[i] = item
+}
+
+// ready removes and returns the items that can be delivered now
+func (s *streamOrderer) ready() []*streamItem {
+	var ready []*streamItem
+	if s.order == StreamOrderCompletion {
+		remaining := s.pending[:0]
+		for _, item := range s.pending {
+			if item.done {
+				ready = append(ready, item)
+			} else {
+				remaining = append(remaining, item)
+			}
+		}
+		s.pending = remaining
+		return ready
+	}
+
+	for len(s.pending) > 0 {
+		head := s.pending[0]
+		if !head.done {
+			break
+		}
+		if s.order == StreamOrderSlot && head.Tx.Slot >= s.maxSlot && !s.closed && len(s.pending) < s.maxInFlight {
+			break
+		}
+		ready = append(ready, head)
+		s.pending = s.pending[1:]
+	}
+	return ready
+}
+
+// streamItemLess orders items by slot, index within the block, then arrival
+func streamItemLess(a, b *streamItem) bool {
+	if a.Tx.Slot != b.Tx.Slot {
+		return a.Tx.Slot < b.Tx.Slot
+	}
+	if a.Tx.Index != b.Tx.Index {
+		return a.Tx.Index < b.Tx.Index
+	}
+	return a.Seq < b.Seq
+}
+
+// signatureOf returns the first signature of a transaction
+func signatureOf(tx *adapter.SolanaTransaction) string {
+	if len(tx.Transaction.Signatures) > 0 {
+		return tx.Transaction.Signatures[0]
+	}
+	return ""
+}
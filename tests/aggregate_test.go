@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/aggregate"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/pricing"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// candleResult is a successful result with one trade by user on pool
+func candleResult(slot uint64, user, pool string, trade types.TradeInfo) *types.ParseResult {
+	trade.User = user
+	trade.Pool = []string{pool}
+	result := types.NewParseResult()
+	result.TxStatus = types.TransactionStatusSuccess
+	result.Slot = slot
+	result.Signer = []string{user}
+	result.Trades = []types.TradeInfo{trade}
+	return result
+}
+
+// drain reads the candles available on ch
+func drain(ch <-chan aggregate.Candle) []aggregate.Candle {
+	var candles []aggregate.Candle
+	for {
+		select {
+		case c, ok := <-ch:
+			if !ok {
+				return candles
+			}
+			candles = append(candles, c)
+		default:
+			return candles
+		}
+	}
+}
+
+func TestCandleAggregator(t *testing.T) {
+	sol, token, pool := constants.TOKENS.SOL, testPubkey(1), testPubkey(20)
+	traderA, traderB := testPubkey(30), testPubkey(31)
+	const minute = 1718000040
+
+	agg := aggregate.NewAggregator(aggregate.Config{Intervals: []time.Duration{time.Minute}, Grace: 5 * time.Second, Buffer: 16})
+	agg.Add(candleResult(100, traderA, pool, pricedTrade(minute+1, sol, 1, token, 1000)))
+	agg.Add(candleResult(102, traderB, pool, pricedTrade(minute+3, token, 500, sol, 0.6)))
+	// An earlier slot arriving late does not become the close
+	agg.Add(candleResult(101, traderA, pool, pricedTrade(minute+2, constants.TOKENS.NATIVE, 0.09, token, 100)))
+	agg.Add(candleResult(150, traderB, pool, pricedTrade(minute+62, sol, 1, token, 1000)))
+	if got := drain(agg.Candles()); len(got) != 0 {
+		t.Fatalf("expected the first minute to wait for the grace window, got %+v", got)
+	}
+	// Within the grace window
+	agg.Add(candleResult(103, traderA, pool, pricedTrade(minute+4, sol, 0.15, token, 100)))
+	failed := candleResult(104, traderB, pool, pricedTrade(minute+5, sol, 100, token, 1))
+	failed.TxStatus = types.TransactionStatusFailed
+	agg.Add(failed)
+
+	agg.Add(candleResult(151, traderA, pool, pricedTrade(minute+66, token, 1000, sol, 1.1)))
+	got := drain(agg.Candles())
+	if len(got) != 1 {
+		t.Fatalf("expected the first minute to be emitted, got %d candles", len(got))
+	}
+	c := got[0]
+	if c.Key != pool || c.BaseMint != token || c.QuoteMint != sol || c.Interval != time.Minute || c.Start != minute {
+		t.Errorf("unexpected candle identity: %+v", c)
+	}
+	if !approxEqual(c.Open, 0.001) || !approxEqual(c.High, 0.0015) || !approxEqual(c.Low, 0.0009) || !approxEqual(c.Close, 0.0015) {
+		t.Errorf("unexpected prices: %v %v %v %v", c.Open, c.High, c.Low, c.Close)
+	}
+	if !approxEqual(c.Volume, 1700) || !approxEqual(c.QuoteVolume, 1.84) || !approxEqual(c.BuyVolume, 1.24) || !approxEqual(c.SellVolume, 0.6) {
+		t.Errorf("unexpected volumes: %+v", c)
+	}
+	if c.Trades != 4 || c.Buys != 3 || c.Sells != 1 || c.UniqueTraders != 2 || c.FirstSlot != 100 || c.LastSlot != 103 {
+		t.Errorf("unexpected counts: %+v", c)
+	}
+
+	// After emission the minute is closed to late trades
+	agg.Add(candleResult(104, traderA, pool, pricedTrade(minute+5, sol, 1, token, 1000)))
+	if agg.Late() != 1 {
+		t.Errorf("expected one late trade, got %d", agg.Late())
+	}
+
+	agg.Close()
+	got = drain(agg.Candles())
+	if len(got) != 1 || got[0].Start != minute+60 || got[0].Trades != 2 || !approxEqual(got[0].Close, 0.0011) {
+		t.Errorf("expected Close to flush the second minute, got %+v", got)
+	}
+	if _, ok := <-agg.Candles(); ok {
+		t.Error("expected the channel to be closed")
+	}
+}
+
+func TestCandleAggregatorByMint(t *testing.T) {
+	sol, usdc, token := constants.TOKENS.SOL, constants.TOKENS.USDC, testPubkey(1)
+	trader := testPubkey(30)
+	const start = 1718000040
+
+	config := aggregate.DefaultConfig()
+	config.Intervals = []time.Duration{time.Second, time.Minute, 500 * time.Millisecond}
+	config.KeyBy = aggregate.KeyByMint
+	agg := aggregate.NewAggregator(config)
+	agg.AddBlock(&types.BlockResult{Transactions: []types.BlockTransaction{
+		{Index: 0, Result: candleResult(100, trader, testPubkey(20), pricedTrade(start, sol, 1, token, 1000))},
+		{Index: 1, Result: candleResult(100, trader, testPubkey(21), pricedTrade(start, sol, 1, token, 500))},
+		{Index: 2, Result: candleResult(100, trader, testPubkey(22), pricedTrade(start, usdc, 150, token, 1000))},
+		{Index: 3, Result: candleResult(100, trader, testPubkey(23), pricedTrade(start, sol, 1, usdc, 150))},
+	}})
+	agg.Flush()
+
+	got := drain(agg.Candles())
+	if len(got) != 6 {
+		t.Fatalf("expected 3 markets at 2 intervals, got %d candles", len(got))
+	}
+	for i, want := range []struct {
+		interval    time.Duration
+		key, quote  string
+		trades      int
+		open, close float64
+	}{
+		{time.Second, token, usdc, 1, 0.15, 0.15},
+		{time.Second, token, sol, 2, 0.001, 0.002},
+		{time.Second, sol, usdc, 1, 150, 150},
+		{time.Minute, token, usdc, 1, 0.15, 0.15},
+	} {
+		c := got[i]
+		if c.Interval != want.interval || c.Key != want.key || c.QuoteMint != want.quote || c.Trades != want.trades ||
+			!approxEqual(c.Open, want.open) || !approxEqual(c.Close, want.close) {
+			t.Errorf("candle %d: unexpected %+v", i, c)
+		}
+	}
+}
+
+func TestCandleOrientationMatchesPricing(t *testing.T) {
+	// SOL quotes non-USD stablecoins in candles and prices alike
+	trade := pricedTrade(1718000040, constants.TOKENS.SOL, 1, constants.TOKENS.EURC, 160)
+	agg := aggregate.NewAggregator(aggregate.Config{Intervals: []time.Duration{time.Minute}, KeyBy: aggregate.KeyByMint, Buffer: 4})
+	agg.Add(candleResult(100, testPubkey(30), testPubkey(20), trade))
+	agg.Flush()
+
+	engine := pricing.NewEngine(pricing.Config{})
+	engine.Apply(&trade)
+
+	got := drain(agg.Candles())
+	if len(got) != 1 || got[0].BaseMint != constants.TOKENS.EURC || got[0].QuoteMint != constants.TOKENS.SOL {
+		t.Fatalf("expected an EURC/SOL candle, got %+v", got)
+	}
+	if !approxEqual(got[0].Close, trade.PriceQuote) || !approxEqual(trade.PriceQuote, 1.0/160) {
+		t.Errorf("candle price %v and priced trade %v differ", got[0].Close, trade.PriceQuote)
+	}
+}
+
+func TestCandleAggregatorFlushCloses(t *testing.T) {
+	sol, token, pool, trader := constants.TOKENS.SOL, testPubkey(1), testPubkey(20), testPubkey(30)
+	const minute = 1718000040
+
+	agg := aggregate.NewAggregator(aggregate.Config{Intervals: []time.Duration{time.Minute}, Buffer: 8})
+	agg.Add(candleResult(100, trader, pool, pricedTrade(minute+1, sol, 1, token, 1000)))
+	agg.Flush()
+	// The flushed interval is closed, the next one is not
+	agg.Add(candleResult(101, trader, pool, pricedTrade(minute+2, sol, 1, token, 1000)))
+	agg.Add(candleResult(102, trader, pool, pricedTrade(minute+61, sol, 1, token, 1000)))
+	agg.Close()
+
+	got := drain(agg.Candles())
+	if len(got) != 2 || got[0].Start != minute || got[0].Trades != 1 || got[1].Start != minute+60 {
+		t.Errorf("expected one candle per interval, got %+v", got)
+	}
+	if agg.Late() != 1 {
+		t.Errorf("expected the trade after Flush to be late, got %d", agg.Late())
+	}
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+	"github.com/mr-tron/base58"
+)
+
+// hop is a trade of amountIn of in for amountOut of out on pool
+func hop(idx, pool, in string, amountIn float64, out string, amountOut float64) types.TradeInfo {
+	return types.TradeInfo{
+		Pool:        []string{pool},
+		InputToken:  types.TokenInfo{Mint: in, Amount: amountIn},
+		OutputToken: types.TokenInfo{Mint: out, Amount: amountOut},
+		ProgramId:   launchpadProgram,
+		Idx:         idx,
+	}
+}
+
+func balanceChange(amount string, decimals uint8) *types.BalanceChange {
+	return &types.BalanceChange{Change: types.TokenAmount{Amount: amount, Decimals: decimals}}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestGetArbitrage(t *testing.T) {
+	sol, usdc, token := constants.TOKENS.SOL, constants.TOKENS.USDC, testPubkey(1)
+	signer := testPubkey(30)
+	result := types.NewParseResult()
+	result.TxStatus = types.TransactionStatusSuccess
+	result.Signer = []string{signer}
+	result.Fee = types.TokenAmount{Amount: "105000", Decimals: 9}
+	result.PriorityFee = types.TokenAmount{Amount: "100000", Decimals: 9}
+	result.Tips = []types.TipInfo{{Provider: "Jito", Payer: signer, AmountRaw: "1000000"}}
+	// Profit arrives as wrapped SOL, the fee and tip leave the native balance
+	result.SolBalanceChange = balanceChange("-1105000", 9)
+	result.TokenBalanceChange = map[string]*types.BalanceChange{sol: balanceChange("5000000", 9)}
+
+	// Hops out of execution order, a native SOL leg and an unrelated trade
+	trades := []types.TradeInfo{
+		hop("3", testPubkey(22), usdc, 150, constants.TOKENS.NATIVE, 1.005),
+		hop("1", testPubkey(20), sol, 1, token, 1000),
+		hop("0", testPubkey(23), usdc, 10, testPubkey(2), 5),
+		hop("2-1", testPubkey(21), token, 1000, usdc, 150),
+	}
+	arb := utils.GetArbitrage(result, trades)
+	if arb == nil || arb.Type != types.ArbitrageTypeAtomic || len(arb.Cycles) != 1 {
+		t.Fatalf("expected one atomic cycle, got %+v", arb)
+	}
+	cycle := arb.Cycles[0]
+	if cycle.Mint != sol || len(cycle.Hops) != 3 || cycle.Hops[1].Idx != "2-1" || cycle.Hops[2].OutputMint != sol || !approxEqual(cycle.Profit, 0.005) {
+		t.Errorf("unexpected cycle: %+v", cycle)
+	}
+	if !approxEqual(arb.Fee, 0.000105) || !approxEqual(arb.PriorityFee, 0.0001) || !approxEqual(arb.Tips, 0.001) {
+		t.Errorf("unexpected costs: %v %v %v", arb.Fee, arb.PriorityFee, arb.Tips)
+	}
+	if len(arb.GrossProfit) != 1 || !approxEqual(arb.GrossProfit[sol], 0.005) || !approxEqual(arb.NetProfit[sol], 0.003895) {
+		t.Errorf("unexpected profit: gross %v net %v", arb.GrossProfit, arb.NetProfit)
+	}
+
+	// Tips paid by another account count in Tips but not against the signer's profit
+	result.Tips = append(result.Tips, types.TipInfo{Provider: "Jito", Payer: testPubkey(31), AmountRaw: "2000000"})
+	if arb := utils.GetArbitrage(result, trades); arb == nil || !approxEqual(arb.Tips, 0.003) || !approxEqual(arb.GrossProfit[sol], 0.005) || !approxEqual(arb.NetProfit[sol], 0.003895) {
+		t.Errorf("unexpected profit with a third-party tip: %+v", arb)
+	}
+
+	// A failed transaction keeps its cycle but only pays the fee
+	result.TxStatus = types.TransactionStatusFailed
+	result.SolBalanceChange = balanceChange("-105000", 9)
+	result.TokenBalanceChange = nil
+	result.Arbitrage = utils.GetArbitrage(result, trades)
+	if arb := result.Arbitrage; arb == nil || arb.Type != types.ArbitrageTypeFailed || arb.Tips != 0 || len(arb.GrossProfit) != 0 || !approxEqual(arb.NetProfit[sol], -0.000105) {
+		t.Errorf("unexpected failed arbitrage: %+v", arb)
+	}
+	if result.IsArbitrage() {
+		t.Error("a failed arbitrage is not an arbitrage")
+	}
+
+	// Buying and selling on the same pool is a round trip, not a cycle
+	roundTrip := []types.TradeInfo{
+		hop("0", testPubkey(20), sol, 1, token, 1000),
+		hop("1", testPubkey(20), token, 1000, sol, 1.1),
+	}
+	if arb := utils.GetArbitrage(result, roundTrip); arb != nil {
+		t.Errorf("expected no cycle for a round trip, got %+v", arb)
+	}
+}
+
+func TestParseBlockBackrun(t *testing.T) {
+	sol, token := constants.TOKENS.SOL, testPubkey(1)
+	trades := map[string][]types.TradeInfo{
+		"victim": {hop("0", testPubkey(20), sol, 2, token, 1900)},
+		"backrun": {
+			hop("0", testPubkey(21), sol, 1, token, 1000),
+			hop("1", testPubkey(20), token, 1000, sol, 1.01),
+		},
+		"atomic": {
+			hop("0", testPubkey(22), sol, 1, token, 1000),
+			hop("1", testPubkey(23), token, 1000, sol, 1.01),
+		},
+	}
+	newTx := func(signature string, signer byte) *adapter.SolanaTransaction {
+		tx := newLaunchpadTransaction(true)
+		tx.Transaction.Signatures = []string{signature}
+		tx.Transaction.Message.StaticAccountKeys[0] = testPubkey(signer)
+		return tx
+	}
+	block := &adapter.SolanaBlock{
+		Slot: 100,
+		Transactions: []*adapter.SolanaTransaction{
+			newTx("victim", 31),
+			newTx("backrun", 30),
+			newTx("atomic", 30),
+		},
+	}
+
+	parser := dexparser.NewDexParser(dexparser.WithTradeParser(launchpadProgram,
+		func(adapt *adapter.TransactionAdapter, _ types.DexInfo, _ map[string][]types.TransferData, _ []types.ClassifiedInstruction) parsers.TradeParser {
+			return &stubTradeParser{trades: trades[adapt.Signature()]}
+		}))
+	result := parser.ParseBlock(block, nil)
+	if len(result.Transactions) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(result.Transactions))
+	}
+
+	if arb := result.Transactions[0].Result.Arbitrage; arb != nil {
+		t.Errorf("a single trade is not an arbitrage: %+v", arb)
+	}
+	backrun := result.Transactions[1].Result
+	if arb := backrun.Arbitrage; arb == nil || arb.Type != types.ArbitrageTypeBackrun || arb.Target != "victim" || !backrun.IsArbitrage() {
+		t.Errorf("expected a backrun of the victim, got %+v", arb)
+	}
+	if arb := result.Transactions[2].Result.Arbitrage; arb == nil || arb.Type != types.ArbitrageTypeAtomic || arb.Target != "" {
+		t.Errorf("expected an atomic arbitrage, got %+v", arb)
+	}
+
+	// Parsed on its own, the backrun has no preceding transaction to follow
+	single := parser.ParseAll(newTx("backrun", 30), nil)
+	if arb := single.Arbitrage; arb == nil || arb.Type != types.ArbitrageTypeAtomic {
+		t.Errorf("expected an atomic arbitrage outside a block, got %+v", arb)
+	}
+}
+
+// failedJupiterRoute is a failed transaction by testPubkey(30) whose only instruction is a
+// Jupiter route from the signer's wrapped SOL account to outputMint
+func failedJupiterRoute(outputMint string) *adapter.SolanaTransaction {
+	sol := constants.TOKENS.SOL
+	// An empty route plan, in_amount, quoted_out_amount, slippage_bps and platform_fee_bps
+	data := (&borsh{}).raw(constants.DISCRIMINATORS.JUPITER.ROUTE...).u32(0).u64(1_000_000_000).u64(1_010_000_000).u16(50).raw(0).Bytes()
+	balances := []adapter.TokenBalance{{
+		AccountIndex:  2,
+		Mint:          sol,
+		Owner:         testPubkey(30),
+		UiTokenAmount: types.TokenAmount{Amount: "1000000000", Decimals: 9},
+	}}
+	keys := []string{testPubkey(30), constants.TOKEN_PROGRAM_ID, testPubkey(31), testPubkey(32), outputMint, constants.DEX_PROGRAMS.JUPITER.ID}
+	tx := newTestTransaction("failed_route", keys,
+		adapter.CompiledInstruction{ProgramIdIndex: 5, Accounts: []int{1, 0, 2, 3, 5, 4}, Data: base58.Encode(data)},
+	)
+	tx.Slot = 12
+	tx.Meta = &adapter.TransactionMeta{
+		Err:               map[string]interface{}{"InstructionError": []interface{}{0, map[string]interface{}{"Custom": 6001}}},
+		Fee:               5000,
+		PreBalances:       []uint64{1_000_000_000, 0, 0, 0, 0, 0},
+		PostBalances:      []uint64{999_995_000, 0, 0, 0, 0, 0},
+		PreTokenBalances:  balances,
+		PostTokenBalances: balances,
+	}
+	return tx
+}
+
+func TestFailedRouteArbitrage(t *testing.T) {
+	sol := constants.TOKENS.SOL
+	parser := dexparser.NewDexParser()
+
+	result := parser.ParseAll(failedJupiterRoute(sol), nil)
+	arb := result.Arbitrage
+	if arb == nil || arb.Type != types.ArbitrageTypeFailed || len(arb.Cycles) != 1 {
+		t.Fatalf("expected a failed arbitrage from the route, got %+v", arb)
+	}
+	cycle := arb.Cycles[0]
+	if cycle.Mint != sol || len(cycle.Hops) != 1 || cycle.Hops[0].Idx != "0" || cycle.Hops[0].AMM != constants.DEX_PROGRAMS.JUPITER.Name || !approxEqual(cycle.Profit, 0.01) {
+		t.Errorf("unexpected cycle: %+v", cycle)
+	}
+	if !approxEqual(arb.Fee, 0.000005) || !approxEqual(arb.NetProfit[sol], -0.000005) {
+		t.Errorf("unexpected accounting: %+v", arb)
+	}
+	if result.IsArbitrage() {
+		t.Error("a failed arbitrage is not an arbitrage")
+	}
+
+	// A failed swap into another token is not an arbitrage
+	if arb := parser.ParseAll(failedJupiterRoute(testPubkey(1)), nil).Arbitrage; arb != nil {
+		t.Errorf("expected no arbitrage for a swap, got %+v", arb)
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/idl"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/goccy/go-json"
+)
+
+// recordedBlock is a getBlock response trimmed to one vote transaction
+const recordedBlock = `{
+  "blockHeight": 250000000,
+  "blockTime": 1718000100,
+  "blockhash": "FvXxCTrJGMc3Xhvr3oUk4cGsqSzYk1Kxuf9QCqxy3gu9",
+  "parentSlot": 270000099,
+  "previousBlockhash": "4fjxYrjEqwVFhoetGK5WejRrfSvgJpYtbMY2R1HrzEzf",
+  "transactions": [{
+    "meta": {"err": null, "fee": 5000, "innerInstructions": [], "logMessages": [], "postBalances": [1, 1, 1], "preBalances": [1, 1, 1], "postTokenBalances": [], "preTokenBalances": []},
+    "transaction": {
+      "message": {
+        "accountKeys": ["FWMXVWNaGcvhgzX5tPe8PdZBMzjgVXKMsms498Kjm8WK", "2Eajoxx9cEy1ah3PAA5qRZjCQEmWFjvcrVMf3ovbAUEF", "Vote111111111111111111111111111111111111111"],
+        "header": {"numReadonlySignedAccounts": 0, "numReadonlyUnsignedAccounts": 1, "numRequiredSignatures": 1},
+        "instructions": [{"accounts": [1, 0], "data": "Ldp", "programIdIndex": 2, "stackHeight": null}],
+        "recentBlockhash": "4fjxYrjEqwVFhoetGK5WejRrfSvgJpYtbMY2R1HrzEzf"
+      },
+      "signatures": ["5PEQ7CQXXxtYujxEWuyxHW7Dvz9dv8azXJqWDa3Ey9KJWMbtD6Mi1wmFUuFQxKq6o2Em652FAV8NjFBrHAqULBvr"]
+    },
+    "version": "legacy"
+  }]
+}`
+
+// createEventParser reports a token creation of the launchpad mint
+type createEventParser struct{}
+
+func (createEventParser) ProcessEvents() []types.MemeEvent {
+	return []types.MemeEvent{{Type: types.TradeTypeCreate, BaseMint: testPubkey(1)}}
+}
+
+func TestParseBlock(t *testing.T) {
+	var block adapter.SolanaBlock
+	if err := json.Unmarshal([]byte(recordedBlock), &block); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	block.Slot = 270000100
+
+	failed := newRoutedTransaction(nil)
+	failed.Meta.Err = rpcErr(t, `{"InstructionError":[2,{"Custom":30}]}`)
+	flaggedVote := newLaunchpadTransaction(true)
+	flaggedVote.IsVote = true
+	block.Transactions = append(block.Transactions,
+		newLaunchpadTransaction(true),
+		flaggedVote,
+		failed,
+		newLaunchpadTransaction(false),
+		newRoutedTransaction(nil),
+	)
+
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	program := idl.NewProgram(parsed, idl.Mapping{
+		Trades: []idl.TradeMapping{{Event: "TradeEvent", IsBuy: "is_buy", User: "user", BaseMint: "mint", BaseAmount: "token_amount", QuoteAmount: "sol_amount"}},
+	})
+	parser := dexparser.NewDexParser(
+		dexparser.WithTradeParser(program.ProgramId, program.NewTradeParser),
+		dexparser.WithMemeParser(program.ProgramId, func(*adapter.TransactionAdapter, map[string][]types.TransferData) parsers.EventParser {
+			return createEventParser{}
+		}),
+	)
+	result := parser.ParseBlock(&block, nil)
+
+	if result.Slot != 270000100 || result.ParentSlot != 270000099 || result.BlockTime != 1718000100 || result.Blockhash != block.Blockhash {
+		t.Errorf("unexpected block fields: %+v", result)
+	}
+	if result.VoteCount != 2 || len(result.Transactions) != 4 {
+		t.Fatalf("expected 2 votes skipped and 4 results, got %d and %d", result.VoteCount, len(result.Transactions))
+	}
+	for i, want := range []int{1, 3, 4, 5} {
+		tx := result.Transactions[i]
+		if tx.Index != want || tx.Result.Slot != 270000100 || tx.Result.Timestamp != 1718000100 {
+			t.Errorf("transaction %d: unexpected position or block fields: index=%d slot=%d time=%d", i, tx.Index, tx.Result.Slot, tx.Result.Timestamp)
+		}
+	}
+	if block.Transactions[1].Slot != 11 {
+		t.Error("the block's transactions must not be modified")
+	}
+
+	agg := result.Aggregates
+	if agg.TradeCount != 2 || len(agg.AMMs) != 1 {
+		t.Fatalf("unexpected trade aggregates: %+v", agg)
+	}
+	if stats := agg.AMMs["launchpad"]; stats == nil || stats.Trades != 2 || stats.Volume[constants.TOKENS.SOL] != 1 {
+		t.Errorf("unexpected launchpad stats: %+v", stats)
+	}
+	if len(agg.NewTokens) != 1 || agg.NewTokens[0] != testPubkey(1) {
+		t.Errorf("expected one new token, got %v", agg.NewTokens)
+	}
+	if agg.FailedTransactions != 1 || agg.FailedSwaps != 1 {
+		t.Errorf("expected one failed swap through raydium, got %d/%d", agg.FailedSwaps, agg.FailedTransactions)
+	}
+
+	if empty := parser.ParseBlock(nil, nil); len(empty.Transactions) != 0 || empty.Aggregates.AMMs == nil {
+		t.Errorf("unexpected result for a nil block: %+v", empty)
+	}
+}
+
+func TestIsVoteTransaction(t *testing.T) {
+	var block adapter.SolanaBlock
+	if err := json.Unmarshal([]byte(recordedBlock), &block); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !adapter.IsVoteTransaction(block.Transactions[0]) {
+		t.Error("expected the json vote transaction to be detected")
+	}
+
+	// A v0 message invoking the vote program and another program is not a vote
+	tx := newLaunchpadTransaction(true)
+	msg := &tx.Transaction.Message
+	msg.StaticAccountKeys = append(msg.StaticAccountKeys, constants.VOTE_PROGRAM_ID)
+	if adapter.IsVoteTransaction(tx) {
+		t.Error("launchpad transaction is not a vote")
+	}
+	msg.CompiledInstructions = []adapter.CompiledInstruction{{ProgramIdIndex: 5}}
+	if !adapter.IsVoteTransaction(tx) {
+		t.Error("expected a vote-only v0 message to be detected")
+	}
+	msg.CompiledInstructions = append(msg.CompiledInstructions, adapter.CompiledInstruction{ProgramIdIndex: 4})
+	if adapter.IsVoteTransaction(tx) || adapter.IsVoteTransaction(nil) {
+		t.Error("mixed or nil transactions are not votes")
+	}
+}
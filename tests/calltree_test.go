@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+func TestCallTree(t *testing.T) {
+	config := types.DefaultParseConfig()
+	config.CallTree = true
+	result := dexparser.NewDexParser().ParseAll(newRoutedTransaction(routedLogs()), &config)
+
+	tree := result.CallTree
+	if tree == nil || len(tree.Roots) != 2 || tree.Truncated {
+		t.Fatalf("unexpected call tree: %+v", tree)
+	}
+	if cb := tree.Roots[0]; cb.ProgramId != logComputeBudget || cb.OuterIndex != 0 || cb.Status != types.CallStatusSuccess {
+		t.Errorf("unexpected compute budget node: %+v", cb)
+	}
+
+	router := tree.Roots[1]
+	if router.OuterIndex != 2 || router.InnerIndex != -1 || router.ComputeUnitsConsumed != 60000 || router.ComputeUnitsAvailable != 250000 {
+		t.Errorf("unexpected router node: %+v", router)
+	}
+	if len(router.Logs) != 2 || router.Logs[0] != "Program log: Instruction: Route" {
+		t.Errorf("expected the router's own logs, got %v", router.Logs)
+	}
+	if len(router.Children) != 1 || len(router.Children[0].Children) != 2 {
+		t.Fatalf("unexpected router children: %+v", router.Children)
+	}
+
+	hop := tree.Find(2, 0)
+	if hop == nil || hop.ProgramId != constants.DEX_PROGRAMS.RAYDIUM_V4.ID || hop.Depth != 2 || hop.ComputeUnitsConsumed != 30000 {
+		t.Errorf("unexpected raydium hop: %+v", hop)
+	}
+	if transfer := tree.Find(2, 2); transfer == nil || transfer.ProgramId != constants.TOKEN_PROGRAM_ID || transfer.Depth != 3 {
+		t.Errorf("unexpected second transfer: %+v", transfer)
+	}
+	if tree.Find(1, -1) != nil {
+		t.Error("precompile wrote no logs and must have no node")
+	}
+
+	units := tree.ProgramComputeUnits()
+	if units[logRouter] != 30000 || units[constants.DEX_PROGRAMS.RAYDIUM_V4.ID] != 25355 {
+		t.Errorf("unexpected per-program compute units: %v", units)
+	}
+	if tree.FailedCall() != nil {
+		t.Error("successful transaction has no failed call")
+	}
+
+	if result = dexparser.NewDexParser().ParseAll(newRoutedTransaction(routedLogs()), nil); result.CallTree != nil {
+		t.Error("call tree must only be attached when requested")
+	}
+}
+
+func TestCallTreeFailedCPI(t *testing.T) {
+	v4 := constants.DEX_PROGRAMS.RAYDIUM_V4.ID
+	logs := []string{
+		"Program " + logRouter + " invoke [1]",
+		"Program " + v4 + " invoke [2]",
+		"Program log: Error: exceeds desired slippage limit",
+		"Program " + v4 + " consumed 12000 of 190000 compute units",
+		"Program " + v4 + " failed: custom program error: 0x1e",
+		"Program " + logRouter + " consumed 20000 of 200000 compute units",
+		"Program " + logRouter + " failed: custom program error: 0x1e",
+	}
+	txAdapter := adapter.NewTransactionAdapter(newRoutedTransaction(logs), &types.ParseConfig{})
+	tree := txAdapter.CallTree()
+
+	failed := tree.FailedCall()
+	if failed == nil || failed.ProgramId != v4 || failed.OuterIndex != 2 || failed.InnerIndex != 0 {
+		t.Fatalf("expected the raydium CPI to be the failing call, got %+v", failed)
+	}
+	if failed.Error != "custom program error: 0x1e" || len(failed.Logs) != 1 {
+		t.Errorf("unexpected failure details: %+v", failed)
+	}
+	if tree.Roots[0].Status != types.CallStatusFailed {
+		t.Errorf("router must fail too, got %s", tree.Roots[0].Status)
+	}
+
+	truncated := adapter.NewTransactionAdapter(newRoutedTransaction(append(logs[:3:3], "Log truncated")), &types.ParseConfig{}).CallTree()
+	if !truncated.Truncated || truncated.Roots[0].Status != types.CallStatusIncomplete || truncated.Roots[0].Children[0].Status != types.CallStatusIncomplete {
+		t.Errorf("unexpected truncated tree: %+v", truncated.Roots[0])
+	}
+}
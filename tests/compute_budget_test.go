@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/binary"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/mr-tron/base58"
+)
+
+// computeBudgetData encodes a ComputeBudget instruction with a u32 or u64 argument
+func computeBudgetData(kind byte, value uint64, size int) string {
+	data := make([]byte, 1+size)
+	data[0] = kind
+	if size == 4 {
+		binary.LittleEndian.PutUint32(data[1:], uint32(value))
+	} else {
+		binary.LittleEndian.PutUint64(data[1:], value)
+	}
+	return base58.Encode(data)
+}
+
+// newComputeBudgetTransaction builds a transaction with the given ComputeBudget instructions before one router instruction
+func newComputeBudgetTransaction(fee uint64, budget ...adapter.CompiledInstruction) *adapter.SolanaTransaction {
+	instructions := append(budget, adapter.CompiledInstruction{ProgramIdIndex: 2, Accounts: []int{0}, Data: base58.Encode([]byte{1})})
+	tx := newTestTransaction("cb_signature", []string{testPubkey(9), constants.COMPUTE_BUDGET_PROGRAM_ID, logRouter}, instructions...)
+	tx.Slot = 13
+	tx.Meta = &adapter.TransactionMeta{Fee: fee}
+	return tx
+}
+
+func TestComputeBudget(t *testing.T) {
+	tx := newComputeBudgetTransaction(5000+30001,
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: computeBudgetData(constants.ComputeBudgetSetComputeUnitLimit, 300_000, 4)},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: computeBudgetData(constants.ComputeBudgetSetComputeUnitPrice, 100_001, 8)},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: computeBudgetData(constants.ComputeBudgetRequestHeapFrame, 256*1024, 4)},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: computeBudgetData(constants.ComputeBudgetSetLoadedAccountsDataSizeLimit, 64*1024, 4)},
+	)
+	result := dexparser.NewDexParser().ParseAll(tx, nil)
+
+	budget := result.ComputeBudget
+	if budget == nil || budget.UnitLimit != 300_000 || budget.DefaultUnitLimit || budget.UnitPrice != 100_001 {
+		t.Fatalf("unexpected compute budget: %+v", budget)
+	}
+	// ceil(100001 * 300000 / 1e6) = ceil(30000.3)
+	if budget.PriorityFee != 30001 || budget.HeapFrameBytes != 256*1024 || budget.LoadedAccountsDataSizeLimit != 64*1024 {
+		t.Errorf("unexpected compute budget: %+v", budget)
+	}
+	if result.Fee.Amount != "35001" || result.BaseFee.Amount != "5000" || result.PriorityFee.Amount != "30001" {
+		t.Errorf("unexpected fee split: %s = %s + %s", result.Fee.Amount, result.BaseFee.Amount, result.PriorityFee.Amount)
+	}
+	if result.PriorityFee.UIAmount == nil || *result.PriorityFee.UIAmount != 0.000030001 {
+		t.Errorf("unexpected priority fee ui amount: %v", result.PriorityFee.UIAmount)
+	}
+}
+
+func TestComputeBudgetDefaults(t *testing.T) {
+	// Price without a limit uses 200k CU per non-builtin instruction and 3k CU per builtin one,
+	// the ComputeBudget instruction included
+	tx := newComputeBudgetTransaction(5000+2030,
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: computeBudgetData(constants.ComputeBudgetSetComputeUnitPrice, 10_000, 8)},
+	)
+	result := dexparser.NewDexParser().ParseAll(tx, nil)
+	if budget := result.ComputeBudget; budget == nil || budget.UnitLimit != 203_000 || !budget.DefaultUnitLimit || budget.PriorityFee != 2030 {
+		t.Errorf("unexpected default budget: %+v", budget)
+	}
+
+	// A System transfer is a builtin instruction too
+	msg := &tx.Transaction.Message
+	msg.StaticAccountKeys = append(msg.StaticAccountKeys, constants.SYSTEM_PROGRAM_ID)
+	msg.CompiledInstructions = append(msg.CompiledInstructions, adapter.CompiledInstruction{ProgramIdIndex: 3, Accounts: []int{0, 0}, Data: systemTransferData(1)})
+	if budget := dexparser.NewDexParser().ParseAll(tx, nil).ComputeBudget; budget == nil || budget.UnitLimit != 206_000 {
+		t.Errorf("unexpected default budget with a builtin: %+v", budget)
+	}
+
+	result = dexparser.NewDexParser().ParseAll(newComputeBudgetTransaction(5000), nil)
+	if result.ComputeBudget.PriorityFee != 0 || result.BaseFee.Amount != "5000" || result.PriorityFee.Amount != "0" {
+		t.Errorf("expected no priority fee, got %+v", result.ComputeBudget)
+	}
+}
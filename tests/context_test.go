@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/fetcher"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+type ctxKey string
+
+func TestParseAllContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := dexparser.NewDexParser().ParseAllContext(ctx, newShredTransferTransaction(), nil)
+	if !result.Skipped || result.State {
+		t.Errorf("Expected skipped, failed result, got skipped=%v state=%v", result.Skipped, result.State)
+	}
+	if result.Signature != "shred_signature" {
+		t.Errorf("Expected signature to be kept, got %q", result.Signature)
+	}
+	if !strings.Contains(result.Msg, context.Canceled.Error()) {
+		t.Errorf("Expected cancellation message, got %q", result.Msg)
+	}
+
+	shred := dexparser.NewShredParser().ParseAllContext(ctx, newShredTransferTransaction(), nil)
+	if !shred.Skipped || shred.State {
+		t.Errorf("Expected skipped shred result, got skipped=%v state=%v", shred.Skipped, shred.State)
+	}
+}
+
+func TestParseAllContextPassesContextToFetchers(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey("request"), "req-1")
+	var seen any
+	config := &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcherContext(types.FetchFilterAll, func(ctx context.Context, keys []string) ([]*types.TokenAccountInfo, error) {
+			seen = ctx.Value(ctxKey("request"))
+			return make([]*types.TokenAccountInfo, len(keys)), nil
+		}),
+	}
+
+	result := dexparser.NewDexParser().ParseAllContext(ctx, newShredTransferTransaction(), config)
+	if result.Skipped || !result.State {
+		t.Errorf("Expected parsed result, got skipped=%v msg=%q", result.Skipped, result.Msg)
+	}
+	if seen != "req-1" {
+		t.Errorf("Expected fetcher to receive request context, got %v", seen)
+	}
+}
+
+func TestParseBatchContextStopsScheduling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	config := &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcherContext(types.FetchFilterAll, func(ctx context.Context, keys []string) ([]*types.TokenAccountInfo, error) {
+			calls++
+			cancel()
+			return nil, ctx.Err()
+		}),
+	}
+	txs := []*adapter.SolanaTransaction{newShredTransferTransaction(), newShredTransferTransaction(), newShredTransferTransaction()}
+
+	results := dexparser.NewDexParser().ParseBatchContext(ctx, txs, config, 1)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Skipped {
+		t.Error("Expected first transaction to be parsed")
+	}
+	for i := 1; i < 3; i++ {
+		if results[i] == nil || !results[i].Skipped {
+			t.Errorf("Expected result %d to be skipped, got %+v", i, results[i])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single fetch before cancellation, got %d", calls)
+	}
+}
+
+func TestParseBatchContextConcurrent(t *testing.T) {
+	parser := dexparser.NewDexParser()
+	txs := make([]*adapter.SolanaTransaction, 20)
+	for i := range txs {
+		txs[i] = newShredTransferTransaction()
+	}
+
+	results := parser.ParseBatchContext(context.Background(), txs, nil, 4)
+	for i, result := range results {
+		if result == nil || result.Skipped || !result.State {
+			t.Errorf("Expected result %d to be parsed, got %+v", i, result)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = parser.ParseBatchContext(ctx, txs, nil, 4)
+	for i, result := range results {
+		if result == nil || !result.Skipped {
+			t.Errorf("Expected result %d to be skipped after cancellation", i)
+		}
+	}
+
+	// Results not started after the callback stops the batch are skipped, never nil
+	stopped := 0
+	results = parser.ParseBatchWithCallbackContext(context.Background(), txs, nil, 4, func(int, *adapter.SolanaTransaction, *types.ParseResult, error) bool {
+		stopped++
+		return false
+	})
+	skipped := 0
+	for i, result := range results {
+		if result == nil {
+			t.Fatalf("Result %d is nil", i)
+		}
+		if result.Skipped {
+			skipped++
+		}
+	}
+	if stopped != 1 || skipped == 0 {
+		t.Errorf("Expected one callback and skipped results, got %d callbacks, %d skipped", stopped, skipped)
+	}
+}
+
+func TestCachedFetcherContextDeadline(t *testing.T) {
+	inner := types.NewPoolInfoFetcher(types.FetchFilterAll, func(keys []string) ([]*types.PoolInfo, error) {
+		return make([]*types.PoolInfo, len(keys)), nil
+	})
+	cached := fetcher.NewCachedPoolInfoFetcher(inner, fetcher.Config{Size: 10, BatchWindow: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cached.FetchContext(ctx, []string{"pool"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
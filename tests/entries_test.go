@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/goccy/go-json"
+	"github.com/mr-tron/base58"
+)
+
+// encodeEntries serializes a bincode Vec<Entry>; an entry without transactions is a tick
+func encodeEntries(entries ...[][]byte) []byte {
+	var buf bytes.Buffer
+	u64 := func(v uint64) { buf.Write(binary.LittleEndian.AppendUint64(nil, v)) }
+	u64(uint64(len(entries)))
+	for i, txs := range entries {
+		u64(12500)
+		buf.Write(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		u64(uint64(len(txs)))
+		for _, tx := range txs {
+			buf.Write(tx)
+		}
+	}
+	return buf.Bytes()
+}
+
+// legacyTransferWire serializes a legacy transaction with two signers, testPubkey(1) and
+// testPubkey(2), whose only instruction transfers lamports to testPubkey(3)
+func legacyTransferWire(lamports uint64) []byte {
+	keys := []string{testPubkey(1), testPubkey(2), testPubkey(3), constants.SYSTEM_PROGRAM_ID}
+	var buf bytes.Buffer
+	buf.Write(shortVec(2))
+	buf.Write(bytes.Repeat([]byte{7}, 128))
+	buf.Write([]byte{2, 1, 1})
+	buf.Write(shortVec(len(keys)))
+	for _, key := range keys {
+		b, _ := base58.Decode(key)
+		buf.Write(b)
+	}
+	buf.Write(make([]byte, 32))
+	buf.Write(shortVec(1))
+	buf.Write([]byte{3, 2, 0, 2})
+	data, _ := base58.Decode(systemTransferData(lamports))
+	buf.Write(shortVec(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestParseEntries(t *testing.T) {
+	var rpcTx adapter.SolanaTransaction
+	if err := json.Unmarshal([]byte(recordedRPCTransaction), &rpcTx); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	router := "9SJA6YxVUHf93TznsHnsw5AVBWGwteYZTjmuH9raVuKX"
+	data := encodeEntries(nil, [][]byte{encodeWire(t, &rpcTx), legacyTransferWire(1000)}, nil)
+
+	entries, err := dexparser.DecodeEntries(data, 42)
+	if err != nil {
+		t.Fatalf("DecodeEntries: %v", err)
+	}
+	if len(entries) != 3 || len(entries[0].Transactions) != 0 || len(entries[1].Transactions) != 2 || entries[1].NumHashes != 12500 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if tx := entries[1].Transactions[0]; tx.Slot != 42 || tx.Meta != nil || tx.Transaction.Signatures[0] != rpcTx.Transaction.Signatures[0] {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+
+	parser := dexparser.NewShredParser(dexparser.WithShredParser(router, stubShredFactory(router)))
+	message := (&pb{}).uint(1, 42).raw(2, data).Bytes()
+	results, err := parser.ParseShredstreamEntry(message, nil)
+	if err != nil {
+		t.Fatalf("ParseShredstreamEntry: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected a result per transaction, got %d", len(results))
+	}
+	routed := results[0]
+	if !routed.State || routed.Slot != 42 || routed.Signature != rpcTx.Transaction.Signatures[0] || len(routed.ParsedInstructions) != 1 || routed.ParsedInstructions[0].Idx != "2" {
+		t.Errorf("unexpected routed result: %+v", routed)
+	}
+	// Lookup accounts are unknown without meta or an ALTs fetcher
+	if accounts := routed.ParsedInstructions[0].Accounts; len(accounts) != 5 || accounts[0] != rpcTx.Transaction.Message.AccountKeys[0].Pubkey || accounts[1] != "" {
+		t.Errorf("unexpected accounts: %v", accounts)
+	}
+	if transfer := results[1]; !transfer.State || len(transfer.Signer) != 2 || len(transfer.ParsedInstructions) != 0 {
+		t.Errorf("unexpected transfer result: %+v", transfer)
+	}
+
+	bad := [][]byte{
+		nil,
+		data[:len(data)-1],
+		append(append([]byte{}, data...), 0),
+		binary.LittleEndian.AppendUint64(nil, 1<<40),
+	}
+	for _, b := range bad {
+		if _, err := parser.ParseEntries(b, 42, nil); err == nil {
+			t.Errorf("expected error for %d bytes", len(b))
+		}
+	}
+	if _, err := parser.ParseShredstreamEntry([]byte{0x08}, nil); err == nil {
+		t.Error("expected error for a truncated Entry message")
+	}
+}
+
+// TestShredstreamRecordedEntries parses captured ShredStream messages. Each
+// testdata/shredstream/<name>.bin holds one serialized Entry message as received from
+// SubscribeEntries.
+func TestShredstreamRecordedEntries(t *testing.T) {
+	messages, err := filepath.Glob("testdata/shredstream/*.bin")
+	if err != nil || len(messages) == 0 {
+		t.Skip("No recorded ShredStream entries at testdata/shredstream")
+	}
+
+	parser := dexparser.NewShredParser()
+	for _, message := range messages {
+		t.Run(filepath.Base(message), func(t *testing.T) {
+			data, err := os.ReadFile(message)
+			if err != nil {
+				t.Fatalf("read entry: %v", err)
+			}
+			slot, payload, err := dexparser.DecodeShredstreamEntry(data)
+			if err != nil {
+				t.Fatalf("DecodeShredstreamEntry: %v", err)
+			}
+			entries, err := dexparser.DecodeEntries(payload, slot)
+			if err != nil {
+				t.Fatalf("DecodeEntries: %v", err)
+			}
+			var signatures []string
+			for _, entry := range entries {
+				for _, tx := range entry.Transactions {
+					if len(tx.Transaction.Signatures) == 0 {
+						t.Fatalf("transaction without signatures in slot %d", slot)
+					}
+					signatures = append(signatures, tx.Transaction.Signatures[0])
+				}
+			}
+
+			results, err := parser.ParseShredstreamEntry(data, nil)
+			if err != nil {
+				t.Fatalf("ParseShredstreamEntry: %v", err)
+			}
+			if len(results) != len(signatures) {
+				t.Fatalf("expected %d results, got %d", len(signatures), len(results))
+			}
+			for i, result := range results {
+				if !result.State || result.Slot != slot || result.Signature != signatures[i] {
+					t.Errorf("result %d: unexpected %+v", i, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/fetcher"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// countingTokenAccountsFetcher returns a token accounts fetcher that records every requested batch
+func countingTokenAccountsFetcher(mu *sync.Mutex, batches *[][]string) *types.TokenAccountsFetcher {
+	return types.NewTokenAccountsFetcher(types.FetchFilterAll, func(keys []string) ([]*types.TokenAccountInfo, error) {
+		mu.Lock()
+		*batches = append(*batches, append([]string(nil), keys...))
+		mu.Unlock()
+		result := make([]*types.TokenAccountInfo, len(keys))
+		for i, key := range keys {
+			if key != "missing" {
+				result[i] = &types.TokenAccountInfo{Mint: "mint_" + key, Decimals: 6}
+			}
+		}
+		return result, nil
+	})
+}
+
+func TestCachedTokenAccountsFetcherCaches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	cached := fetcher.NewCachedTokenAccountsFetcher(countingTokenAccountsFetcher(&mu, &batches), fetcher.Config{Size: 10})
+
+	infos, err := cached.Fetch([]string{"a", "b", "a", "missing"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(infos) != 4 || infos[0].Mint != "mint_a" || infos[2].Mint != "mint_a" || infos[3] != nil {
+		t.Errorf("Unexpected aligned result: %+v", infos)
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("Expected one de-duplicated batch of 3 keys, got %v", batches)
+	}
+
+	infos, _ = cached.Fetch([]string{"b", "c"})
+	if infos[0].Mint != "mint_b" || infos[1].Mint != "mint_c" {
+		t.Errorf("Unexpected second result: %+v", infos)
+	}
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0] != "c" {
+		t.Errorf("Expected only the uncached key to be fetched, got %v", batches)
+	}
+
+	stats := cached.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Fetches != 2 || stats.Size != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachedFetcherLRUAndTTL(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	cached := fetcher.NewCachedTokenAccountsFetcher(countingTokenAccountsFetcher(&mu, &batches), fetcher.Config{Size: 2, TTL: 30 * time.Millisecond})
+
+	cached.Fetch([]string{"a", "b"})
+	cached.Fetch([]string{"a"}) // a becomes most recently used
+	cached.Fetch([]string{"c"}) // evicts b
+	if stats := cached.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("Expected 1 eviction and 2 entries, got %+v", stats)
+	}
+
+	cached.Fetch([]string{"a", "b"})
+	if last := batches[len(batches)-1]; len(last) != 1 || last[0] != "b" {
+		t.Errorf("Expected only evicted key b to be refetched, got %v", last)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	before := len(batches)
+	cached.Fetch([]string{"a"})
+	if len(batches) != before+1 {
+		t.Error("Expected expired key to be refetched")
+	}
+}
+
+func TestCachedFetcherBatchesConcurrentCallers(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	inner := countingTokenAccountsFetcher(&mu, &batches)
+	cached := fetcher.NewCachedTokenAccountsFetcher(inner, fetcher.Config{Size: 100, BatchWindow: 100 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	var failures atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys := []string{"shared", string(rune('a' + i))}
+			infos, err := cached.Fetch(keys)
+			if err != nil || infos[0] == nil || infos[1] == nil || infos[1].Mint != "mint_"+keys[1] {
+				failures.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if failures.Load() != 0 {
+		t.Errorf("%d callers got wrong results", failures.Load())
+	}
+	if len(batches) != 1 || len(batches[0]) != 11 {
+		t.Errorf("Expected a single batch of 11 keys, got %d batches: %v", len(batches), batches)
+	}
+	if stats := cached.Stats(); stats.Shared != 9 {
+		t.Errorf("Expected 9 shared misses, got %+v", stats)
+	}
+}
+
+func TestCachedFetcherMaxBatchSize(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	cached := fetcher.NewCachedTokenAccountsFetcher(countingTokenAccountsFetcher(&mu, &batches), fetcher.Config{
+		Size:         100,
+		BatchWindow:  time.Hour,
+		MaxBatchSize: 2,
+	})
+
+	infos, err := cached.Fetch([]string{"a", "b", "c", "d"})
+	if err != nil || len(infos) != 4 || infos[3] == nil {
+		t.Fatalf("Unexpected result: %+v, %v", infos, err)
+	}
+	if len(batches) != 2 {
+		t.Errorf("Expected 2 full batches, got %v", batches)
+	}
+}
+
+func TestCachedFetcherErrorsNotCached(t *testing.T) {
+	calls := 0
+	inner := types.NewPoolInfoFetcher(types.FetchFilterAll, func(keys []string) ([]*types.PoolInfo, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("rpc unavailable")
+		}
+		return []*types.PoolInfo{{PoolId: keys[0]}}, nil
+	})
+	cached := fetcher.NewCachedPoolInfoFetcher(inner, fetcher.DefaultConfig())
+
+	if _, err := cached.Fetch([]string{"pool"}); err == nil {
+		t.Error("Expected error from first fetch")
+	}
+	infos, err := cached.Fetch([]string{"pool"})
+	if err != nil || infos[0] == nil || infos[0].PoolId != "pool" {
+		t.Errorf("Expected retry to succeed, got %+v, %v", infos, err)
+	}
+	if stats := cached.Stats(); stats.Errors != 1 || stats.Fetches != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachedFetcherNegativeTTL(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	cached := fetcher.NewCachedTokenAccountsFetcher(countingTokenAccountsFetcher(&mu, &batches), fetcher.Config{Size: 10, NegativeTTL: 30 * time.Millisecond})
+
+	cached.Fetch([]string{"missing"})
+	if infos, err := cached.Fetch([]string{"missing"}); err != nil || infos[0] != nil {
+		t.Errorf("Expected a cached miss, got %+v, %v", infos, err)
+	}
+	if len(batches) != 1 {
+		t.Errorf("Expected the missing key to be fetched once, got %v", batches)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cached.Fetch([]string{"missing"})
+	if len(batches) != 2 {
+		t.Errorf("Expected the expired miss to be refetched, got %v", batches)
+	}
+}
+
+func TestCachedFetcherAbandonedFetch(t *testing.T) {
+	var calls atomic.Int32
+	cancelled := make(chan error, 1)
+	inner := types.NewTokenAccountsFetcherContext(types.FetchFilterAll, func(ctx context.Context, keys []string) ([]*types.TokenAccountInfo, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil, ctx.Err()
+		}
+		return []*types.TokenAccountInfo{{Mint: "mint_" + keys[0]}}, nil
+	})
+	cached := fetcher.NewCachedTokenAccountsFetcher(inner, fetcher.Config{Size: 10})
+
+	// The only caller gives up, so the hung fetch is cancelled and the key is released
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cached.FetchContext(ctx, []string{"a"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline, got %v", err)
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the fetch to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the abandoned fetch to be cancelled")
+	}
+	if infos, err := cached.Fetch([]string{"a"}); err != nil || infos[0] == nil || infos[0].Mint != "mint_a" {
+		t.Errorf("Expected a fresh fetch, got %+v, %v", infos, err)
+	}
+}
+
+func TestCachedFetcherSharedFetchOutlivesCaller(t *testing.T) {
+	release := make(chan struct{})
+	var fetchErr atomic.Value
+	inner := types.NewTokenAccountsFetcherContext(types.FetchFilterAll, func(ctx context.Context, keys []string) ([]*types.TokenAccountInfo, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			fetchErr.Store(ctx.Err())
+			return nil, ctx.Err()
+		}
+		return []*types.TokenAccountInfo{{Mint: "mint_" + keys[0]}}, nil
+	})
+	cached := fetcher.NewCachedTokenAccountsFetcher(inner, fetcher.Config{Size: 10, BatchWindow: 10 * time.Millisecond})
+
+	waiting := make(chan []*types.TokenAccountInfo)
+	go func() {
+		infos, _ := cached.Fetch([]string{"a"})
+		waiting <- infos
+	}()
+	for cached.Stats().Misses == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := cached.FetchContext(ctx, []string{"a"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline, got %v", err)
+	}
+	close(release)
+	if infos := <-waiting; infos[0] == nil || infos[0].Mint != "mint_a" {
+		t.Errorf("Expected the remaining caller to get the result, got %+v", infos)
+	}
+	if err := fetchErr.Load(); err != nil {
+		t.Errorf("Expected the shared fetch to keep running, got %v", err)
+	}
+}
+
+func TestCachedFetcherFetchTimeout(t *testing.T) {
+	inner := types.NewPoolInfoFetcherContext(types.FetchFilterAll, func(ctx context.Context, keys []string) ([]*types.PoolInfo, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cached := fetcher.NewCachedPoolInfoFetcher(inner, fetcher.Config{Size: 10, FetchTimeout: 20 * time.Millisecond})
+
+	if _, err := cached.Fetch([]string{"pool"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the fetch timeout, got %v", err)
+	}
+	if stats := cached.Stats(); stats.Errors != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachedALTsFetcher(t *testing.T) {
+	var requested [][]types.AddressTableLookup
+	inner := types.NewALTsFetcher(types.FetchFilterAll, func(alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+		requested = append(requested, alts)
+		result := make(map[string]*types.LoadedAddresses)
+		for _, lookup := range alts {
+			loaded := &types.LoadedAddresses{}
+			for _, index := range lookup.WritableIndexes {
+				loaded.Writable = append(loaded.Writable, lookup.AccountKey[len(lookup.AccountKey)-1:]+string(rune('0'+index)))
+			}
+			result[lookup.AccountKey] = loaded
+		}
+		return result, nil
+	})
+	cached := fetcher.NewCachedALTsFetcher(inner, fetcher.Config{Size: 100})
+	config := &types.ParseConfig{ALTsFetcher: cached.Fetcher()}
+
+	txAdapter := adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+	expected := []string{"signer", "program", "A3", "A1", "B2", "A0", "B5", "B6"}
+	for i, key := range expected {
+		if txAdapter.GetAccountKey(i) != key {
+			t.Errorf("AccountKeys[%d]: expected %s, got %s", i, key, txAdapter.GetAccountKey(i))
+		}
+	}
+
+	// The same lookups again are served entirely from cache
+	adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+	if len(requested) != 1 {
+		t.Errorf("Expected a single underlying fetch, got %d", len(requested))
+	}
+	if stats := cached.Stats(); stats.Hits != 6 || stats.Misses != 6 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
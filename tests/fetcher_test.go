@@ -3,7 +3,12 @@ package tests
 import (
 	"testing"
 
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
 	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
 )
 
 func TestFetchFilterType(t *testing.T) {
@@ -127,19 +132,15 @@ func TestTokenAccountsFetcher(t *testing.T) {
 }
 
 func TestPoolInfoFetcher(t *testing.T) {
-	type PoolData struct {
-		TokenAMint string
-		TokenBMint string
-	}
-
 	fetcher := types.NewPoolInfoFetcher(
 		types.FetchFilterAccount,
-		func(pools []string) ([]interface{}, error) {
-			result := make([]interface{}, len(pools))
-			for i := range pools {
-				result[i] = &PoolData{
-					TokenAMint: "tokenA",
-					TokenBMint: "tokenB",
+		func(pools []string) ([]*types.PoolInfo, error) {
+			result := make([]*types.PoolInfo, len(pools))
+			for i, pool := range pools {
+				result[i] = &types.PoolInfo{
+					PoolId:    pool,
+					BaseMint:  "tokenA",
+					QuoteMint: "tokenB",
 				}
 			}
 			return result, nil
@@ -155,14 +156,13 @@ func TestPoolInfoFetcher(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 	if len(result) != 1 {
-		t.Errorf("Expected 1 result, got %d", len(result))
+		t.Fatalf("Expected 1 result, got %d", len(result))
 	}
-	poolData, ok := result[0].(*PoolData)
-	if !ok {
-		t.Error("Expected PoolData type")
+	if result[0].BaseMint != "tokenA" {
+		t.Errorf("Expected BaseMint 'tokenA', got '%s'", result[0].BaseMint)
 	}
-	if poolData.TokenAMint != "tokenA" {
-		t.Errorf("Expected TokenAMint 'tokenA', got '%s'", poolData.TokenAMint)
+	if other := result[0].OtherMint("tokenA"); other != "tokenB" {
+		t.Errorf("Expected OtherMint 'tokenB', got '%s'", other)
 	}
 }
 
@@ -181,3 +181,317 @@ func TestTokenAccountInfo(t *testing.T) {
 		t.Errorf("Expected 9 decimals, got %d", info.Decimals)
 	}
 }
+
+// newV0LookupTransaction builds a v0 transaction whose accounts past the static keys come from two ALTs
+func newV0LookupTransaction() *adapter.SolanaTransaction {
+	tx := newTestTransaction("alt_signature", []string{"signer", "program"})
+	tx.Transaction.Message.AddressTableLookups = []adapter.AddressTableLookup{
+		{AccountKey: "tableA", WritableIndexes: []int{3, 1}, ReadonlyIndexes: []int{0}},
+		{AccountKey: "tableB", WritableIndexes: []int{2}, ReadonlyIndexes: []int{5, 6}},
+	}
+	tx.Meta = &adapter.TransactionMeta{}
+	return tx
+}
+
+func TestALTsFetcherResolvesAccountKeys(t *testing.T) {
+	calls := 0
+	config := &types.ParseConfig{
+		ALTsFetcher: types.NewALTsFetcher(types.FetchFilterAll, func(alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+			calls++
+			if len(alts) != 2 {
+				t.Errorf("Expected 2 lookups, got %d", len(alts))
+			}
+			return map[string]*types.LoadedAddresses{
+				"tableA": {Writable: []string{"a3", "a1"}, Readonly: []string{"a0"}},
+				"tableB": {Writable: []string{"b2"}, Readonly: []string{"b5", "b6"}},
+			}, nil
+		}),
+	}
+
+	txAdapter := adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+
+	expected := []string{"signer", "program", "a3", "a1", "b2", "a0", "b5", "b6"}
+	if len(txAdapter.AccountKeys) != len(expected) {
+		t.Fatalf("Expected %d account keys, got %v", len(expected), txAdapter.AccountKeys)
+	}
+	for i, key := range expected {
+		if txAdapter.AccountKeys[i] != key {
+			t.Errorf("AccountKeys[%d]: expected %s, got %s", i, key, txAdapter.AccountKeys[i])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected fetcher to be called once, got %d", calls)
+	}
+}
+
+func TestALTsFetcherPartialResponse(t *testing.T) {
+	config := &types.ParseConfig{
+		ALTsFetcher: types.NewALTsFetcher(types.FetchFilterAll, func(alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+			return map[string]*types.LoadedAddresses{
+				"tableB": {Writable: []string{"b2"}, Readonly: []string{"b5", "b6"}},
+			}, nil
+		}),
+	}
+
+	txAdapter := adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+
+	// tableA is unresolved, so its slots stay empty and tableB keeps its positions
+	if got := txAdapter.GetAccountKey(4); got != "b2" {
+		t.Errorf("Expected b2 at index 4, got %q", got)
+	}
+	if got := txAdapter.GetAccountKey(7); got != "b6" {
+		t.Errorf("Expected b6 at index 7, got %q", got)
+	}
+	if got := txAdapter.GetAccountKey(2); got != "" {
+		t.Errorf("Expected empty key at index 2, got %q", got)
+	}
+}
+
+func TestALTsFetcherFilter(t *testing.T) {
+	calls := 0
+	fetch := func(alts []types.AddressTableLookup) (map[string]*types.LoadedAddresses, error) {
+		calls++
+		return nil, nil
+	}
+
+	// Program filter without a matching program must not call the fetcher
+	config := &types.ParseConfig{
+		ProgramIds:  []string{"otherProgram"},
+		ALTsFetcher: types.NewALTsFetcher(types.FetchFilterProgram, fetch),
+	}
+	adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+	if calls != 0 {
+		t.Errorf("Expected no fetch for non-matching program filter, got %d", calls)
+	}
+
+	config.ProgramIds = []string{"program"}
+	adapter.NewTransactionAdapter(newV0LookupTransaction(), config)
+	if calls != 1 {
+		t.Errorf("Expected one fetch for matching program filter, got %d", calls)
+	}
+
+	// Meta-provided loaded addresses take precedence over the fetcher
+	tx := newV0LookupTransaction()
+	tx.Meta.LoadedAddresses = &adapter.LoadedAddresses{Writable: []string{"w"}, Readonly: []string{"r"}}
+	config.ALTsFetcher.Filter = types.FetchFilterAll
+	adapter.NewTransactionAdapter(tx, config)
+	if calls != 1 {
+		t.Errorf("Expected no fetch when meta has loaded addresses, got %d", calls)
+	}
+}
+
+// newShredTransferTransaction builds a meta-less transaction with a single compiled SPL transfer
+func newShredTransferTransaction() *adapter.SolanaTransaction {
+	data := make([]byte, 9)
+	data[0] = 3 // Transfer
+	data[1] = 0x40
+	data[2] = 0x42
+	data[3] = 0x0f // 1_000_000
+
+	return newTestTransaction("shred_signature", []string{"signer", "sourceAta", "destAta", constants.TOKEN_PROGRAM_ID},
+		adapter.CompiledInstruction{ProgramIdIndex: 3, Accounts: []int{1, 2, 0}, Data: base58.Encode(data)},
+	)
+}
+
+func TestTokenAccountsFetcherResolvesMints(t *testing.T) {
+	var requested [][]string
+	config := &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcher(types.FetchFilterAll, func(keys []string) ([]*types.TokenAccountInfo, error) {
+			requested = append(requested, keys)
+			result := make([]*types.TokenAccountInfo, len(keys))
+			for i, key := range keys {
+				if key == "destAta" {
+					result[i] = &types.TokenAccountInfo{Mint: constants.TOKENS.USDC, Owner: "receiver", Decimals: 6}
+				}
+			}
+			return result, nil
+		}),
+	}
+
+	txAdapter := adapter.NewTransactionAdapter(newShredTransferTransaction(), config)
+
+	if len(requested) != 1 {
+		t.Fatalf("Expected fetcher to be called once, got %d", len(requested))
+	}
+	if len(requested[0]) != 2 || requested[0][0] != "sourceAta" || requested[0][1] != "destAta" {
+		t.Errorf("Unexpected requested accounts: %v", requested[0])
+	}
+	if mint := txAdapter.GetSplTokenMint("destAta"); mint != constants.TOKENS.USDC {
+		t.Errorf("Expected USDC mint for destAta, got %s", mint)
+	}
+	if owner := txAdapter.GetTokenAccountOwner("destAta"); owner != "receiver" {
+		t.Errorf("Expected owner 'receiver', got '%s'", owner)
+	}
+	// Unresolved accounts keep the legacy SOL default
+	if mint := txAdapter.GetSplTokenMint("sourceAta"); mint != constants.TOKENS.SOL {
+		t.Errorf("Expected SOL fallback for sourceAta, got %s", mint)
+	}
+
+	transfers := dexparser.NewDexParser().ParseTransfers(newShredTransferTransaction(), config)
+	if len(transfers) != 1 {
+		t.Fatalf("Expected 1 transfer, got %d", len(transfers))
+	}
+	if transfers[0].Info.Mint != constants.TOKENS.USDC || transfers[0].Info.TokenAmount.Decimals != 6 {
+		t.Errorf("Expected USDC transfer with 6 decimals, got %s/%d", transfers[0].Info.Mint, transfers[0].Info.TokenAmount.Decimals)
+	}
+}
+
+func TestTokenAccountsFetcherZeroDecimals(t *testing.T) {
+	mint := testPubkey(7)
+	config := &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcher(types.FetchFilterAll, func(keys []string) ([]*types.TokenAccountInfo, error) {
+			result := make([]*types.TokenAccountInfo, len(keys))
+			for i := range keys {
+				result[i] = &types.TokenAccountInfo{Mint: mint, Owner: "owner", Decimals: 0}
+			}
+			return result, nil
+		}),
+	}
+
+	txAdapter := adapter.NewTransactionAdapter(newShredTransferTransaction(), config)
+	if info := txAdapter.SPLTokenMap["destAta"]; info.Mint != mint || info.Decimals != 0 {
+		t.Errorf("Expected the fetched mint with 0 decimals for destAta, got %s/%d", info.Mint, info.Decimals)
+	}
+	if decimals, ok := txAdapter.SPLDecimalsMap[mint]; !ok || decimals != 0 {
+		t.Errorf("Expected 0 decimals recorded for the fetched mint, got %d %v", decimals, ok)
+	}
+
+	transfers := dexparser.NewDexParser().ParseTransfers(newShredTransferTransaction(), config)
+	if len(transfers) != 1 {
+		t.Fatalf("Expected 1 transfer, got %d", len(transfers))
+	}
+	amount := transfers[0].Info.TokenAmount
+	if transfers[0].Info.Mint != mint || amount.Decimals != 0 || amount.UIAmount == nil || *amount.UIAmount != 1_000_000 {
+		t.Errorf("Expected an unscaled 0-decimal transfer, got %s %+v", transfers[0].Info.Mint, amount)
+	}
+}
+
+func TestTokenAccountsFetcherFilter(t *testing.T) {
+	calls := 0
+	config := &types.ParseConfig{
+		AccountInclude: []string{"someoneElse"},
+		TokenAccountsFetcher: types.NewTokenAccountsFetcher(types.FetchFilterAccount, func(keys []string) ([]*types.TokenAccountInfo, error) {
+			calls++
+			return nil, nil
+		}),
+	}
+
+	txAdapter := adapter.NewTransactionAdapter(newShredTransferTransaction(), config)
+	if calls != 0 {
+		t.Errorf("Expected no fetch for non-matching account filter, got %d", calls)
+	}
+	if mint := txAdapter.GetSplTokenMint("destAta"); mint != constants.TOKENS.SOL {
+		t.Errorf("Expected SOL fallback without fetcher, got %s", mint)
+	}
+}
+
+// stubTradeParser returns fixed trades for pool enrichment tests
+type stubTradeParser struct{ trades []types.TradeInfo }
+
+func (p *stubTradeParser) ProcessTrades() []types.TradeInfo { return p.trades }
+
+// stubLiquidityParser returns fixed pool events for pool enrichment tests
+type stubLiquidityParser struct{ events []types.PoolEvent }
+
+func (p *stubLiquidityParser) ProcessLiquidity() []types.PoolEvent { return p.events }
+
+// newPoolProgramTransaction builds a meta-less transaction with one instruction of the given program
+func newPoolProgramTransaction(programId string) *adapter.SolanaTransaction {
+	return newTestTransaction("pool_signature", []string{"signer", "pool1", programId},
+		adapter.CompiledInstruction{ProgramIdIndex: 2, Accounts: []int{0, 1}, Data: base58.Encode([]byte{1})},
+	)
+}
+
+func newTestPoolInfoFetcher(calls *int) *types.PoolInfoFetcher {
+	return types.NewPoolInfoFetcher(types.FetchFilterAll, func(pools []string) ([]*types.PoolInfo, error) {
+		*calls++
+		result := make([]*types.PoolInfo, len(pools))
+		for i, pool := range pools {
+			if pool == "pool1" {
+				result[i] = &types.PoolInfo{
+					PoolId:        pool,
+					AMM:           "TestAMM",
+					BaseMint:      "memeMint",
+					QuoteMint:     constants.TOKENS.SOL,
+					BaseDecimals:  6,
+					QuoteDecimals: 9,
+					LpMint:        "lpMint",
+				}
+			}
+		}
+		return result, nil
+	})
+}
+
+func TestPoolInfoFetcherEnrichesTrades(t *testing.T) {
+	const programId = "TestPoo1Program111111111111111111111111111"
+	parser := dexparser.NewDexParser()
+	parser.RegisterTradeParser(programId, func(a *adapter.TransactionAdapter, d types.DexInfo, _ map[string][]types.TransferData, _ []types.ClassifiedInstruction) parsers.TradeParser {
+		return &stubTradeParser{trades: []types.TradeInfo{{
+			Pool:        []string{"pool1", "unknownPool"},
+			InputToken:  types.TokenInfo{Mint: constants.TOKENS.SOL, AmountRaw: "1000000000", Amount: 1, Decimals: 9},
+			OutputToken: types.TokenInfo{AmountRaw: "2500000"},
+			ProgramId:   programId,
+			Idx:         "0",
+		}}}
+	})
+
+	calls := 0
+	config := &types.ParseConfig{PoolInfoFetcher: newTestPoolInfoFetcher(&calls)}
+	trades := parser.ParseTrades(newPoolProgramTransaction(programId), config)
+
+	if calls != 1 {
+		t.Errorf("Expected pool info fetcher to be called once, got %d", calls)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(trades))
+	}
+	trade := trades[0]
+	if trade.OutputToken.Mint != "memeMint" {
+		t.Errorf("Expected output mint 'memeMint', got '%s'", trade.OutputToken.Mint)
+	}
+	if trade.OutputToken.Decimals != 6 || trade.OutputToken.Amount != 2.5 {
+		t.Errorf("Expected 2.5 output with 6 decimals, got %v/%d", trade.OutputToken.Amount, trade.OutputToken.Decimals)
+	}
+	if trade.Type != types.TradeTypeBuy {
+		t.Errorf("Expected BUY, got %s", trade.Type)
+	}
+}
+
+func TestPoolInfoFetcherOrientsPoolEvents(t *testing.T) {
+	const programId = "TestPoo1Program111111111111111111111111111"
+	parser := dexparser.NewDexParser()
+	parser.RegisterLiquidityParser(programId, func(a *adapter.TransactionAdapter, _ map[string][]types.TransferData, _ []types.ClassifiedInstruction) parsers.LiquidityParser {
+		return &stubLiquidityParser{events: []types.PoolEvent{{
+			PoolEventBase:   types.PoolEventBase{Type: types.PoolEventTypeAdd, User: "signer"},
+			PoolId:          "pool1",
+			Token0Mint:      constants.TOKENS.SOL,
+			Token0AmountRaw: "2000000000",
+			Token1AmountRaw: "5000000",
+		}}}
+	})
+
+	calls := 0
+	config := &types.ParseConfig{PoolInfoFetcher: newTestPoolInfoFetcher(&calls)}
+	events := parser.ParseLiquidity(newPoolProgramTransaction(programId), config)
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 pool event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Token0Mint != "memeMint" || event.Token1Mint != constants.TOKENS.SOL {
+		t.Errorf("Expected memeMint/SOL orientation, got %s/%s", event.Token0Mint, event.Token1Mint)
+	}
+	if event.Token0AmountRaw != "5000000" || event.Token1AmountRaw != "2000000000" {
+		t.Errorf("Expected raw amounts to follow their mints, got %s/%s", event.Token0AmountRaw, event.Token1AmountRaw)
+	}
+	if event.Token0Decimals == nil || *event.Token0Decimals != 6 || event.Token0Amount == nil || *event.Token0Amount != 5 {
+		t.Errorf("Expected token0 amount 5 with 6 decimals, got %v/%v", event.Token0Amount, event.Token0Decimals)
+	}
+	if event.Token1Decimals == nil || *event.Token1Decimals != 9 || event.Token1Amount == nil || *event.Token1Amount != 2 {
+		t.Errorf("Expected token1 amount 2 with 9 decimals, got %v/%v", event.Token1Amount, event.Token1Decimals)
+	}
+	if event.PoolLpMint != "lpMint" || event.AMM != "TestAMM" {
+		t.Errorf("Expected LP mint and AMM from pool info, got %s/%s", event.PoolLpMint, event.AMM)
+	}
+}
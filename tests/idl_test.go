@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/idl"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+const launchpadProgram = "LaunchPad1111111111111111111111111111111111"
+
+// launchpadIDL is a minimal IDL in the current (0.30+) Anchor format
+const launchpadIDL = `{
+  "address": "LaunchPad1111111111111111111111111111111111",
+  "metadata": {"name": "launchpad", "version": "0.1.0", "spec": "0.1.0"},
+  "instructions": [{
+    "name": "buy",
+    "discriminator": [102, 6, 61, 18, 1, 218, 235, 234],
+    "accounts": [
+      {"name": "global"},
+      {"name": "pool_accounts", "accounts": [{"name": "mint"}, {"name": "bonding_curve"}]},
+      {"name": "user", "writable": true, "signer": true}
+    ],
+    "args": [{"name": "amount", "type": "u64"}, {"name": "max_sol_cost", "type": "u64"}]
+  }],
+  "events": [{"name": "TradeEvent", "discriminator": [189, 219, 127, 211, 78, 230, 97, 238]}],
+  "types": [
+    {"name": "Side", "type": {"kind": "enum", "variants": [{"name": "Buy"}, {"name": "Sell"}]}},
+    {"name": "Extra", "type": {"kind": "struct", "fields": [{"name": "tag", "type": "string"}, {"name": "weights", "type": {"array": ["u16", 2]}}]}},
+    {"name": "TradeEvent", "type": {"kind": "struct", "fields": [
+      {"name": "mint", "type": "pubkey"},
+      {"name": "sol_amount", "type": "u64"},
+      {"name": "token_amount", "type": "u64"},
+      {"name": "is_buy", "type": "bool"},
+      {"name": "user", "type": "pubkey"},
+      {"name": "virtual_reserves", "type": "u128"},
+      {"name": "referrer", "type": {"option": "pubkey"}},
+      {"name": "side", "type": {"defined": {"name": "Side"}}},
+      {"name": "extra", "type": {"defined": {"name": "Extra"}}},
+      {"name": "fees", "type": {"vec": "u64"}}
+    ]}}
+  ]
+}`
+
+// legacyIDL is a pre-0.30 IDL without explicit discriminators
+const legacyIDL = `{
+  "version": "0.1.0",
+  "name": "pump",
+  "instructions": [{"name": "buy", "accounts": [{"name": "global", "isMut": false, "isSigner": false}], "args": [{"name": "amount", "type": "u64"}]},
+                   {"name": "buyExactIn", "accounts": [], "args": []}],
+  "events": [{"name": "TradeEvent", "fields": [{"name": "mint", "type": "publicKey", "index": false}]}],
+  "metadata": {"address": "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P"}
+}`
+
+func testPubkey(b byte) string {
+	return base58.Encode(bytes.Repeat([]byte{b}, 32))
+}
+
+// borsh builds little-endian Borsh data
+type borsh struct{ bytes.Buffer }
+
+func (b *borsh) u64(v uint64) *borsh {
+	_ = binary.Write(&b.Buffer, binary.LittleEndian, v)
+	return b
+}
+
+func (b *borsh) u16(v uint16) *borsh {
+	_ = binary.Write(&b.Buffer, binary.LittleEndian, v)
+	return b
+}
+
+func (b *borsh) u32(v uint32) *borsh {
+	_ = binary.Write(&b.Buffer, binary.LittleEndian, v)
+	return b
+}
+
+func (b *borsh) raw(v ...byte) *borsh {
+	b.Write(v)
+	return b
+}
+
+func (b *borsh) pubkey(key string) *borsh {
+	decoded, _ := base58.Decode(key)
+	b.Write(decoded)
+	return b
+}
+
+func (b *borsh) str(s string) *borsh {
+	b.u32(uint32(len(s)))
+	b.WriteString(s)
+	return b
+}
+
+// tradeEventData encodes a TradeEvent wrapped in an emit_cpi! instruction
+func tradeEventData(isBuy bool, sol, token uint64) []byte {
+	b := &borsh{}
+	b.raw(idl.EventIxTag...).raw(idl.EventDiscriminator("TradeEvent")...)
+	b.pubkey(testPubkey(1)).u64(sol).u64(token)
+	if isBuy {
+		b.raw(1)
+	} else {
+		b.raw(0)
+	}
+	b.pubkey(testPubkey(9))
+	b.u64(5).u64(1) // u128 = 1<<64 + 5
+	b.raw(1).pubkey(testPubkey(7))
+	b.raw(1)                 // Side::Sell
+	b.str("x").u16(3).u16(4) // Extra
+	b.u32(2).u64(10).u64(20) // fees
+	return b.Bytes()
+}
+
+func newLaunchpadTransaction(isBuy bool) *adapter.SolanaTransaction {
+	buy := (&borsh{}).raw(idl.InstructionDiscriminator("buy")...).u64(1000).u64(2000).Bytes()
+	tx := newTestTransaction("idl_signature", []string{testPubkey(9), "global", testPubkey(1), "curve", launchpadProgram},
+		adapter.CompiledInstruction{ProgramIdIndex: 4, Accounts: []int{1, 2, 3, 0}, Data: base58.Encode(buy)},
+	)
+	tx.Slot = 11
+	tx.Meta = &adapter.TransactionMeta{
+		InnerInstructions: []adapter.InnerInstructionSet{{
+			Index: 0,
+			Instructions: []interface{}{
+				adapter.CompiledInstruction{ProgramIdIndex: 4, Accounts: []int{4}, Data: base58.Encode(tradeEventData(isBuy, 500_000_000, 2_000_000))},
+			},
+		}},
+		PostTokenBalances: []adapter.TokenBalance{{
+			AccountIndex:  3,
+			Mint:          testPubkey(1),
+			Owner:         testPubkey(9),
+			UiTokenAmount: types.TokenAmount{Amount: "2000000", Decimals: 6},
+		}},
+	}
+	return tx
+}
+
+func TestIDLDiscriminators(t *testing.T) {
+	parsed, err := idl.Parse([]byte(legacyIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Address != constants.DEX_PROGRAMS.PUMP_FUN.ID || parsed.Name != "pump" {
+		t.Errorf("unexpected metadata: %s %s", parsed.Address, parsed.Name)
+	}
+	if !bytes.Equal(parsed.Instruction("buy").Discriminator, constants.DISCRIMINATORS.PUMPFUN.BUY) {
+		t.Errorf("buy discriminator mismatch: %v", parsed.Instruction("buy").Discriminator)
+	}
+	if !bytes.Equal(parsed.Instruction("buyExactIn").Discriminator, idl.InstructionDiscriminator("buy_exact_in")) {
+		t.Error("legacy camelCase names must hash as snake_case")
+	}
+	if !bytes.Equal(append(append([]byte{}, idl.EventIxTag...), parsed.Event("TradeEvent").Discriminator...), constants.DISCRIMINATORS.PUMPFUN.TRADE_EVENT) {
+		t.Errorf("event discriminator mismatch: %v", parsed.Event("TradeEvent").Discriminator)
+	}
+	if parsed.Event("TradeEvent").Fields[0].Type.Kind != idl.KindPubkey {
+		t.Error("publicKey must normalize to pubkey")
+	}
+}
+
+func TestIDLDecodeEvent(t *testing.T) {
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := parsed.Instruction("buy").Accounts; len(got) != 4 || got[1] != "mint" || got[3] != "user" {
+		t.Errorf("expected flattened accounts, got %v", got)
+	}
+
+	decoded, err := parsed.DecodeEvent(tradeEventData(true, 42, 7))
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	v := decoded.Values
+	if decoded.Name != "TradeEvent" || v["sol_amount"] != uint64(42) || v["is_buy"] != true || v["mint"] != testPubkey(1) {
+		t.Errorf("unexpected values: %+v", v)
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 64)
+	want.Add(want, big.NewInt(5))
+	if got, ok := v["virtual_reserves"].(*big.Int); !ok || got.Cmp(want) != 0 {
+		t.Errorf("unexpected u128: %v", v["virtual_reserves"])
+	}
+	if v["referrer"] != testPubkey(7) || v["side"] != "Sell" {
+		t.Errorf("unexpected option/enum: %v %v", v["referrer"], v["side"])
+	}
+
+	var event struct {
+		Mint            string
+		SolAmount       uint64 `json:"sol_amount"`
+		TokenAmount     uint64
+		IsBuy           bool
+		VirtualReserves *big.Int
+		Referrer        *string
+		Side            string
+		Extra           struct {
+			Tag     string
+			Weights [2]uint16
+		}
+		Fees []uint64
+	}
+	if err := decoded.Into(&event); err != nil {
+		t.Fatalf("Into: %v", err)
+	}
+	if event.SolAmount != 42 || event.TokenAmount != 7 || !event.IsBuy || event.VirtualReserves.Cmp(want) != 0 {
+		t.Errorf("unexpected struct: %+v", event)
+	}
+	if event.Referrer == nil || *event.Referrer != testPubkey(7) || event.Extra.Tag != "x" || event.Extra.Weights != [2]uint16{3, 4} {
+		t.Errorf("unexpected nested values: %+v", event)
+	}
+	if len(event.Fees) != 2 || event.Fees[1] != 20 {
+		t.Errorf("unexpected vec: %v", event.Fees)
+	}
+
+	if _, err := parsed.DecodeEvent(tradeEventData(true, 42, 7)[:40]); err == nil {
+		t.Error("expected error for truncated event")
+	}
+	if _, err := parsed.DecodeInstruction([]byte{1, 2, 3, 4, 5, 6, 7, 8}, nil); err != idl.ErrUnknownDiscriminator {
+		t.Errorf("expected ErrUnknownDiscriminator, got %v", err)
+	}
+}
+
+func TestIDLDecodeVecLengthBound(t *testing.T) {
+	parsed, err := idl.Parse([]byte(`{
+  "address": "LaunchPad1111111111111111111111111111111111",
+  "metadata": {"name": "bounds", "version": "0.1.0", "spec": "0.1.0"},
+  "instructions": [],
+  "types": [
+    {"name": "Empty", "type": {"kind": "struct", "fields": []}},
+    {"name": "Pair", "type": {"kind": "struct", "fields": [{"name": "a", "type": "u64"}, {"name": "b", "type": "u64"}]}},
+    {"name": "Empties", "type": {"kind": "struct", "fields": [{"name": "items", "type": {"vec": {"defined": {"name": "Empty"}}}}]}},
+    {"name": "ZeroArrays", "type": {"kind": "struct", "fields": [{"name": "items", "type": {"vec": {"array": ["u64", 0]}}}]}},
+    {"name": "Pairs", "type": {"kind": "struct", "fields": [{"name": "items", "type": {"vec": {"defined": {"name": "Pair"}}}}]}}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Crafted lengths of zero-size elements must fail fast rather than loop
+	huge := binary.LittleEndian.AppendUint32(nil, 0xffffffff)
+	for _, name := range []string{"Empties", "ZeroArrays"} {
+		if _, err := parsed.DecodeType(name, huge); err == nil {
+			t.Errorf("%s: expected error for a vec length beyond the data", name)
+		}
+	}
+	if v, err := parsed.DecodeType("Empties", append(binary.LittleEndian.AppendUint32(nil, 2), 0, 0)); err != nil {
+		t.Errorf("Empties: unexpected error %v for a bounded length, got %v", err, v)
+	}
+	pairs := append(binary.LittleEndian.AppendUint32(nil, 2), make([]byte, 20)...)
+	if _, err := parsed.DecodeType("Pairs", pairs); err == nil {
+		t.Error("Pairs: expected error for two 16 byte elements in 20 bytes")
+	}
+}
+
+func TestIDLRejectsNegativeArrayLength(t *testing.T) {
+	_, err := idl.Parse([]byte(`{
+  "address": "LaunchPad1111111111111111111111111111111111",
+  "metadata": {"name": "bounds", "version": "0.1.0", "spec": "0.1.0"},
+  "instructions": [],
+  "types": [
+    {"name": "Negative", "type": {"kind": "struct", "fields": [{"name": "items", "type": {"array": ["u8", -1]}}]}}
+  ]
+}`))
+	if !errors.Is(err, idl.ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType for a negative array length, got %v", err)
+	}
+}
+
+func TestIDLTradeMapping(t *testing.T) {
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	program := idl.NewProgram(parsed, idl.Mapping{
+		Trades: []idl.TradeMapping{{
+			Event:       "TradeEvent",
+			Instruction: "buy",
+			IsBuy:       "is_buy",
+			User:        "user",
+			Pool:        "accounts.bonding_curve",
+			BaseMint:    "mint",
+			BaseAmount:  "token_amount",
+			QuoteAmount: "sol_amount",
+			Fee:         "fees.0",
+		}},
+	})
+	parser := dexparser.NewDexParser(dexparser.WithTradeParser(program.ProgramId, program.NewTradeParser))
+
+	result := parser.ParseAll(newLaunchpadTransaction(true), nil)
+	if len(result.Trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d (%s)", len(result.Trades), result.Msg)
+	}
+	trade := result.Trades[0]
+	if trade.Type != types.TradeTypeBuy || trade.AMM != "launchpad" || trade.Idx != "0-0" {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+	if trade.InputToken.Mint != constants.TOKENS.SOL || trade.InputToken.Amount != 0.5 {
+		t.Errorf("unexpected input: %+v", trade.InputToken)
+	}
+	if trade.OutputToken.Mint != testPubkey(1) || trade.OutputToken.Decimals != 6 || trade.OutputToken.Amount != 2 {
+		t.Errorf("unexpected output: %+v", trade.OutputToken)
+	}
+	if len(trade.Pool) != 1 || trade.Pool[0] != "curve" || trade.User != testPubkey(9) {
+		t.Errorf("unexpected pool/user: %v %s", trade.Pool, trade.User)
+	}
+
+	result = parser.ParseAll(newLaunchpadTransaction(false), nil)
+	if len(result.Trades) != 1 || result.Trades[0].Type != types.TradeTypeSell || result.Trades[0].OutputToken.Mint != constants.TOKENS.SOL {
+		t.Errorf("expected sell trade, got %+v", result.Trades)
+	}
+}
+
+func TestIDLPoolEventMapping(t *testing.T) {
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	program := idl.NewProgram(parsed, idl.Mapping{
+		PoolEvents: []idl.PoolEventMapping{{
+			Instruction:  "buy",
+			Type:         types.PoolEventTypeAdd,
+			Pool:         "accounts.bonding_curve",
+			Token0Mint:   "accounts.mint",
+			Token0Amount: "amount",
+		}},
+	})
+	parser := dexparser.NewDexParser(dexparser.WithLiquidityParser(program.ProgramId, program.NewLiquidityParser))
+
+	result := parser.ParseAll(newLaunchpadTransaction(true), nil)
+	if len(result.Liquidities) != 1 {
+		t.Fatalf("expected 1 pool event, got %d", len(result.Liquidities))
+	}
+	event := result.Liquidities[0]
+	if event.PoolId != "curve" || event.Token0Mint != testPubkey(1) || event.Token0AmountRaw != "1000" || event.Type != types.PoolEventTypeAdd {
+		t.Errorf("unexpected pool event: %+v", event)
+	}
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/idl"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers/raydium"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	logRouter        = "Router1111111111111111111111111111111111111"
+	logComputeBudget = "ComputeBudget111111111111111111111111111111"
+	logPrecompile    = "Ed25519SigVerify111111111111111111111111111"
+)
+
+// raySwapBaseIn encodes a SwapBaseIn ray_log payload
+func raySwapBaseIn(amountIn, minimumOut, outAmount uint64) []byte {
+	b := (&borsh{}).raw(byte(raydium.RayLogSwapBaseIn))
+	b.u64(amountIn).u64(minimumOut).u64(2).u64(amountIn * 2).u64(1_000_000).u64(5_000_000).u64(outAmount)
+	return b.Bytes()
+}
+
+// newRoutedTransaction builds a transaction where a router CPIs into Raydium v4 after a
+// ComputeBudget instruction and a precompile that writes no logs
+func newRoutedTransaction(logs []string) *adapter.SolanaTransaction {
+	v4 := constants.DEX_PROGRAMS.RAYDIUM_V4.ID
+	tx := newTestTransaction("log_signature", []string{testPubkey(9), logComputeBudget, logPrecompile, logRouter, v4, constants.TOKEN_PROGRAM_ID},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Data: base58.Encode([]byte{2, 0, 0, 1, 0})},
+		adapter.CompiledInstruction{ProgramIdIndex: 2, Data: base58.Encode([]byte{0})},
+		adapter.CompiledInstruction{ProgramIdIndex: 3, Accounts: []int{0, 4}, Data: base58.Encode([]byte{1})},
+	)
+	tx.Slot = 12
+	tx.Meta = &adapter.TransactionMeta{
+		InnerInstructions: []adapter.InnerInstructionSet{{
+			Index: 2,
+			Instructions: []interface{}{
+				adapter.CompiledInstruction{ProgramIdIndex: 4, Accounts: []int{0}, Data: base58.Encode([]byte{9})},
+				adapter.CompiledInstruction{ProgramIdIndex: 5, Accounts: []int{0}, Data: base58.Encode([]byte{3})},
+				adapter.CompiledInstruction{ProgramIdIndex: 5, Accounts: []int{0}, Data: base58.Encode([]byte{3})},
+			},
+		}},
+		LogMessages: logs,
+	}
+	return tx
+}
+
+func routedLogs() []string {
+	v4 := constants.DEX_PROGRAMS.RAYDIUM_V4.ID
+	token := constants.TOKEN_PROGRAM_ID
+	rayLog := base64.StdEncoding.EncodeToString(raySwapBaseIn(1000, 900, 950))
+	return []string{
+		"Program " + logComputeBudget + " invoke [1]",
+		"Program " + logComputeBudget + " success",
+		"Program " + logRouter + " invoke [1]",
+		"Program log: Instruction: Route",
+		"Program " + v4 + " invoke [2]",
+		"Program log: ray_log: " + rayLog,
+		"Program " + token + " invoke [3]",
+		"Program log: Instruction: Transfer",
+		"Program " + token + " consumed 4645 of 180000 compute units",
+		"Program " + token + " success",
+		"Program " + token + " invoke [3]",
+		"Program " + token + " success",
+		"Program " + v4 + " consumed 30000 of 200000 compute units",
+		"Program " + v4 + " success",
+		"Program data: " + base64.StdEncoding.EncodeToString([]byte{1, 2}) + " " + base64.StdEncoding.EncodeToString([]byte{3}),
+		"Program " + logRouter + " consumed 60000 of 250000 compute units",
+		"Program " + logRouter + " success",
+	}
+}
+
+func TestLogEventsAttribution(t *testing.T) {
+	txAdapter := adapter.NewTransactionAdapter(newRoutedTransaction(routedLogs()), &types.ParseConfig{})
+	events := txAdapter.LogEvents()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 log events, got %d: %+v", len(events), events)
+	}
+
+	ray := events[0]
+	if ray.Type != types.LogEventRayLog || ray.ProgramId != constants.DEX_PROGRAMS.RAYDIUM_V4.ID {
+		t.Errorf("unexpected ray_log event: %+v", ray)
+	}
+	if ray.OuterIndex != 2 || ray.InnerIndex != 0 || ray.Depth != 2 || ray.LogIndex != 5 {
+		t.Errorf("ray_log attributed to %d-%d depth %d log %d", ray.OuterIndex, ray.InnerIndex, ray.Depth, ray.LogIndex)
+	}
+	swap, err := raydium.DecodeRaySwapLog(ray.Data)
+	if err != nil {
+		t.Fatalf("DecodeRaySwapLog: %v", err)
+	}
+	if swap.AmountIn != 1000 || swap.AmountOut != 950 || swap.Limit != 900 || swap.Direction != 2 || swap.PoolPc != 5_000_000 {
+		t.Errorf("unexpected swap log: %+v", swap)
+	}
+
+	data := events[1]
+	if data.Type != types.LogEventProgramData || data.ProgramId != logRouter || data.OuterIndex != 2 || data.InnerIndex != -1 || data.Depth != 1 {
+		t.Errorf("unexpected program data event: %+v", data)
+	}
+	if string(data.Data) != string([]byte{1, 2, 3}) {
+		t.Errorf("expected chunks to be concatenated, got %v", data.Data)
+	}
+
+	ic := classifier.NewInstructionClassifier(txAdapter)
+	if got := ic.GetEvents(logRouter); len(got) != 1 || got[0].LogIndex != 14 {
+		t.Errorf("unexpected classifier events: %+v", got)
+	}
+	if got := ic.GetEvents(constants.TOKEN_PROGRAM_ID); len(got) != 0 {
+		t.Errorf("token program emitted no events, got %+v", got)
+	}
+}
+
+func TestLogEventsTruncated(t *testing.T) {
+	logs := routedLogs()[:7]
+	logs = append(logs, "Log truncated", "Program data: AQID")
+	txAdapter := adapter.NewTransactionAdapter(newRoutedTransaction(logs), &types.ParseConfig{})
+	events := txAdapter.LogEvents()
+	if len(events) != 1 || events[0].Type != types.LogEventRayLog {
+		t.Errorf("expected only the ray_log before truncation, got %+v", events)
+	}
+
+	if _, err := raydium.DecodeRaySwapLog([]byte{byte(raydium.RayLogDeposit)}); err == nil {
+		t.Error("expected error for non-swap ray_log")
+	}
+	if _, err := raydium.DecodeRaySwapLog([]byte{byte(raydium.RayLogSwapBaseOut), 1}); err == nil {
+		t.Error("expected error for short ray_log")
+	}
+}
+
+func TestIDLLogEventTrade(t *testing.T) {
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	program := idl.NewProgram(parsed, idl.Mapping{
+		Trades: []idl.TradeMapping{{
+			Event:       "TradeEvent",
+			Instruction: "buy",
+			IsBuy:       "is_buy",
+			User:        "user",
+			Pool:        "accounts.bonding_curve",
+			BaseMint:    "mint",
+			BaseAmount:  "token_amount",
+			QuoteAmount: "sol_amount",
+		}},
+	})
+
+	// Same trade as newLaunchpadTransaction, but the event is logged instead of self-invoked
+	tx := newLaunchpadTransaction(true)
+	event := tradeEventData(true, 500_000_000, 2_000_000)[len(idl.EventIxTag):]
+	tx.Meta.InnerInstructions = nil
+	tx.Meta.LogMessages = []string{
+		"Program " + launchpadProgram + " invoke [1]",
+		"Program log: Instruction: Buy",
+		"Program data: " + base64.StdEncoding.EncodeToString(event),
+		"Program " + launchpadProgram + " success",
+	}
+
+	parser := dexparser.NewDexParser(dexparser.WithTradeParser(program.ProgramId, program.NewTradeParser))
+	result := parser.ParseAll(tx, nil)
+	if len(result.Trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d (%s)", len(result.Trades), result.Msg)
+	}
+	trade := result.Trades[0]
+	if trade.Type != types.TradeTypeBuy || trade.Idx != "0-0" || trade.InputToken.Amount != 0.5 || trade.OutputToken.Amount != 2 {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+	if len(trade.Pool) != 1 || trade.Pool[0] != "curve" {
+		t.Errorf("expected pool from the paired instruction, got %v", trade.Pool)
+	}
+}
+
+func TestRaydiumV4TradeUsesRayLog(t *testing.T) {
+	v4 := constants.DEX_PROGRAMS.RAYDIUM_V4.ID
+	token := constants.TOKEN_PROGRAM_ID
+	signer, amm, authority := testPubkey(9), testPubkey(10), testPubkey(11)
+	userSource, userDest, coinVault, pcVault := testPubkey(12), testPubkey(13), testPubkey(14), testPubkey(15)
+	coin := testPubkey(16)
+	transfer := func(source, destination, owner int, amount uint64) adapter.CompiledInstruction {
+		data := binary.LittleEndian.AppendUint64([]byte{3}, amount)
+		return adapter.CompiledInstruction{ProgramIdIndex: 2, Accounts: []int{source, destination, owner}, Data: base58.Encode(data)}
+	}
+	balance := func(index int, mint, owner, amount string) adapter.TokenBalance {
+		return adapter.TokenBalance{AccountIndex: index, Mint: mint, Owner: owner, UiTokenAmount: types.TokenAmount{Amount: amount, Decimals: 6}}
+	}
+
+	// The transfers move 1000 in and 940 out, the ray_log reports the exact 1000 in and 950 out
+	tx := newTestTransaction("raylog_signature", []string{signer, v4, token, amm, authority, userSource, userDest, coinVault, pcVault},
+		adapter.CompiledInstruction{
+			ProgramIdIndex: 1,
+			Accounts:       []int{2, 3, 4, 3, 7, 8, 3, 3, 3, 3, 3, 3, 3, 3, 5, 6, 0},
+			Data:           base58.Encode(append([]byte{9}, make([]byte, 16)...)),
+		},
+	)
+	tx.Meta = &adapter.TransactionMeta{
+		InnerInstructions: []adapter.InnerInstructionSet{{
+			Index:        0,
+			Instructions: []interface{}{transfer(5, 8, 0, 1000), transfer(7, 6, 4, 940)},
+		}},
+		LogMessages: []string{
+			"Program " + v4 + " invoke [1]",
+			"Program log: ray_log: " + base64.StdEncoding.EncodeToString(raySwapBaseIn(1000, 900, 950)),
+			"Program " + token + " invoke [2]",
+			"Program " + token + " success",
+			"Program " + token + " invoke [2]",
+			"Program " + token + " success",
+			"Program " + v4 + " success",
+		},
+		PreTokenBalances: []adapter.TokenBalance{
+			balance(5, constants.TOKENS.USDC, signer, "5000"), balance(6, coin, signer, "0"),
+			balance(7, coin, authority, "100000"), balance(8, constants.TOKENS.USDC, authority, "100000"),
+		},
+		PostTokenBalances: []adapter.TokenBalance{
+			balance(5, constants.TOKENS.USDC, signer, "4000"), balance(6, coin, signer, "940"),
+			balance(7, coin, authority, "99060"), balance(8, constants.TOKENS.USDC, authority, "101000"),
+		},
+	}
+
+	trades := dexparser.NewDexParser().ParseTrades(tx, nil)
+	if len(trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d", len(trades))
+	}
+	trade := trades[0]
+	if trade.InputToken.Mint != constants.TOKENS.USDC || trade.InputToken.AmountRaw != "1000" || trade.InputToken.Amount != 0.001 {
+		t.Errorf("unexpected input: %+v", trade.InputToken)
+	}
+	if trade.OutputToken.Mint != coin || trade.OutputToken.AmountRaw != "950" || trade.OutputToken.Amount != 0.00095 {
+		t.Errorf("expected the exact ray_log output amount, got %+v", trade.OutputToken)
+	}
+}
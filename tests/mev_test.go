@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/mev"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// swapResult is a successful transaction with one quote-paired trade against pool
+func swapResult(slot uint64, signature, signer, pool string, side types.TradeType, base, quote float64) *types.ParseResult {
+	sol := types.TokenInfo{Mint: constants.TOKENS.SOL, Amount: quote, Decimals: 9}
+	token := types.TokenInfo{Mint: testPubkey(1), Amount: base, Decimals: 6}
+	trade := types.TradeInfo{
+		User:        signer,
+		Type:        side,
+		Pool:        []string{pool},
+		InputToken:  sol,
+		OutputToken: token,
+		ProgramId:   constants.DEX_PROGRAMS.PUMP_SWAP.ID,
+		AMM:         constants.DEX_PROGRAMS.PUMP_SWAP.Name,
+		Slot:        slot,
+		Signature:   signature,
+		Idx:         "0-0",
+	}
+	if side == types.TradeTypeSell {
+		trade.InputToken, trade.OutputToken = token, sol
+	}
+	return &types.ParseResult{
+		State:     true,
+		TxStatus:  types.TransactionStatusSuccess,
+		Slot:      slot,
+		Signature: signature,
+		Signer:    []string{signer},
+		Trades:    []types.TradeInfo{trade},
+	}
+}
+
+func TestDetectSandwich(t *testing.T) {
+	pool, other := testPubkey(20), testPubkey(21)
+	attacker, backRunner, victim, trader := testPubkey(30), testPubkey(31), testPubkey(32), testPubkey(33)
+
+	failed := swapResult(100, "failed", attacker, pool, types.TradeTypeSell, 1000, 1.2)
+	failed.TxStatus = types.TransactionStatusFailed
+	block := &types.BlockResult{
+		Slot: 100,
+		Transactions: []types.BlockTransaction{
+			{Index: 0, Result: swapResult(100, "front", attacker, pool, types.TradeTypeBuy, 1000, 1)},
+			{Index: 2, Result: swapResult(100, "victim", victim, pool, types.TradeTypeBuy, 900, 1)},
+			{Index: 3, Result: swapResult(100, "other-buy", trader, other, types.TradeTypeBuy, 500, 0.5)},
+			{Index: 4, Result: failed},
+			{Index: 5, Result: swapResult(100, "back", backRunner, pool, types.TradeTypeSell, 1000, 1.15)},
+			{Index: 6, Result: swapResult(100, "other-sell", trader, other, types.TradeTypeSell, 500, 0.6)},
+		},
+	}
+
+	// Without linking, the back-run comes from an unrelated signer
+	if found := mev.DetectBlock(block, nil); len(found) != 0 {
+		t.Fatalf("expected no sandwich without linked signers, got %+v", found)
+	}
+
+	found := mev.DetectBlock(block, &mev.Config{LinkedSigners: [][]string{{attacker, backRunner}}})
+	if len(found) != 1 {
+		t.Fatalf("expected one sandwich, got %d", len(found))
+	}
+	s := found[0]
+	if s.Attacker != attacker || s.Pool != pool || s.AMM != "Pumpswap" || s.BaseMint != testPubkey(1) || s.QuoteMint != constants.TOKENS.SOL {
+		t.Errorf("unexpected sandwich: %+v", s)
+	}
+	if s.FrontRun.Signature != "front" || s.FrontRun.Position != 0 || s.BackRun.Signature != "back" || s.BackRun.Position != 5 || s.BackRun.Signer != backRunner {
+		t.Errorf("unexpected front- or back-run: %+v / %+v", s.FrontRun, s.BackRun)
+	}
+	if len(s.Victims) != 1 || s.Victims[0].Signer != victim || s.Victims[0].Position != 2 || s.Victims[0].Side != types.TradeTypeBuy {
+		t.Fatalf("unexpected victims: %+v", s.Victims)
+	}
+	if math.Abs(s.Victims[0].Loss-0.1) > 1e-9 || math.Abs(s.VictimLoss-0.1) > 1e-9 || math.Abs(s.AttackerProfit-0.15) > 1e-9 {
+		t.Errorf("unexpected loss or profit: %v, %v", s.VictimLoss, s.AttackerProfit)
+	}
+}
+
+func TestSandwichDetectorWindow(t *testing.T) {
+	pool := testPubkey(20)
+	attacker, victim := testPubkey(30), testPubkey(32)
+
+	// A sell-side sandwich: the attacker dumps before the victim's sell and buys back after
+	d := mev.NewDetector(nil)
+	d.Add(0, swapResult(100, "front", attacker, pool, types.TradeTypeSell, 1000, 1))
+	d.Add(1, swapResult(100, "victim", victim, pool, types.TradeTypeSell, 1000, 0.9))
+	found := d.Add(2, swapResult(100, "back", attacker, pool, types.TradeTypeBuy, 1050, 0.95))
+	if len(found) != 1 || math.Abs(found[0].AttackerProfit-0.05) > 1e-9 || math.Abs(found[0].VictimLoss-0.1) > 1e-9 {
+		t.Fatalf("unexpected sell-side sandwich: %+v", found)
+	}
+	if again := d.Add(3, swapResult(100, "back2", attacker, pool, types.TradeTypeBuy, 1000, 0.95)); len(again) != 0 {
+		t.Errorf("a front-run must be reported once, got %+v", again)
+	}
+
+	// The front-run falls out of a window of two trades
+	d = mev.NewDetector(&mev.Config{Window: 2})
+	d.Add(0, swapResult(100, "front", attacker, pool, types.TradeTypeBuy, 1000, 1))
+	d.Add(1, swapResult(100, "victim", victim, pool, types.TradeTypeBuy, 900, 1))
+	d.Add(2, swapResult(100, "victim2", victim, pool, types.TradeTypeBuy, 800, 1))
+	if found := d.Add(3, swapResult(100, "back", attacker, pool, types.TradeTypeSell, 1000, 1.2)); len(found) != 0 {
+		t.Errorf("expected the front-run to leave the window, got %+v", found)
+	}
+
+	// A new slot clears the window
+	d = mev.NewDetector(nil)
+	d.Add(0, swapResult(100, "front", attacker, pool, types.TradeTypeBuy, 1000, 1))
+	d.Add(1, swapResult(100, "victim", victim, pool, types.TradeTypeBuy, 900, 1))
+	if found := d.Add(0, swapResult(101, "back", attacker, pool, types.TradeTypeSell, 1000, 1.2)); len(found) != 0 {
+		t.Errorf("expected no sandwich across slots, got %+v", found)
+	}
+
+	// Amounts that do not unwind the front-run are not a back-run
+	d = mev.NewDetector(nil)
+	d.Add(0, swapResult(100, "front", attacker, pool, types.TradeTypeBuy, 1000, 1))
+	d.Add(1, swapResult(100, "victim", victim, pool, types.TradeTypeBuy, 900, 1))
+	if found := d.Add(2, swapResult(100, "back", attacker, pool, types.TradeTypeSell, 300, 0.4)); len(found) != 0 {
+		t.Errorf("expected a partial sell not to match, got %+v", found)
+	}
+}
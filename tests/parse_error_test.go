@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	panickingProgram = "PanicProgram1111111111111111111111111111111"
+	workingProgram   = "WorkingProgram11111111111111111111111111111"
+)
+
+// panicTradeParser panics while processing trades
+type panicTradeParser struct{}
+
+func (p *panicTradeParser) ProcessTrades() []types.TradeInfo {
+	panic(errors.New("index out of range in decoder"))
+}
+
+// newTwoProgramTransaction builds a transaction calling two programs
+func newTwoProgramTransaction() *adapter.SolanaTransaction {
+	tx := newTestTransaction("two_program_signature", []string{"signer", panickingProgram, workingProgram},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Accounts: []int{0}, Data: base58.Encode([]byte{1})},
+		adapter.CompiledInstruction{ProgramIdIndex: 2, Accounts: []int{0}, Data: base58.Encode([]byte{2})},
+	)
+	tx.Slot = 7
+	tx.Meta = &adapter.TransactionMeta{}
+	return tx
+}
+
+func newIsolationParser() *dexparser.DexParser {
+	parser := dexparser.NewDexParser()
+	parser.RegisterTradeParser(panickingProgram, func(*adapter.TransactionAdapter, types.DexInfo, map[string][]types.TransferData, []types.ClassifiedInstruction) parsers.TradeParser {
+		return &panicTradeParser{}
+	})
+	parser.RegisterTradeParser(workingProgram, func(a *adapter.TransactionAdapter, d types.DexInfo, _ map[string][]types.TransferData, _ []types.ClassifiedInstruction) parsers.TradeParser {
+		return &stubTradeParser{trades: []types.TradeInfo{{
+			ProgramId:   workingProgram,
+			InputToken:  types.TokenInfo{Mint: "mintA", AmountRaw: "1"},
+			OutputToken: types.TokenInfo{Mint: "mintB", AmountRaw: "2"},
+			Idx:         "1",
+		}}}
+	})
+	return parser
+}
+
+func TestParserPanicIsolation(t *testing.T) {
+	result := newIsolationParser().ParseAll(newTwoProgramTransaction(), &types.ParseConfig{})
+
+	if !result.State {
+		t.Errorf("Expected state to stay true, got msg %q", result.Msg)
+	}
+	if len(result.Trades) != 1 || result.Trades[0].ProgramId != workingProgram {
+		t.Errorf("Expected the working program's trade to be kept, got %+v", result.Trades)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 parse error, got %d", len(result.Errors))
+	}
+
+	parseErr := result.Errors[0]
+	if parseErr.Code != types.ParseErrorDecoderPanic || parseErr.ProgramId != panickingProgram || parseErr.Idx != "0" {
+		t.Errorf("Unexpected parse error: %+v", parseErr)
+	}
+	if !errors.Is(result.Err(), types.ErrDecoderPanic) {
+		t.Error("Expected errors.Is to match ErrDecoderPanic")
+	}
+	if parseErr.Cause == nil || parseErr.Cause.Error() != "index out of range in decoder" {
+		t.Errorf("Expected wrapped panic cause, got %v", parseErr.Cause)
+	}
+}
+
+func TestParserPanicThrowError(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic with ThrowError")
+		}
+	}()
+	newIsolationParser().ParseAll(newTwoProgramTransaction(), &types.ParseConfig{ThrowError: true})
+}
+
+func TestParserPanicOutsideParsers(t *testing.T) {
+	// A panic outside the program parsers is an internal failure, not malformed input
+	config := &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcher(types.FetchFilterAll, func([]string) ([]*types.TokenAccountInfo, error) {
+			panic("fetcher bug")
+		}),
+	}
+	result := dexparser.NewDexParser().ParseAll(newShredTransferTransaction(), config)
+	if result.State || !errors.Is(result.Err(), types.ErrDecoderPanic) || errors.Is(result.Err(), types.ErrMalformedInput) {
+		t.Errorf("Expected DECODER_PANIC, got %v", result.Err())
+	}
+	shred := dexparser.NewShredParser().ParseAll(newShredTransferTransaction(), config)
+	if len(shred.Errors) != 1 || !errors.Is(shred.Errors[0], types.ErrDecoderPanic) {
+		t.Errorf("Expected DECODER_PANIC from the shred parser, got %v", shred.Errors)
+	}
+}
+
+func TestParseErrorFiltered(t *testing.T) {
+	parser := dexparser.NewDexParser()
+
+	tests := []struct {
+		name   string
+		config types.ParseConfig
+		msg    string
+	}{
+		{"program ids", types.ParseConfig{ProgramIds: []string{"other"}}, "No matching program ids"},
+		{"account include", types.ParseConfig{AccountInclude: []string{"other"}}, "No matching accounts include"},
+		{"account exclude", types.ParseConfig{AccountExclude: []string{"signer"}}, "Account excluded"},
+	}
+	for _, tt := range tests {
+		result := parser.ParseAll(newTwoProgramTransaction(), &tt.config)
+		if result.State || result.Msg != tt.msg {
+			t.Errorf("%s: expected failed state with %q, got %v/%q", tt.name, tt.msg, result.State, result.Msg)
+		}
+		if !errors.Is(result.Err(), types.ErrFiltered) {
+			t.Errorf("%s: expected FILTERED error, got %v", tt.name, result.Err())
+		}
+	}
+}
+
+func TestParseErrorMalformedAndVersion(t *testing.T) {
+	parser := dexparser.NewDexParser()
+
+	result := parser.ParseAll(nil, nil)
+	if result.State || !errors.Is(result.Err(), types.ErrMalformedInput) {
+		t.Errorf("Expected MALFORMED_INPUT for nil transaction, got %v", result.Err())
+	}
+
+	tx := newTwoProgramTransaction()
+	tx.Version = float64(1)
+	result = parser.ParseAll(tx, nil)
+	var parseErr *types.ParseError
+	if !errors.As(result.Err(), &parseErr) || parseErr.Code != types.ParseErrorUnsupportedVersion {
+		t.Errorf("Expected UNSUPPORTED_VERSION, got %v", result.Err())
+	}
+
+	for _, version := range []interface{}{nil, "legacy", float64(0), 0} {
+		tx.Version = version
+		if result := parser.ParseAll(tx, nil); result.Err() != nil {
+			t.Errorf("Version %v: unexpected error %v", version, result.Err())
+		}
+	}
+}
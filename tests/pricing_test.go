@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/pricing"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// pricedTrade swaps amountIn of in for amountOut of out at timestamp
+func pricedTrade(timestamp int64, in string, amountIn float64, out string, amountOut float64) types.TradeInfo {
+	return types.TradeInfo{
+		InputToken:  types.TokenInfo{Mint: in, Amount: amountIn},
+		OutputToken: types.TokenInfo{Mint: out, Amount: amountOut},
+		Timestamp:   timestamp,
+	}
+}
+
+func TestPricingEngine(t *testing.T) {
+	sol, usdc, usdt, token := constants.TOKENS.SOL, constants.TOKENS.USDC, constants.TOKENS.USDT, testPubkey(1)
+	engine := pricing.NewEngine(pricing.DefaultConfig())
+	const start = 1718000000
+
+	if _, ok := engine.SOLPrice(start); ok {
+		t.Fatal("expected no SOL price before any trade")
+	}
+	engine.Observe(pricedTrade(start, sol, 2, usdc, 300))
+	engine.Observe(pricedTrade(start+1, usdt, 151, sol, 1))
+	engine.Observe(pricedTrade(start+2, constants.TOKENS.NATIVE, 1, usdc, 149))
+	// A far-off price from a thin pool is rejected
+	engine.Observe(pricedTrade(start+3, sol, 1, usdc, 300))
+	if price, ok := engine.SOLPrice(start + 3); !ok || !approxEqual(price, 150) {
+		t.Fatalf("expected the SOL median of 150, got %v %v", price, ok)
+	}
+
+	// A buy of a token for SOL is priced by itself and the SOL reference
+	buy := pricedTrade(start+10, sol, 1, token, 1000)
+	engine.Apply(&buy)
+	if !approxEqual(buy.PriceQuote, 0.001) || !approxEqual(buy.PriceUSD, 0.15) || !approxEqual(buy.VolumeUSD, 150) {
+		t.Errorf("unexpected buy valuation: %v %v %v", buy.PriceQuote, buy.PriceUSD, buy.VolumeUSD)
+	}
+	if price, ok := engine.PriceUSD(token, start+20); !ok || !approxEqual(price, 0.15) {
+		t.Errorf("expected the token at 0.15 USD, got %v %v", price, ok)
+	}
+
+	// A token-to-token trade is valued from the side with a price and does not update prices
+	other := testPubkey(2)
+	swap := pricedTrade(start+20, token, 2000, other, 10)
+	engine.Apply(&swap)
+	if swap.PriceQuote != 0 || swap.PriceUSD != 0 || !approxEqual(swap.VolumeUSD, 300) {
+		t.Errorf("unexpected swap valuation: %+v", swap)
+	}
+	if _, ok := engine.PriceUSD(other, start+20); ok {
+		t.Error("a token-to-token trade must not price its tokens")
+	}
+
+	// Non-USD stablecoins are priced like tokens and quote others
+	engine.Observe(pricedTrade(start+30, constants.TOKENS.EURC, 100, usdc, 108))
+	euro := pricedTrade(start+31, constants.TOKENS.EURC, 10, other, 100)
+	engine.Apply(&euro)
+	if !approxEqual(euro.PriceQuote, 0.1) || !approxEqual(euro.PriceUSD, 0.108) || !approxEqual(euro.VolumeUSD, 10.8) {
+		t.Errorf("unexpected EURC valuation: %v %v %v", euro.PriceQuote, euro.PriceUSD, euro.VolumeUSD)
+	}
+
+	// Stale prices are not used
+	late := pricedTrade(start+int64((10*time.Minute).Seconds()), token, 1000, sol, 2)
+	engine.Apply(&late)
+	if !approxEqual(late.PriceQuote, 0.002) || late.PriceUSD != 0 || late.VolumeUSD != 0 {
+		t.Errorf("unexpected valuation with a stale SOL price: %+v", late)
+	}
+	if _, ok := engine.PriceUSD(other, late.Timestamp); ok {
+		t.Error("expected a stale token price")
+	}
+}
+
+func TestPricingOutliers(t *testing.T) {
+	sol, usdc, token := constants.TOKENS.SOL, constants.TOKENS.USDC, testPubkey(1)
+	engine := pricing.NewEngine(pricing.Config{Window: 8, MaxDeviation: 0.1})
+
+	// Without staleness a real move is followed once enough observations agree on it
+	for i := int64(0); i < 5; i++ {
+		engine.Observe(pricedTrade(i, sol, 1, usdc, 100))
+	}
+	engine.Observe(pricedTrade(5, sol, 1, usdc, 150))
+	engine.Observe(pricedTrade(6, sol, 1, usdc, 10)) // disagrees with the run and restarts it
+	if price, _ := engine.SOLPrice(6); !approxEqual(price, 100) {
+		t.Errorf("expected outliers to be rejected, got %v", price)
+	}
+	for i := int64(7); i < 1000; i++ {
+		engine.Observe(pricedTrade(i, sol, 1, usdc, 150))
+	}
+	if price, _ := engine.SOLPrice(1000); !approxEqual(price, 150) {
+		t.Errorf("expected the reference to follow the move, got %v", price)
+	}
+
+	// A dust trade does not move a token price, a sustained move does
+	engine.Observe(pricedTrade(1000, usdc, 100, token, 1000))
+	engine.Observe(pricedTrade(1001, usdc, 0.000001, token, 1))
+	if price, _ := engine.PriceUSD(token, 1001); !approxEqual(price, 0.1) {
+		t.Errorf("expected the dust trade to be rejected, got %v", price)
+	}
+	for i := int64(0); i < 3; i++ {
+		engine.Observe(pricedTrade(1002+i, usdc, 100, token, 500))
+	}
+	if price, _ := engine.PriceUSD(token, 1005); !approxEqual(price, 0.2) {
+		t.Errorf("expected the token price to follow the move, got %v", price)
+	}
+}
+
+func TestPricingApplyBlock(t *testing.T) {
+	sol, usdc, token := constants.TOKENS.SOL, constants.TOKENS.USDC, testPubkey(1)
+	reference := types.NewParseResult()
+	reference.TxStatus = types.TransactionStatusSuccess
+	reference.Trades = []types.TradeInfo{pricedTrade(100, usdc, 200, sol, 1)}
+
+	routed := types.NewParseResult()
+	routed.TxStatus = types.TransactionStatusSuccess
+	routed.Trades = []types.TradeInfo{
+		pricedTrade(100, sol, 1, usdc, 200),
+		pricedTrade(100, usdc, 200, token, 4000),
+	}
+	aggregate := pricedTrade(100, sol, 1, token, 4000)
+	routed.AggregateTrade = &aggregate
+
+	failed := types.NewParseResult()
+	failed.TxStatus = types.TransactionStatusFailed
+	failed.Trades = []types.TradeInfo{pricedTrade(100, usdc, 1, sol, 1)}
+
+	engine := pricing.NewEngine(pricing.Config{})
+	engine.ApplyBlock(&types.BlockResult{Transactions: []types.BlockTransaction{
+		{Index: 0, Result: reference},
+		{Index: 1, Result: failed},
+		{Index: 2, Result: routed},
+	}})
+
+	if failed.Trades[0].PriceQuote != 0 {
+		t.Error("failed transactions must not be priced")
+	}
+	if price, ok := engine.SOLPrice(0); !ok || !approxEqual(price, 200) {
+		t.Errorf("expected SOL at 200 without staleness limit, got %v", price)
+	}
+	if hop := routed.Trades[1]; !approxEqual(hop.PriceUSD, 0.05) || !approxEqual(hop.VolumeUSD, 200) {
+		t.Errorf("unexpected hop valuation: %+v", hop)
+	}
+	if !approxEqual(aggregate.PriceQuote, 0.00025) || !approxEqual(aggregate.PriceUSD, 0.05) || !approxEqual(aggregate.VolumeUSD, 200) {
+		t.Errorf("unexpected aggregate valuation: %+v", aggregate)
+	}
+}
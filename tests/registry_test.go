@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+func workingTradeFactory(a *adapter.TransactionAdapter, d types.DexInfo, _ map[string][]types.TransferData, _ []types.ClassifiedInstruction) parsers.TradeParser {
+	return &stubTradeParser{trades: []types.TradeInfo{{
+		ProgramId:   workingProgram,
+		InputToken:  types.TokenInfo{Mint: "mintA", AmountRaw: "1"},
+		OutputToken: types.TokenInfo{Mint: "mintB", AmountRaw: "2"},
+		Idx:         "1",
+	}}}
+}
+
+func findCapabilities(caps []dexparser.ProgramCapabilities, programId string) *dexparser.ProgramCapabilities {
+	for i := range caps {
+		if caps[i].ProgramId == programId {
+			return &caps[i]
+		}
+	}
+	return nil
+}
+
+func TestRegistryWithoutDefaultParsers(t *testing.T) {
+	parser := dexparser.NewDexParser(dexparser.WithoutDefaultParsers())
+	if caps := parser.RegisteredPrograms(); len(caps) != 0 {
+		t.Fatalf("expected empty registry, got %d programs", len(caps))
+	}
+
+	parser = dexparser.NewDexParser(
+		dexparser.WithoutDefaultParsers(),
+		dexparser.WithTradeParser(workingProgram, workingTradeFactory),
+	)
+	result := parser.ParseAll(newTwoProgramTransaction(), &types.ParseConfig{TryUnknownDEX: false})
+	if len(result.Trades) != 1 || result.Trades[0].ProgramId != workingProgram {
+		t.Fatalf("expected one trade from option-registered parser, got %+v", result.Trades)
+	}
+}
+
+func TestRegisteredPrograms(t *testing.T) {
+	parser := dexparser.NewDexParser()
+	caps := parser.RegisteredPrograms()
+	for i := 1; i < len(caps); i++ {
+		if caps[i-1].ProgramId >= caps[i].ProgramId {
+			t.Fatalf("programs not sorted: %s >= %s", caps[i-1].ProgramId, caps[i].ProgramId)
+		}
+	}
+
+	pumpfun := findCapabilities(caps, constants.DEX_PROGRAMS.PUMP_FUN.ID)
+	if pumpfun == nil {
+		t.Fatal("expected Pumpfun to be registered")
+	}
+	if !pumpfun.Trade || !pumpfun.MemeEvent || pumpfun.Name != constants.DEX_PROGRAMS.PUMP_FUN.Name {
+		t.Errorf("unexpected Pumpfun capabilities: %+v", pumpfun)
+	}
+
+	raydium := findCapabilities(caps, constants.DEX_PROGRAMS.RAYDIUM_V4.ID)
+	if raydium == nil || !raydium.Trade || !raydium.Liquidity || raydium.MemeEvent {
+		t.Errorf("unexpected Raydium V4 capabilities: %+v", raydium)
+	}
+}
+
+func TestRegistryWithoutProgram(t *testing.T) {
+	parser := dexparser.NewDexParser(dexparser.WithoutProgram(constants.DEX_PROGRAMS.PUMP_FUN.ID))
+	if findCapabilities(parser.RegisteredPrograms(), constants.DEX_PROGRAMS.PUMP_FUN.ID) != nil {
+		t.Error("expected Pumpfun to be unregistered")
+	}
+	if findCapabilities(dexparser.NewDexParser().RegisteredPrograms(), constants.DEX_PROGRAMS.PUMP_FUN.ID) == nil {
+		t.Error("unregistering must not affect other parsers")
+	}
+}
+
+func TestRegistryIndependentParsers(t *testing.T) {
+	custom := dexparser.NewDexParser(dexparser.WithTradeParser(workingProgram, workingTradeFactory))
+	plain := dexparser.NewDexParser()
+
+	if findCapabilities(plain.RegisteredPrograms(), workingProgram) != nil {
+		t.Error("options leaked into another parser")
+	}
+	if c := findCapabilities(custom.RegisteredPrograms(), workingProgram); c == nil || !c.Trade {
+		t.Errorf("expected custom trade parser, got %+v", c)
+	}
+}
+
+func TestRegistryConcurrentRegister(t *testing.T) {
+	parser := dexparser.NewDexParser(dexparser.WithoutDefaultParsers())
+	txs := make([]*adapter.SolanaTransaction, 50)
+	for i := range txs {
+		txs[i] = newTwoProgramTransaction()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			parser.RegisterTradeParser(workingProgram, workingTradeFactory)
+			parser.RegisteredPrograms()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		parser.ParseBatch(txs, &types.ParseConfig{}, 4)
+	}()
+	wg.Wait()
+
+	if c := findCapabilities(parser.RegisteredPrograms(), workingProgram); c == nil || !c.Trade {
+		t.Errorf("expected registered trade parser, got %+v", c)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// stubShredParser decodes every instruction of a program into a typed instruction
+type stubShredParser struct {
+	adapter    *adapter.TransactionAdapter
+	classifier *classifier.InstructionClassifier
+	programId  string
+}
+
+func (p *stubShredParser) ProcessInstructions() []interface{} {
+	var result []interface{}
+	for _, ci := range p.classifier.GetInstructions(p.programId) {
+		result = append(result, p.adapter.GetInstructionData(ci.Instruction))
+	}
+	return result
+}
+
+func (p *stubShredParser) ProcessTypedInstructions() []types.ParsedShredInstruction {
+	var result []types.ParsedShredInstruction
+	for _, ci := range p.classifier.GetInstructions(p.programId) {
+		result = append(result, types.ParsedShredInstruction{
+			ProgramID: p.programId,
+			Action:    "custom",
+			Accounts:  p.adapter.GetInstructionAccounts(ci.Instruction),
+			Idx:       ci.GetIdx(),
+		})
+	}
+	return result
+}
+
+func stubShredFactory(programId string) dexparser.ShredParserFactory {
+	return func(a *adapter.TransactionAdapter, c *classifier.InstructionClassifier) dexparser.ShredInstructionParser {
+		return &stubShredParser{adapter: a, classifier: c, programId: programId}
+	}
+}
+
+func TestRegisterShredParser(t *testing.T) {
+	parser := dexparser.NewShredParser()
+	result := parser.ParseAll(newTwoProgramTransaction(), nil)
+	if len(result.ParsedInstructions) != 0 {
+		t.Fatalf("expected no instructions before registration, got %d", len(result.ParsedInstructions))
+	}
+
+	parser.RegisterShredParser(workingProgram, stubShredFactory(workingProgram))
+	result = parser.ParseAll(newTwoProgramTransaction(), nil)
+	if len(result.ParsedInstructions) != 1 {
+		t.Fatalf("expected 1 typed instruction, got %d", len(result.ParsedInstructions))
+	}
+	if ix := result.ParsedInstructions[0]; ix.ProgramID != workingProgram || ix.Action != "custom" || ix.Idx != "1" {
+		t.Errorf("unexpected typed instruction: %+v", ix)
+	}
+	if len(result.Instructions[workingProgram]) != 1 {
+		t.Errorf("expected legacy instructions keyed by program ID, got %v", result.Instructions)
+	}
+}
+
+func TestShredParserOptionsAndFilters(t *testing.T) {
+	parser := dexparser.NewShredParser(
+		dexparser.WithoutDefaultShredParsers(),
+		dexparser.WithShredParser(workingProgram, stubShredFactory(workingProgram)),
+		dexparser.WithShredParser(panickingProgram, stubShredFactory(panickingProgram)),
+	)
+
+	result := parser.ParseAll(newTwoProgramTransaction(), nil)
+	if len(result.ParsedInstructions) != 2 {
+		t.Fatalf("expected 2 typed instructions, got %d", len(result.ParsedInstructions))
+	}
+
+	result = parser.ParseAll(newTwoProgramTransaction(), &types.ParseConfig{IgnoreProgramIds: []string{panickingProgram}})
+	if len(result.ParsedInstructions) != 1 || result.ParsedInstructions[0].ProgramID != workingProgram {
+		t.Errorf("expected ignored program to be skipped, got %+v", result.ParsedInstructions)
+	}
+
+	result = parser.ParseAll(newTwoProgramTransaction(), &types.ParseConfig{ProgramIds: []string{panickingProgram}})
+	if len(result.ParsedInstructions) != 1 || result.ParsedInstructions[0].ProgramID != panickingProgram {
+		t.Errorf("expected only the requested program, got %+v", result.ParsedInstructions)
+	}
+
+	parser = dexparser.NewShredParser(
+		dexparser.WithShredParser(workingProgram, stubShredFactory(workingProgram)),
+		dexparser.WithoutShredProgram(workingProgram),
+	)
+	if result = parser.ParseAll(newTwoProgramTransaction(), nil); len(result.ParsedInstructions) != 0 {
+		t.Errorf("expected removed parser to be skipped, got %+v", result.ParsedInstructions)
+	}
+}
+
+func TestShredParserLegacyPumpfunKey(t *testing.T) {
+	// An undecodable Pumpfun instruction still gets its legacy Instructions key, as before the
+	// registry; other programs only get a key with instructions
+	tx := newTwoProgramTransaction()
+	tx.Transaction.Message.StaticAccountKeys[1] = constants.DEX_PROGRAMS.PUMP_FUN.ID
+	tx.Transaction.Message.StaticAccountKeys[2] = constants.DEX_PROGRAMS.JUPITER.ID
+
+	result := dexparser.NewShredParser().ParseAll(tx, nil)
+	if instructions, ok := result.Instructions[constants.DEX_PROGRAMS.PUMP_FUN.Name]; !ok || len(instructions) != 0 {
+		t.Errorf("expected an empty Pumpfun group, got %v", result.Instructions)
+	}
+	if _, ok := result.Instructions[constants.DEX_PROGRAMS.JUPITER.Name]; ok {
+		t.Errorf("expected no Jupiter group, got %v", result.Instructions)
+	}
+}
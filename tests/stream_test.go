@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// newStreamTransaction builds a transfer transaction with its own signature, slot and block index
+func newStreamTransaction(signature string, slot, index uint64) *adapter.SolanaTransaction {
+	tx := newShredTransferTransaction()
+	tx.Transaction.Signatures = []string{signature}
+	tx.Slot = slot
+	tx.Index = index
+	return tx
+}
+
+// slowFetcherConfig delays parsing of transactions whose source account is delayed
+func slowFetcherConfig(delay func(keys []string) time.Duration) *types.ParseConfig {
+	return &types.ParseConfig{
+		TokenAccountsFetcher: types.NewTokenAccountsFetcher(types.FetchFilterAll, func(keys []string) ([]*types.TokenAccountInfo, error) {
+			time.Sleep(delay(keys))
+			return make([]*types.TokenAccountInfo, len(keys)), nil
+		}),
+	}
+}
+
+func feed(txs []*adapter.SolanaTransaction) <-chan *adapter.SolanaTransaction {
+	in := make(chan *adapter.SolanaTransaction)
+	go func() {
+		defer close(in)
+		for _, tx := range txs {
+			in <- tx
+		}
+	}()
+	return in
+}
+
+func collect(out <-chan dexparser.StreamResult) []dexparser.StreamResult {
+	var results []dexparser.StreamResult
+	for r := range out {
+		results = append(results, r)
+	}
+	return results
+}
+
+func TestStreamArrivalOrder(t *testing.T) {
+	var calls atomic.Int32
+	config := slowFetcherConfig(func([]string) time.Duration {
+		// Earlier transactions take longer so completion order differs from arrival order
+		return time.Duration(10-calls.Add(1)) * time.Millisecond
+	})
+
+	var txs []*adapter.SolanaTransaction
+	for i := 0; i < 8; i++ {
+		txs = append(txs, newStreamTransaction(fmt.Sprintf("sig%d", i), 100, uint64(i)))
+	}
+
+	results := collect(dexparser.NewDexParser().Stream(context.Background(), feed(txs), &dexparser.StreamOptions{
+		Config:  config,
+		Workers: 4,
+	}))
+	if len(results) != len(txs) {
+		t.Fatalf("Expected %d results, got %d", len(txs), len(results))
+	}
+	for i, r := range results {
+		if r.Seq != uint64(i) || r.CorrelationID != fmt.Sprintf("sig%d", i) {
+			t.Errorf("Result %d: got seq=%d id=%s", i, r.Seq, r.CorrelationID)
+		}
+		if r.Result == nil || r.Result.Signature != r.CorrelationID {
+			t.Errorf("Result %d: result does not match input", i)
+		}
+	}
+}
+
+func TestStreamSlotOrder(t *testing.T) {
+	txs := []*adapter.SolanaTransaction{
+		newStreamTransaction("s100i5", 100, 5),
+		newStreamTransaction("s100i2", 100, 2),
+		newStreamTransaction("s100i0", 100, 0),
+		newStreamTransaction("s101i1", 101, 1),
+		newStreamTransaction("s101i0", 101, 0),
+		newStreamTransaction("s102i0", 102, 0),
+	}
+
+	results := collect(dexparser.NewDexParser().Stream(context.Background(), feed(txs), &dexparser.StreamOptions{
+		Workers:       2,
+		Order:         dexparser.StreamOrderSlot,
+		CorrelationID: func(tx *adapter.SolanaTransaction) string { return "id-" + tx.Transaction.Signatures[0] },
+	}))
+
+	expected := []string{"id-s100i0", "id-s100i2", "id-s100i5", "id-s101i0", "id-s101i1", "id-s102i0"}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for i, id := range expected {
+		if results[i].CorrelationID != id {
+			t.Errorf("Position %d: expected %s, got %s", i, id, results[i].CorrelationID)
+		}
+	}
+}
+
+func TestStreamInFlightLimit(t *testing.T) {
+	in := make(chan *adapter.SolanaTransaction)
+	var sent atomic.Int32
+	go func() {
+		defer close(in)
+		for i := 0; i < 50; i++ {
+			in <- newStreamTransaction(fmt.Sprintf("sig%d", i), 1, uint64(i))
+			sent.Add(1)
+		}
+	}()
+
+	out := dexparser.NewDexParser().Stream(context.Background(), in, &dexparser.StreamOptions{
+		Workers:     2,
+		MaxInFlight: 4,
+		Order:       dexparser.StreamOrderCompletion,
+	})
+
+	// Without a consumer the stream must stop reading once the in-flight limit is reached
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n > 5 {
+		t.Errorf("Expected at most 5 transactions read without a consumer, got %d", n)
+	}
+
+	if results := collect(out); len(results) != 50 {
+		t.Errorf("Expected 50 results, got %d", len(results))
+	}
+}
+
+func TestStreamInFlightBelowWorkers(t *testing.T) {
+	in := make(chan *adapter.SolanaTransaction)
+	var sent atomic.Int32
+	go func() {
+		defer close(in)
+		for i := 0; i < 20; i++ {
+			in <- newStreamTransaction(fmt.Sprintf("sig%d", i), 1, uint64(i))
+			sent.Add(1)
+		}
+	}()
+
+	// An explicit limit below Workers is kept rather than replaced by the default
+	out := dexparser.NewDexParser().Stream(context.Background(), in, &dexparser.StreamOptions{
+		Workers:     8,
+		MaxInFlight: 2,
+		Order:       dexparser.StreamOrderCompletion,
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n > 3 {
+		t.Errorf("Expected at most 3 transactions read without a consumer, got %d", n)
+	}
+
+	if results := collect(out); len(results) != 20 {
+		t.Errorf("Expected 20 results, got %d", len(results))
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan *adapter.SolanaTransaction) // never closed
+
+	out := dexparser.NewDexParser().Stream(ctx, in, &dexparser.StreamOptions{Workers: 2})
+	in <- newStreamTransaction("sig0", 1, 0)
+	first := <-out
+	if first.CorrelationID != "sig0" {
+		t.Errorf("Expected sig0, got %s", first.CorrelationID)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			// A result may still be in flight; the channel must close right after
+			if _, ok = <-out; ok {
+				t.Error("Expected output channel to close after cancellation")
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stream did not stop after cancellation")
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/binary"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/idl"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	jitoTipAccount   = "96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5"
+	customTipAccount = "NextTip111111111111111111111111111111111111"
+)
+
+// systemTransferData encodes a System Program Transfer
+func systemTransferData(lamports uint64) string {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, constants.SystemTransfer)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	return base58.Encode(data)
+}
+
+func TestTips(t *testing.T) {
+	parsed, err := idl.Parse([]byte(launchpadIDL))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	program := idl.NewProgram(parsed, idl.Mapping{
+		Trades: []idl.TradeMapping{{
+			Event:       "TradeEvent",
+			IsBuy:       "is_buy",
+			User:        "user",
+			BaseMint:    "mint",
+			BaseAmount:  "token_amount",
+			QuoteAmount: "sol_amount",
+		}},
+	})
+
+	// The launchpad buy followed by a Jito tip and a tip to a configured provider
+	tx := newLaunchpadTransaction(true)
+	msg := &tx.Transaction.Message
+	msg.StaticAccountKeys = append(msg.StaticAccountKeys, constants.SYSTEM_PROGRAM_ID, jitoTipAccount, customTipAccount)
+	msg.CompiledInstructions = append(msg.CompiledInstructions,
+		adapter.CompiledInstruction{ProgramIdIndex: 5, Accounts: []int{0, 6}, Data: systemTransferData(1_000_000)},
+		adapter.CompiledInstruction{ProgramIdIndex: 5, Accounts: []int{0, 7}, Data: systemTransferData(500_000)},
+	)
+
+	config := types.DefaultParseConfig()
+	config.ParseType.AggregateTrade = true
+	config.TipAccounts = map[string][]string{"Nextblock": {customTipAccount}}
+	parser := dexparser.NewDexParser(dexparser.WithTradeParser(program.ProgramId, program.NewTradeParser))
+	result := parser.ParseAll(tx, &config)
+
+	if len(result.Tips) != 2 {
+		t.Fatalf("expected 2 tips, got %+v", result.Tips)
+	}
+	jito := result.Tips[0]
+	if jito.Provider != "Jito" || jito.Account != jitoTipAccount || jito.Payer != testPubkey(9) || jito.AmountRaw != "1000000" || jito.Amount != 0.001 || jito.Idx != "1" {
+		t.Errorf("unexpected jito tip: %+v", jito)
+	}
+	if custom := result.Tips[1]; custom.Provider != "Nextblock" || custom.AmountRaw != "500000" || custom.Idx != "2" {
+		t.Errorf("unexpected configured tip: %+v", custom)
+	}
+
+	trade := result.AggregateTrade
+	if trade == nil {
+		t.Fatalf("expected an aggregate trade (%s)", result.Msg)
+	}
+	var tip *types.FeeInfo
+	for i := range trade.Fees {
+		if trade.Fees[i].Type == "tip" {
+			tip = &trade.Fees[i]
+		}
+	}
+	if tip == nil || tip.Mint != constants.TOKENS.SOL || tip.AmountRaw != "1500000" || tip.Amount != 0.0015 || tip.Recipient != "" {
+		t.Errorf("expected the tip total on the aggregate trade, got %+v", trade.Fees)
+	}
+
+	// Without the configured provider only the Jito tip is reported
+	result = parser.ParseAll(tx, nil)
+	if len(result.Tips) != 1 || result.Tips[0].Provider != "Jito" {
+		t.Errorf("expected only the jito tip, got %+v", result.Tips)
+	}
+
+	// Relay providers are built in, and Jito tips stay known fee accounts
+	for account, provider := range map[string]string{
+		"HWEoBxYs7ssKuudEjzjmpfJVX7Dvi7wescFsVx2L5yoY": "bloXroute",
+		"NextbLoCkVtMGcV47JzewQdvBpLqT9TxQFozQkN98pE":  "Nextblock",
+		"Eb2KpSC8uMt9GmzyAEm5Eb1AAAgTjRaXWFjKyFXHZxF3": "0slot",
+		jitoTipAccount: "Jito",
+	} {
+		if got := constants.GetTipProvider(account); got != provider {
+			t.Errorf("expected %s to be a %s tip account, got %q", account, provider, got)
+		}
+	}
+	if !constants.IsFeeAccount(jitoTipAccount) {
+		t.Error("expected jito tip accounts in FEE_ACCOUNTS")
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/parsers"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/mr-tron/base58"
+)
+
+// findTrace returns the trace entry for an instruction idx
+func findTrace(t *testing.T, trace *types.ParseTrace, idx string) types.InstructionTrace {
+	t.Helper()
+	if trace == nil {
+		t.Fatal("Expected trace to be attached")
+	}
+	for _, entry := range trace.Instructions {
+		if entry.Idx == idx {
+			return entry
+		}
+	}
+	t.Fatalf("No trace entry for idx %s", idx)
+	return types.InstructionTrace{}
+}
+
+func TestTraceDisabledByDefault(t *testing.T) {
+	result := newIsolationParser().ParseAll(newTwoProgramTransaction(), &types.ParseConfig{})
+	if result.Trace != nil {
+		t.Error("Expected no trace without ParseConfig.Trace")
+	}
+}
+
+func TestTraceRecordsHandlersAndReasons(t *testing.T) {
+	result := newIsolationParser().ParseAll(newTwoProgramTransaction(), &types.ParseConfig{Trace: true})
+
+	if len(result.Trace.Instructions) != 2 {
+		t.Fatalf("Expected 2 traced instructions, got %d", len(result.Trace.Instructions))
+	}
+
+	panicked := findTrace(t, result.Trace, "0")
+	if panicked.ProgramId != panickingProgram || panicked.Discriminator != "01" {
+		t.Errorf("Unexpected entry: %+v", panicked)
+	}
+	if panicked.Reason != types.TraceReasonParserPanic || len(panicked.Handlers) != 1 || panicked.Handlers[0] != types.TraceHandlerTrade {
+		t.Errorf("Expected trade handler with parser_panic, got %+v", panicked)
+	}
+
+	working := findTrace(t, result.Trace, "1")
+	if working.Events != 1 || working.Reason != "" {
+		t.Errorf("Expected one event without reason, got %+v", working)
+	}
+	if result.Trace.JupiterEarlyReturn {
+		t.Error("Expected no Jupiter early return")
+	}
+}
+
+func TestTraceFilters(t *testing.T) {
+	parser := newIsolationParser()
+
+	result := parser.ParseAll(newTwoProgramTransaction(), &types.ParseConfig{Trace: true, IgnoreProgramIds: []string{panickingProgram}})
+	if entry := findTrace(t, result.Trace, "0"); entry.Reason != types.TraceReasonProgramFiltered {
+		t.Errorf("Expected program_filtered, got %s", entry.Reason)
+	}
+
+	result = parser.ParseAll(newTwoProgramTransaction(), &types.ParseConfig{Trace: true, AccountExclude: []string{"signer"}})
+	for _, entry := range result.Trace.Instructions {
+		if entry.Reason != types.TraceReasonTransactionFiltered {
+			t.Errorf("Expected transaction_filtered for %s, got %s", entry.Idx, entry.Reason)
+		}
+	}
+}
+
+func TestTraceNoEventReasons(t *testing.T) {
+	programId := constants.DEX_PROGRAMS.PUMP_FUN.ID
+	parser := dexparser.NewDexParser()
+	parser.RegisterTradeParser(programId, func(*adapter.TransactionAdapter, types.DexInfo, map[string][]types.TransferData, []types.ClassifiedInstruction) parsers.TradeParser {
+		return &stubTradeParser{}
+	})
+
+	tx := newTestTransaction("silent_signature", []string{"signer", programId, "UnregisteredProgram111111111111111111111111"},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Accounts: []int{0}, Data: base58.Encode([]byte{0xde, 0xad, 0xbe, 0xef, 0, 0, 0, 0, 1})},
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Accounts: []int{0}, Data: base58.Encode(constants.DISCRIMINATORS.PUMPFUN.BUY)},
+		adapter.CompiledInstruction{ProgramIdIndex: 2, Accounts: []int{0}, Data: base58.Encode([]byte{7})},
+		// A discriminator of another program is unknown to this one
+		adapter.CompiledInstruction{ProgramIdIndex: 1, Accounts: []int{0}, Data: base58.Encode(constants.DISCRIMINATORS.RAYDIUM.SWAP)},
+	)
+	tx.Meta = &adapter.TransactionMeta{}
+
+	result := parser.ParseAll(tx, &types.ParseConfig{Trace: true})
+	if entry := findTrace(t, result.Trace, "0"); entry.Reason != types.TraceReasonUnknownDiscriminator || entry.Discriminator != "deadbeef00000000" {
+		t.Errorf("Expected unknown_discriminator, got %+v", entry)
+	}
+	if entry := findTrace(t, result.Trace, "1"); entry.Reason != types.TraceReasonNoEvent {
+		t.Errorf("Expected no_event for known discriminator, got %s", entry.Reason)
+	}
+	if entry := findTrace(t, result.Trace, "2"); entry.Reason != types.TraceReasonNoParser {
+		t.Errorf("Expected no_parser, got %s", entry.Reason)
+	}
+	if entry := findTrace(t, result.Trace, "3"); entry.Reason != types.TraceReasonUnknownDiscriminator {
+		t.Errorf("Expected unknown_discriminator for another program's discriminator, got %s", entry.Reason)
+	}
+
+	result = parser.ParseAll(tx, &types.ParseConfig{Trace: true, TryUnknownDEX: true})
+	entry := findTrace(t, result.Trace, "2")
+	if entry.Reason != types.TraceReasonInsufficientTransfers || entry.Handlers[0] != types.TraceHandlerUnknownDEX {
+		t.Errorf("Expected unknown_dex with insufficient_transfers, got %+v", entry)
+	}
+
+	result = parser.ParseAll(tx, &types.ParseConfig{Trace: true, ParseType: types.ParseType{Liquidity: true}})
+	if entry := findTrace(t, result.Trace, "1"); entry.Reason != types.TraceReasonNotRequested {
+		t.Errorf("Expected not_requested when trades are disabled, got %s", entry.Reason)
+	}
+
+	// Without a discriminator table nothing is known about an instruction, so it has no event
+	const silentProgram = "SilentProgram1111111111111111111111111111111"
+	parser.RegisterTradeParser(silentProgram, func(*adapter.TransactionAdapter, types.DexInfo, map[string][]types.TransferData, []types.ClassifiedInstruction) parsers.TradeParser {
+		return &stubTradeParser{}
+	})
+	tx.Transaction.Message.StaticAccountKeys[1] = silentProgram
+	result = parser.ParseAll(tx, &types.ParseConfig{Trace: true})
+	if entry := findTrace(t, result.Trace, "0"); entry.Reason != types.TraceReasonNoEvent {
+		t.Errorf("Expected no_event for a program without discriminators, got %s", entry.Reason)
+	}
+}
@@ -0,0 +1,20 @@
+package tests
+
+import (
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+)
+
+// newTestTransaction builds a meta-less transaction signed by the first of keys, with
+// instructions compiled against keys. Callers set Slot, Meta or lookups as needed.
+func newTestTransaction(signature string, keys []string, instructions ...adapter.CompiledInstruction) *adapter.SolanaTransaction {
+	return &adapter.SolanaTransaction{
+		Transaction: adapter.TransactionData{
+			Signatures: []string{signature},
+			Message: adapter.TransactionMessage{
+				Header:               &adapter.MessageHeader{NumRequiredSignatures: 1},
+				StaticAccountKeys:    keys,
+				CompiledInstructions: instructions,
+			},
+		},
+	}
+}
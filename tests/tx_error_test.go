@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/goccy/go-json"
+)
+
+// rpcErr decodes meta.err the way it arrives from JSON-RPC
+func rpcErr(t *testing.T, raw string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		t.Fatalf("unmarshal %s: %v", raw, err)
+	}
+	return v
+}
+
+func TestTxErrorFailingCPI(t *testing.T) {
+	v4 := constants.DEX_PROGRAMS.RAYDIUM_V4.ID
+	tx := newRoutedTransaction([]string{
+		"Program " + logRouter + " invoke [1]",
+		"Program " + v4 + " invoke [2]",
+		"Program " + v4 + " failed: custom program error: 0x1e",
+		"Program " + logRouter + " failed: custom program error: 0x1e",
+	})
+	tx.Meta.Err = rpcErr(t, `{"InstructionError":[2,{"Custom":30}]}`)
+
+	result := dexparser.NewDexParser().ParseAll(tx, nil)
+	txErr := result.TxError
+	if result.TxStatus != types.TransactionStatusFailed || txErr == nil {
+		t.Fatalf("expected a TxError, got %+v", result)
+	}
+	if txErr.Kind != types.TxErrorInstruction || txErr.InstructionIndex != 2 || txErr.InnerIndex != 0 || txErr.ProgramId != v4 {
+		t.Errorf("unexpected attribution: %+v", txErr)
+	}
+	if txErr.Code == nil || *txErr.Code != 30 || txErr.Name != "ExceededSlippage" {
+		t.Errorf("unexpected code/name: %v %s", txErr.Code, txErr.Name)
+	}
+	if got := txErr.Error(); got != "InstructionError idx=2 program="+v4+": ExceededSlippage (30)" {
+		t.Errorf("unexpected message: %s", got)
+	}
+}
+
+func TestTxErrorVariants(t *testing.T) {
+	parser := dexparser.NewDexParser(dexparser.WithErrorTable(logRouter, map[uint32]string{6001: "RouteSlippage"}))
+
+	// Without logs the outer instruction's program is blamed
+	tx := newRoutedTransaction(nil)
+	tx.Meta.Err = rpcErr(t, `{"InstructionError":[2,{"Custom":6001}]}`)
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr == nil || txErr.ProgramId != logRouter || txErr.InnerIndex != -1 || txErr.Name != "RouteSlippage" {
+		t.Errorf("expected user table name, got %+v", txErr)
+	}
+	if txErr := dexparser.NewDexParser().ParseAll(tx, nil).TxError; txErr == nil || txErr.Name != "Custom" {
+		t.Errorf("user tables must not leak between parsers, got %+v", txErr)
+	}
+
+	// Codes missing from a registered table keep their anchor name
+	tx.Meta.Err = rpcErr(t, `{"InstructionError":[2,{"Custom":3012}]}`)
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr == nil || txErr.Name != "AccountNotInitialized" {
+		t.Errorf("expected anchor fallback with a user table, got %+v", txErr)
+	}
+	if txErr := dexparser.NewDexParser().ParseAll(tx, nil).TxError; txErr == nil || txErr.Name != "AccountNotInitialized" {
+		t.Errorf("expected anchor fallback without a table, got %+v", txErr)
+	}
+
+	tx.Meta.Err = rpcErr(t, `{"InstructionError":[1,"InvalidAccountData"]}`)
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr == nil || txErr.Name != "InvalidAccountData" || txErr.Code != nil || txErr.ProgramId != logPrecompile {
+		t.Errorf("unexpected variant error: %+v", txErr)
+	}
+
+	tx.Meta.Err = rpcErr(t, `{"InsufficientFundsForRent":{"account_index":2}}`)
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr == nil || txErr.Kind != "InsufficientFundsForRent" || txErr.InstructionIndex != -1 {
+		t.Errorf("unexpected transaction error: %+v", txErr)
+	}
+
+	tx.Meta.Err = "AccountInUse"
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr == nil || txErr.Name != "AccountInUse" || txErr.Error() != "AccountInUse" {
+		t.Errorf("unexpected string error: %+v", txErr)
+	}
+
+	tx.Meta.Err = nil
+	if txErr := parser.ParseAll(tx, nil).TxError; txErr != nil {
+		t.Errorf("successful transaction has no TxError, got %+v", txErr)
+	}
+
+	if got := constants.GetProgramErrorName(constants.DEX_PROGRAMS.PUMP_FUN.ID, 6002); got != "TooMuchSolRequired" {
+		t.Errorf("unexpected pumpfun error name: %s", got)
+	}
+	if got := constants.GetProgramErrorName(constants.DEX_PROGRAMS.JUPITER.ID, 6001); got != "SlippageToleranceExceeded" {
+		t.Errorf("unexpected jupiter error name: %s", got)
+	}
+	if got := constants.GetProgramErrorName(logRouter, 2006); got != "ConstraintSeeds" {
+		t.Errorf("expected anchor fallback, got %s", got)
+	}
+	if got := constants.GetProgramErrorName(constants.DEX_PROGRAMS.JUPITER.ID, 2006); got != "ConstraintSeeds" {
+		t.Errorf("expected anchor fallback for jupiter, got %s", got)
+	}
+	for _, programId := range []string{constants.DEX_PROGRAMS.RAYDIUM_V4.ID, constants.TOKEN_PROGRAM_ID, constants.SYSTEM_PROGRAM_ID} {
+		if got := constants.GetProgramErrorName(programId, 2006); got != "" {
+			t.Errorf("native program %s must not get anchor names, got %s", programId, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"sort"
+	"testing"
+
+	dexparser "github.com/DefaultPerson/solana-dex-parser-go"
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/goccy/go-json"
+	"github.com/mr-tron/base58"
+)
+
+// shortVec encodes a compact-u16 length
+func shortVec(n int) []byte {
+	var b []byte
+	for {
+		if n < 0x80 {
+			return append(b, byte(n))
+		}
+		b = append(b, byte(n&0x7f)|0x80)
+		n >>= 7
+	}
+}
+
+// encodeWire serializes a transaction decoded from a "json" RPC response in the wire format
+func encodeWire(t *testing.T, tx *adapter.SolanaTransaction) []byte {
+	t.Helper()
+	decode := func(s string) []byte {
+		b, err := base58.Decode(s)
+		if err != nil {
+			t.Fatalf("decode %s: %v", s, err)
+		}
+		return b
+	}
+	indexes := func(values []int) []byte {
+		b := shortVec(len(values))
+		for _, v := range values {
+			b = append(b, byte(v))
+		}
+		return b
+	}
+
+	var buf bytes.Buffer
+	buf.Write(shortVec(len(tx.Transaction.Signatures)))
+	for _, sig := range tx.Transaction.Signatures {
+		buf.Write(decode(sig))
+	}
+	msg := tx.Transaction.Message
+	versioned := msg.AddressTableLookups != nil
+	if versioned {
+		buf.WriteByte(0x80)
+	}
+	buf.Write([]byte{byte(msg.Header.NumRequiredSignatures), byte(msg.Header.NumReadonlySignedAccounts), byte(msg.Header.NumReadonlyUnsignedAccounts)})
+	buf.Write(shortVec(len(msg.AccountKeys)))
+	for _, key := range msg.AccountKeys {
+		buf.Write(decode(key.Pubkey))
+	}
+	buf.Write(decode(msg.RecentBlockhash))
+	buf.Write(shortVec(len(msg.Instructions)))
+	for _, raw := range msg.Instructions {
+		ix := raw.(map[string]interface{})
+		buf.WriteByte(byte(ix["programIdIndex"].(float64)))
+		var accounts []int
+		for _, account := range ix["accounts"].([]interface{}) {
+			accounts = append(accounts, int(account.(float64)))
+		}
+		buf.Write(indexes(accounts))
+		data := decode(ix["data"].(string))
+		buf.Write(shortVec(len(data)))
+		buf.Write(data)
+	}
+	if versioned {
+		buf.Write(shortVec(len(msg.AddressTableLookups)))
+		for _, lookup := range msg.AddressTableLookups {
+			buf.Write(decode(lookup.AccountKey))
+			buf.Write(indexes(lookup.WritableIndexes))
+			buf.Write(indexes(lookup.ReadonlyIndexes))
+		}
+	}
+	return buf.Bytes()
+}
+
+// marshalResult serializes a result for comparison. Ungrouped transfers come out in map order,
+// so they are sorted by idx first.
+func marshalResult(result *types.ParseResult) string {
+	sort.Slice(result.Transfers, func(i, j int) bool { return result.Transfers[i].Idx < result.Transfers[j].Idx })
+	b, _ := json.Marshal(result)
+	return string(b)
+}
+
+func TestWireTransactionMatchesRPC(t *testing.T) {
+	var rpcTx adapter.SolanaTransaction
+	if err := json.Unmarshal([]byte(recordedRPCTransaction), &rpcTx); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	wire := encodeWire(t, &rpcTx)
+
+	tx, err := adapter.DecodeWireTransaction(wire)
+	if err != nil {
+		t.Fatalf("DecodeWireTransaction: %v", err)
+	}
+	msg := tx.Transaction.Message
+	if tx.Version != float64(0) || tx.Meta != nil || len(msg.StaticAccountKeys) != 7 || len(msg.CompiledInstructions) != 3 || msg.RecentBlockhash != rpcTx.Transaction.Message.RecentBlockhash {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+	if lookups := msg.AddressTableLookups; len(lookups) != 1 || lookups[0].WritableIndexes[0] != 3 || lookups[0].ReadonlyIndexes[0] != 7 {
+		t.Errorf("unexpected lookups: %+v", lookups)
+	}
+	if ix := msg.CompiledInstructions[2]; ix.ProgramIdIndex != 6 || len(ix.Accounts) != 5 || ix.Data != "Ldp" {
+		t.Errorf("unexpected instruction: %+v", ix)
+	}
+
+	// The same response fetched with "base64" encoding parses like the "json" one
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(recordedRPCTransaction), &response); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	response["transaction"] = []string{base64.StdEncoding.EncodeToString(wire), "base64"}
+	encoded, _ := json.Marshal(response)
+	var base64Tx adapter.SolanaTransaction
+	if err := json.Unmarshal(encoded, &base64Tx); err != nil {
+		t.Fatalf("unmarshal base64 response: %v", err)
+	}
+
+	config := types.DefaultParseConfig()
+	parser := dexparser.NewDexParser()
+	if fromJSON, fromBase64 := marshalResult(parser.ParseAll(&rpcTx, &config)), marshalResult(parser.ParseAll(&base64Tx, &config)); fromJSON != fromBase64 {
+		t.Errorf("parse results differ\n json: %s\nbase64: %s", fromJSON, fromBase64)
+	}
+
+	if tx, err := adapter.DecodeWireTransactionString(base58.Encode(wire), "base58"); err != nil || len(tx.Transaction.Message.CompiledInstructions) != 3 {
+		t.Errorf("unexpected base58 decode: %v", err)
+	}
+}
+
+func TestWireTransactionLegacy(t *testing.T) {
+	signer, other := testPubkey(1), testPubkey(2)
+	keys := []string{signer, other, testPubkey(3), constants.SYSTEM_PROGRAM_ID}
+	var buf bytes.Buffer
+	buf.Write(shortVec(2))
+	buf.Write(bytes.Repeat([]byte{7}, 128))
+	buf.Write([]byte{2, 1, 1})
+	buf.Write(shortVec(len(keys)))
+	for _, key := range keys {
+		b, _ := base58.Decode(key)
+		buf.Write(b)
+	}
+	buf.Write(make([]byte, 32))
+	buf.Write(shortVec(1))
+	buf.Write([]byte{3, 2, 0, 2})
+	data, _ := base58.Decode(systemTransferData(1000))
+	buf.Write(shortVec(len(data)))
+	buf.Write(data)
+	wire := buf.Bytes()
+
+	tx, err := adapter.DecodeWireTransaction(wire)
+	if err != nil {
+		t.Fatalf("DecodeWireTransaction: %v", err)
+	}
+	msg := tx.Transaction.Message
+	if tx.Version != "legacy" || len(tx.Transaction.Signatures) != 2 || len(msg.StaticAccountKeys) != 0 || msg.AddressTableLookups != nil {
+		t.Errorf("unexpected legacy transaction: %+v", tx)
+	}
+	flags := [][2]bool{{true, true}, {true, false}, {false, true}, {false, false}}
+	for i, key := range msg.AccountKeys {
+		if key.Pubkey != keys[i] || key.Signer != flags[i][0] || key.Writable != flags[i][1] {
+			t.Errorf("unexpected account key %d: %+v", i, key)
+		}
+	}
+
+	// Without meta the transfer is still decoded from the instruction
+	txAdapter := adapter.NewTransactionAdapter(tx, &types.ParseConfig{})
+	if signers := txAdapter.Signers(); len(signers) != 2 || signers[1] != other {
+		t.Errorf("unexpected signers: %v", signers)
+	}
+	result := dexparser.NewDexParser().ParseAll(tx, nil)
+	if len(result.Transfers) != 1 || result.Transfers[0].Info.Destination != testPubkey(3) || result.Transfers[0].Info.TokenAmount.Amount != "1000" {
+		t.Errorf("unexpected transfers: %+v", result.Transfers)
+	}
+
+	// Transactions read back to back report how many bytes each used
+	stream := append(append([]byte{}, wire...), wire...)
+	if _, n, err := adapter.ReadWireTransaction(stream); err != nil || n != len(wire) {
+		t.Errorf("ReadWireTransaction = %d, %v", n, err)
+	}
+
+	bad := [][]byte{
+		nil,
+		wire[:len(wire)-1],
+		append(append([]byte{}, wire...), 0),
+		{0x80, 0x80, 0x80},                   // compact-u16 longer than 3 bytes
+		{0x80, 0x00},                         // non-canonical compact-u16
+		append([]byte{0, 0x81}, wire[1:]...), // message version 1
+		append(append([]byte{1}, make([]byte, 64)...), 5, 0, 0, 1), // 5 signers, 1 key
+	}
+	for _, b := range bad {
+		if _, err := adapter.DecodeWireTransaction(b); err == nil {
+			t.Errorf("expected error for % x", b)
+		}
+	}
+	if _, err := adapter.DecodeWireTransactionString("!!", "base64"); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+	if _, err := adapter.DecodeWireTransactionString("", "hex"); err == nil {
+		t.Error("expected error for an unknown encoding")
+	}
+}
@@ -0,0 +1,294 @@
+package dexparser
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/adapter"
+	"github.com/DefaultPerson/solana-dex-parser-go/classifier"
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+	"github.com/DefaultPerson/solana-dex-parser-go/utils"
+)
+
+// tracer collects a ParseTrace; all methods are no-ops on a nil tracer
+type tracer struct {
+	trace   *types.ParseTrace
+	data    [][]byte       // instruction data, aligned with trace.Instructions
+	byIdx   map[string]int // instruction idx -> position in trace.Instructions
+	program map[string][]int
+	keys    []string // transfer-action keys, aligned with trace.Instructions
+
+	transferActions map[string][]types.TransferData
+}
+
+// newTracer creates a tracer for the classified instructions of the given programs, or nil if disabled
+func newTracer(enabled bool, adapt *adapter.TransactionAdapter, instrClassifier *classifier.InstructionClassifier, programIds []string) *tracer {
+	if !enabled {
+		return nil
+	}
+
+	var instructions []types.ClassifiedInstruction
+	for _, programId := range programIds {
+		instructions = append(instructions, instrClassifier.GetInstructions(programId)...)
+	}
+	sort.SliceStable(instructions, func(i, j int) bool {
+		if instructions[i].OuterIndex != instructions[j].OuterIndex {
+			return instructions[i].OuterIndex < instructions[j].OuterIndex
+		}
+		return instructions[i].InnerIndex < instructions[j].InnerIndex
+	})
+
+	t := &tracer{
+		trace:   &types.ParseTrace{Instructions: make([]types.InstructionTrace, 0, len(instructions))},
+		byIdx:   make(map[string]int, len(instructions)),
+		program: make(map[string][]int),
+	}
+	for _, ci := range instructions {
+		data := adapt.GetInstructionData(ci.Instruction)
+		discriminator := data
+		if len(discriminator) > 8 {
+			discriminator = discriminator[:8]
+		}
+		entry := types.InstructionTrace{
+			Idx:           utils.FormatIdx(ci.OuterIndex, ci.InnerIndex),
+			ProgramId:     ci.ProgramId,
+			ProgramName:   constants.GetProgramName(ci.ProgramId),
+			Discriminator: hex.EncodeToString(discriminator),
+		}
+		t.keys = append(t.keys, utils.FormatTransferKey(ci.ProgramId, ci.OuterIndex, ci.InnerIndex))
+
+		pos := len(t.trace.Instructions)
+		t.trace.Instructions = append(t.trace.Instructions, entry)
+		t.data = append(t.data, data)
+		t.byIdx[entry.Idx] = pos
+		t.program[ci.ProgramId] = append(t.program[ci.ProgramId], pos)
+	}
+	return t
+}
+
+// setTransferActions records the transfer-action key and transfer count of each instruction
+func (t *tracer) setTransferActions(transferActions map[string][]types.TransferData) {
+	if t == nil {
+		return
+	}
+	t.transferActions = transferActions
+	for i, key := range t.keys {
+		if transfers, ok := transferActions[key]; ok {
+			t.trace.Instructions[i].TransferKey = key
+			t.trace.Instructions[i].TransferCount = len(transfers)
+		}
+	}
+}
+
+// setDexProgram records the main program detected for the transaction
+func (t *tracer) setDexProgram(programId string) {
+	if t == nil {
+		return
+	}
+	t.trace.DexProgramId = programId
+}
+
+// jupiterEarlyReturn marks every instruction outside the Jupiter program as skipped
+func (t *tracer) jupiterEarlyReturn(jupiterProgramId string) {
+	if t == nil {
+		return
+	}
+	t.trace.JupiterEarlyReturn = true
+	for i := range t.trace.Instructions {
+		if t.trace.Instructions[i].ProgramId != jupiterProgramId {
+			t.setReason(i, types.TraceReasonJupiterEarlyReturn)
+		}
+	}
+}
+
+// handled records that a parser of the given kind ran over a program's instructions
+func (t *tracer) handled(programId, handler string) {
+	if t == nil {
+		return
+	}
+	for _, pos := range t.program[programId] {
+		entry := &t.trace.Instructions[pos]
+		entry.Handlers = append(entry.Handlers, handler)
+	}
+}
+
+// skipProgram records why a program's instructions were not parsed
+func (t *tracer) skipProgram(programId string, reason types.TraceReason) {
+	if t == nil {
+		return
+	}
+	for _, pos := range t.program[programId] {
+		t.setReason(pos, reason)
+	}
+}
+
+// skipAll records why no instruction was parsed
+func (t *tracer) skipAll(reason types.TraceReason) {
+	if t == nil {
+		return
+	}
+	for i := range t.trace.Instructions {
+		t.setReason(i, reason)
+	}
+}
+
+// emitted attributes events to instructions of programId by idx; an event whose idx matches
+// no instruction of the program is attributed to the program's first instruction with the same outer index
+func (t *tracer) emitted(programId string, idxs ...string) {
+	if t == nil {
+		return
+	}
+	for _, idx := range idxs {
+		if pos, ok := t.byIdx[idx]; ok && t.trace.Instructions[pos].ProgramId == programId {
+			t.trace.Instructions[pos].Events++
+			continue
+		}
+		outer, _, _ := strings.Cut(idx, "-")
+		for _, pos := range t.program[programId] {
+			entryOuter, _, _ := strings.Cut(t.trace.Instructions[pos].Idx, "-")
+			if entryOuter == outer {
+				t.trace.Instructions[pos].Events++
+				break
+			}
+		}
+	}
+}
+
+// setReason sets the reason of an instruction unless one is already recorded
+func (t *tracer) setReason(pos int, reason types.TraceReason) {
+	if t.trace.Instructions[pos].Reason == "" {
+		t.trace.Instructions[pos].Reason = reason
+	}
+}
+
+// finish fills in the remaining reasons and returns the trace
+func (t *tracer) finish(result *types.ParseResult, adapt *adapter.TransactionAdapter) *types.ParseTrace {
+	if t == nil {
+		return nil
+	}
+
+	panicked := make(map[string]bool)
+	for _, err := range result.Errors {
+		if err.Code == types.ParseErrorDecoderPanic {
+			panicked[err.ProgramId] = true
+		}
+	}
+
+	for i := range t.trace.Instructions {
+		entry := &t.trace.Instructions[i]
+		if entry.Events > 0 {
+			entry.Reason = ""
+			continue
+		}
+		switch {
+		case entry.Reason != "":
+		case panicked[entry.ProgramId]:
+			entry.Reason = types.TraceReasonParserPanic
+		case len(entry.Handlers) == 0:
+			entry.Reason = types.TraceReasonNotRequested
+		case containsString(entry.Handlers, types.TraceHandlerUnknownDEX):
+			entry.Reason = t.unknownDEXReason(adapt, entry)
+		case !isKnownDiscriminator(entry.ProgramId, t.data[i]):
+			entry.Reason = types.TraceReasonUnknownDiscriminator
+		default:
+			entry.Reason = types.TraceReasonNoEvent
+		}
+	}
+	return t.trace
+}
+
+// unknownDEXReason explains why the unknown DEX path produced no trade for an instruction
+func (t *tracer) unknownDEXReason(adapt *adapter.TransactionAdapter, entry *types.InstructionTrace) types.TraceReason {
+	transfers := t.transferActions[entry.TransferKey]
+	if len(transfers) < 2 {
+		return types.TraceReasonInsufficientTransfers
+	}
+	for _, transfer := range transfers {
+		if adapt.IsSupportedToken(transfer.Info.Mint) {
+			return types.TraceReasonNoEvent
+		}
+	}
+	return types.TraceReasonUnsupportedToken
+}
+
+var (
+	knownDiscriminators     map[string][][]byte
+	knownDiscriminatorsOnce sync.Once
+)
+
+// programDiscriminators maps program ids to their table in constants.DISCRIMINATORS
+func programDiscriminators() map[string]interface{} {
+	d, p := constants.DISCRIMINATORS, constants.DEX_PROGRAMS
+	return map[string]interface{}{
+		p.JUPITER.ID:                  d.JUPITER,
+		p.JUPITER_DCA.ID:              d.JUPITER_DCA,
+		p.JUPITER_LIMIT_ORDER.ID:      d.JUPITER_LIMIT_ORDER,
+		p.JUPITER_LIMIT_ORDER_V2.ID:   d.JUPITER_LIMIT_ORDER_V2,
+		p.JUPITER_VA.ID:               d.JUPITER_VA,
+		p.PUMP_FUN.ID:                 d.PUMPFUN,
+		p.PUMP_SWAP.ID:                d.PUMPSWAP,
+		p.MOONIT.ID:                   d.MOONIT,
+		p.RAYDIUM_V4.ID:               d.RAYDIUM,
+		p.RAYDIUM_CL.ID:               d.RAYDIUM_CL,
+		p.RAYDIUM_CPMM.ID:             d.RAYDIUM_CPMM,
+		p.RAYDIUM_LCP.ID:              d.RAYDIUM_LCP,
+		p.METEORA.ID:                  d.METEORA_DLMM,
+		p.METEORA_DAMM.ID:             d.METEORA_DAMM,
+		p.METEORA_DAMM_V2.ID:          d.METEORA_DAMM_V2,
+		p.METEORA_DBC.ID:              d.METEORA_DBC,
+		p.ORCA.ID:                     d.ORCA,
+		p.BOOP_FUN.ID:                 d.BOOPFUN,
+		p.HEAVEN.ID:                   d.HEAVEN,
+		constants.METAPLEX_PROGRAM_ID: d.METAPLEX,
+		p.SUGAR.ID:                    d.SUGAR,
+		p.PHOTON.ID:                   d.PHOTON,
+		p.SOLFI.ID:                    d.SOLFI,
+		p.GOONFI.ID:                   d.GOONFI,
+		p.OBRIC_V2.ID:                 d.OBRIC,
+		p.DFLOW.ID:                    d.DFLOW,
+		p.HUMIDIFI.ID:                 d.HUMIDIFI,
+	}
+}
+
+// isKnownDiscriminator reports whether data starts with a discriminator of programId in
+// constants.DISCRIMINATORS. Programs without a table always report true, since nothing is
+// known about their instructions.
+func isKnownDiscriminator(programId string, data []byte) bool {
+	knownDiscriminatorsOnce.Do(func() {
+		tables := programDiscriminators()
+		knownDiscriminators = make(map[string][][]byte, len(tables))
+		for id, table := range tables {
+			knownDiscriminators[id] = collectDiscriminators(reflect.ValueOf(table), nil)
+		}
+	})
+	discriminators, ok := knownDiscriminators[programId]
+	if !ok {
+		return true
+	}
+	for _, d := range discriminators {
+		if bytes.HasPrefix(data, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectDiscriminators gathers every non-empty []byte field of a (nested) discriminator struct
+func collectDiscriminators(v reflect.Value, out [][]byte) [][]byte {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			out = collectDiscriminators(v.Field(i), out)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Len() > 0 {
+			out = append(out, v.Bytes())
+		}
+	}
+	return out
+}
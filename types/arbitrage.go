@@ -0,0 +1,67 @@
+package types
+
+// ArbitrageType classifies an arbitrage transaction
+type ArbitrageType string
+
+const (
+	// ArbitrageTypeAtomic is a successful cycle that does not follow a target transaction
+	ArbitrageTypeAtomic ArbitrageType = "atomic"
+	// ArbitrageTypeBackrun is a successful cycle right after another signer's trade on one of its
+	// pools; only ParseBlock sees the preceding transactions and sets it
+	ArbitrageTypeBackrun ArbitrageType = "backrun"
+	// ArbitrageTypeFailed is a cycle in a failed transaction, from its trades or, as these are
+	// rarely rebuilt without inner instructions, from its router instructions
+	ArbitrageTypeFailed ArbitrageType = "failed"
+)
+
+// ArbitrageInfo describes the trade cycles of a transaction and what the signer earned from them
+type ArbitrageInfo struct {
+	// Type classifies the arbitrage
+	Type ArbitrageType `json:"type"`
+
+	// Cycles are the trade paths that start and end with the same mint, in execution order
+	Cycles []ArbitrageCycle `json:"cycles"`
+
+	// GrossProfit is the signer's balance change per mint (UI amount) before the transaction fee
+	// and tips; wrapped and native SOL are combined under the wrapped SOL mint
+	GrossProfit map[string]float64 `json:"grossProfit"`
+
+	// NetProfit is GrossProfit after subtracting Fee and the tips the signer paid from SOL
+	NetProfit map[string]float64 `json:"netProfit"`
+
+	// Fee is the transaction fee in SOL, including PriorityFee
+	Fee float64 `json:"fee"`
+
+	// PriorityFee is the prioritization fee part of Fee in SOL
+	PriorityFee float64 `json:"priorityFee"`
+
+	// Tips is the total of the tips in SOL; tips of failed transactions are not paid
+	Tips float64 `json:"tips"`
+
+	// Target is the signature of the transaction a backrun follows
+	Target string `json:"target,omitempty"`
+}
+
+// ArbitrageCycle is a sequence of trades that returns to its starting mint
+type ArbitrageCycle struct {
+	// Mint is the mint the cycle starts and ends with
+	Mint string `json:"mint"`
+
+	// Hops are the trades of the cycle in execution order
+	Hops []ArbitrageHop `json:"hops"`
+
+	// Profit is the output of the last hop minus the input of the first, in Mint (UI amount)
+	Profit float64 `json:"profit"`
+}
+
+// ArbitrageHop is one trade of an arbitrage cycle
+type ArbitrageHop struct {
+	Pool         string  `json:"pool,omitempty"`
+	AMM          string  `json:"amm,omitempty"`
+	ProgramId    string  `json:"programId"`
+	InputMint    string  `json:"inputMint"`
+	OutputMint   string  `json:"outputMint"`
+	InputAmount  float64 `json:"inputAmount"`
+	OutputAmount float64 `json:"outputAmount"`
+	Idx          string  `json:"idx"`
+}
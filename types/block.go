@@ -0,0 +1,55 @@
+package types
+
+// BlockResult is the result of parsing a whole block
+type BlockResult struct {
+	// Slot, ParentSlot, Blockhash and BlockTime are taken from the block
+	Slot       uint64 `json:"slot"`
+	ParentSlot uint64 `json:"parentSlot"`
+	Blockhash  string `json:"blockhash"`
+	BlockTime  int64  `json:"blockTime"`
+
+	// Transactions holds the results of the non-vote transactions in block order
+	Transactions []BlockTransaction `json:"transactions"`
+
+	// VoteCount is the number of vote transactions that were skipped
+	VoteCount int `json:"voteCount"`
+
+	// Aggregates summarizes the parsed transactions
+	Aggregates BlockAggregates `json:"aggregates"`
+}
+
+// BlockTransaction is the result of one transaction and its position in the block
+type BlockTransaction struct {
+	Index  int          `json:"index"`
+	Result *ParseResult `json:"result"`
+}
+
+// BlockAggregates summarizes the trades, token creations and failures of a block.
+// Trades and token creations of failed transactions are not counted.
+type BlockAggregates struct {
+	// TradeCount is the number of trades (individual swaps) in the block
+	TradeCount int `json:"tradeCount"`
+
+	// AMMs holds the trade count and volume per AMM name, or per program id for unnamed programs
+	AMMs map[string]*AMMStats `json:"amms"`
+
+	// NewTokens lists the mints of meme CREATE events in block order
+	NewTokens []string `json:"newTokens"`
+
+	// FailedTransactions is the number of failed non-vote transactions
+	FailedTransactions int `json:"failedTransactions"`
+
+	// FailedSwaps is the number of failed transactions that invoked an AMM, a router or a program
+	// with a registered trade parser
+	FailedSwaps int `json:"failedSwaps"`
+}
+
+// AMMStats is the trading activity of one AMM in a block
+type AMMStats struct {
+	// Trades is the number of trades
+	Trades int `json:"trades"`
+
+	// Volume is the traded quote amount (SOL or stablecoin side) in UI units, keyed by quote mint.
+	// Trades without a quote token count in Trades only.
+	Volume map[string]float64 `json:"volume"`
+}
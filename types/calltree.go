@@ -0,0 +1,115 @@
+package types
+
+// CallStatus is the outcome of one program invocation
+type CallStatus string
+
+const (
+	CallStatusSuccess CallStatus = "success"
+	CallStatusFailed  CallStatus = "failed"
+	// CallStatusIncomplete means the logs end (or were truncated) before the invocation returned
+	CallStatusIncomplete CallStatus = "incomplete"
+)
+
+// CallTree is the program invocation tree reconstructed from LogMessages
+type CallTree struct {
+	// Roots contains one node per logged outer instruction, in execution order
+	Roots []*CallNode `json:"roots"`
+
+	// Truncated is true when the runtime cut the logs short; later invocations are missing
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CallNode is one program invocation ("Program <id> invoke [depth]")
+type CallNode struct {
+	// ProgramId is the invoked program
+	ProgramId string `json:"programId"`
+
+	// OuterIndex is the index of the outer instruction, as in ClassifiedInstruction
+	OuterIndex int `json:"outerIndex"`
+
+	// InnerIndex is the index within the outer instruction's inner instructions, -1 for the outer instruction itself
+	InnerIndex int `json:"innerIndex"`
+
+	// Depth is the invoke depth, 1 for outer instructions
+	Depth int `json:"depth"`
+
+	// ComputeUnitsConsumed is X of "consumed X of Y compute units", including nested invocations
+	ComputeUnitsConsumed uint64 `json:"computeUnitsConsumed"`
+
+	// ComputeUnitsAvailable is Y of "consumed X of Y compute units"
+	ComputeUnitsAvailable uint64 `json:"computeUnitsAvailable"`
+
+	// Status is the outcome of the invocation
+	Status CallStatus `json:"status"`
+
+	// Error is the message of a "failed: <message>" line
+	Error string `json:"error,omitempty"`
+
+	// Logs contains the lines written by this invocation itself, excluding nested invocations
+	Logs []string `json:"logs,omitempty"`
+
+	// Children contains the CPIs made by this invocation, in execution order
+	Children []*CallNode `json:"children,omitempty"`
+}
+
+// Walk visits every node in execution order (depth first); returning false from fn skips the node's children
+func (t *CallTree) Walk(fn func(node *CallNode) bool) {
+	if t == nil {
+		return
+	}
+	for _, root := range t.Roots {
+		root.walk(fn)
+	}
+}
+
+func (n *CallNode) walk(fn func(node *CallNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.walk(fn)
+	}
+}
+
+// Find returns the invocation of an instruction by its ClassifiedInstruction indexes, or nil if it did not log
+func (t *CallTree) Find(outerIndex, innerIndex int) *CallNode {
+	var found *CallNode
+	t.Walk(func(node *CallNode) bool {
+		if found != nil || node.OuterIndex != outerIndex {
+			return false
+		}
+		if node.InnerIndex == innerIndex {
+			found = node
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// FailedCall returns the deepest failed invocation, i.e. the instruction or CPI that raised the error
+func (t *CallTree) FailedCall() *CallNode {
+	var failed *CallNode
+	t.Walk(func(node *CallNode) bool {
+		if node.Status != CallStatusFailed {
+			return false
+		}
+		failed = node
+		return true
+	})
+	return failed
+}
+
+// ProgramComputeUnits sums the compute units consumed by each program, excluding the CPIs it made
+func (t *CallTree) ProgramComputeUnits() map[string]uint64 {
+	units := make(map[string]uint64)
+	t.Walk(func(node *CallNode) bool {
+		own := node.ComputeUnitsConsumed
+		for _, child := range node.Children {
+			own -= min(own, child.ComputeUnitsConsumed)
+		}
+		units[node.ProgramId] += own
+		return true
+	})
+	return units
+}
@@ -1,5 +1,9 @@
 package types
 
+import (
+	"errors"
+)
+
 // ClassifiedInstruction represents a classified instruction with its context information
 type ClassifiedInstruction struct {
 	// Instruction is the raw instruction data
@@ -31,6 +35,50 @@ func formatIdxSingle(outer int) string {
 	return string(rune('0' + outer))
 }
 
+// LogEventType identifies the log line a ClassifiedEvent was decoded from
+type LogEventType string
+
+const (
+	// LogEventProgramData is a "Program data: <base64>" line written by sol_log_data (e.g. Anchor emit!)
+	LogEventProgramData LogEventType = "program_data"
+	// LogEventRayLog is a "Program log: ray_log: <base64>" line written by Raydium AMM v4
+	LogEventRayLog LogEventType = "ray_log"
+)
+
+// ClassifiedEvent represents a log payload attributed to the instruction that emitted it
+type ClassifiedEvent struct {
+	// Type is the kind of log line the payload was read from
+	Type LogEventType `json:"type"`
+
+	// ProgramId is the program executing when the payload was logged
+	ProgramId string `json:"programId"`
+
+	// OuterIndex is the outer instruction index in the transaction
+	OuterIndex int `json:"outerIndex"`
+
+	// InnerIndex is the inner instruction index (for CPI calls), -1 if not inner
+	InnerIndex int `json:"innerIndex"`
+
+	// Depth is the invoke depth of the emitting program (1 for outer instructions)
+	Depth int `json:"depth"`
+
+	// LogIndex is the index of the line in the transaction log messages
+	LogIndex int `json:"logIndex"`
+
+	// Data is the decoded payload; multiple base64 chunks on one line are concatenated
+	Data []byte `json:"data"`
+}
+
+// TipInfo is a SOL transfer to a block engine or relay tip account
+type TipInfo struct {
+	Provider  string  `json:"provider"`  // Tip provider (e.g. 'Jito')
+	Account   string  `json:"account"`   // Tip account
+	Payer     string  `json:"payer"`     // Account paying the tip
+	Amount    float64 `json:"amount"`    // Tip amount in SOL
+	AmountRaw string  `json:"amountRaw"` // Tip amount in lamports
+	Idx       string  `json:"idx"`       // Instruction index
+}
+
 // BalanceChange represents token balance changes before and after transaction execution
 type BalanceChange struct {
 	Pre    TokenAmount `json:"pre"`    // Token balance before transaction execution
@@ -82,9 +130,24 @@ type ParseResult struct {
 	// Fee is the transaction gas fee paid in SOL
 	Fee TokenAmount `json:"fee"`
 
+	// BaseFee is the signature fee part of Fee in SOL
+	BaseFee TokenAmount `json:"baseFee"`
+
+	// PriorityFee is the prioritization fee part of Fee in SOL
+	PriorityFee TokenAmount `json:"priorityFee"`
+
+	// Tips contains SOL transfers to known tip accounts (Jito and ParseConfig.TipAccounts)
+	Tips []TipInfo `json:"tips,omitempty"`
+
+	// ComputeBudget contains the decoded ComputeBudget instructions (unit limit, price, priority fee)
+	ComputeBudget *ComputeBudget `json:"computeBudget,omitempty"`
+
 	// AggregateTrade contains aggregated trade information combining multiple related trades
 	AggregateTrade *TradeInfo `json:"aggregateTrade,omitempty"`
 
+	// Arbitrage describes the trade cycles of the transaction and the signer's profit, if any
+	Arbitrage *ArbitrageInfo `json:"arbitrage,omitempty"`
+
 	// Trades contains array of individual trade transactions found in the transaction
 	Trades []TradeInfo `json:"trades"`
 
@@ -124,9 +187,24 @@ type ParseResult struct {
 	// TxStatus indicates final execution status of the transaction
 	TxStatus TransactionStatus `json:"txStatus"`
 
+	// TxError contains the decoded meta.err of a failed transaction
+	TxError *TxError `json:"txError,omitempty"`
+
 	// Msg contains optional error or status message
 	Msg string `json:"msg,omitempty"`
 
+	// Skipped is true when the transaction was never parsed because the context was done
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Errors contains structured parse failures; parser failures leave other programs' results intact
+	Errors []*ParseError `json:"errors,omitempty"`
+
+	// Trace explains how each instruction was handled (set when ParseConfig.Trace is true)
+	Trace *ParseTrace `json:"trace,omitempty"`
+
+	// CallTree contains the program invocations with their compute units and status (set when ParseConfig.CallTree is true)
+	CallTree *CallTree `json:"callTree,omitempty"`
+
 	// Extras contains additional parser-specific data
 	Extras interface{} `json:"extras,omitempty"`
 }
@@ -136,6 +214,8 @@ func NewParseResult() *ParseResult {
 	return &ParseResult{
 		State:       true,
 		Fee:         TokenAmount{Amount: "0", Decimals: 9},
+		BaseFee:     TokenAmount{Amount: "0", Decimals: 9},
+		PriorityFee: TokenAmount{Amount: "0", Decimals: 9},
 		Trades:      make([]TradeInfo, 0),
 		Liquidities: make([]PoolEvent, 0),
 		Transfers:   make([]TransferData, 0),
@@ -146,9 +226,24 @@ func NewParseResult() *ParseResult {
 	}
 }
 
-// IsArbitrage returns true if the aggregated trade is an arbitrage
-// (same input and output token mint addresses)
+// Err returns the parse errors joined into one error, or nil if there were none
+func (r *ParseResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Errors))
+	for i, err := range r.Errors {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+// IsArbitrage returns true if the transaction completed a trade cycle, or if the aggregated
+// trade has the same input and output token mint addresses
 func (r *ParseResult) IsArbitrage() bool {
+	if r.Arbitrage != nil {
+		return r.Arbitrage.Type != ArbitrageTypeFailed
+	}
 	if r.AggregateTrade != nil {
 		return r.AggregateTrade.InputToken.Mint == r.AggregateTrade.OutputToken.Mint
 	}
@@ -180,6 +275,12 @@ type ParseShredResult struct {
 
 	// Msg contains optional error or status message
 	Msg string `json:"msg,omitempty"`
+
+	// Skipped is true when the transaction was never parsed because the context was done
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Errors contains structured parse failures; parser failures leave other programs' results intact
+	Errors []*ParseError `json:"errors,omitempty"`
 }
 
 // ParsedShredInstruction represents a typed parsed shred instruction
@@ -0,0 +1,22 @@
+package types
+
+// ComputeBudget contains the ComputeBudget program settings of a transaction
+type ComputeBudget struct {
+	// UnitLimit is the requested compute unit limit, or the runtime default when not set
+	UnitLimit uint32 `json:"unitLimit"`
+
+	// DefaultUnitLimit is true when the transaction has no SetComputeUnitLimit instruction
+	DefaultUnitLimit bool `json:"defaultUnitLimit,omitempty"`
+
+	// UnitPrice is the compute unit price in micro-lamports
+	UnitPrice uint64 `json:"unitPrice"`
+
+	// PriorityFee is the prioritization fee in lamports, ceil(UnitPrice * UnitLimit / 1e6)
+	PriorityFee uint64 `json:"priorityFee"`
+
+	// HeapFrameBytes is the requested heap frame size (RequestHeapFrame)
+	HeapFrameBytes uint32 `json:"heapFrameBytes,omitempty"`
+
+	// LoadedAccountsDataSizeLimit is the requested loaded accounts data size limit in bytes
+	LoadedAccountsDataSizeLimit uint32 `json:"loadedAccountsDataSizeLimit,omitempty"`
+}
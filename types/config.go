@@ -72,6 +72,15 @@ type ParseConfig struct {
 	// ThrowError if true, will panic on parse errors instead of returning error state
 	ThrowError bool `json:"throwError,omitempty"`
 
+	// Trace if true, will attach a ParseTrace explaining how each instruction was handled
+	Trace bool `json:"trace,omitempty"`
+
+	// TipAccounts maps further tip providers to their tip accounts, in addition to constants.TIP_ACCOUNTS
+	TipAccounts map[string][]string `json:"tipAccounts,omitempty"`
+
+	// CallTree if true, will attach the program invocation tree reconstructed from the logs
+	CallTree bool `json:"callTree,omitempty"`
+
 	// AggregateTrades if true, will return the finalSwap record instead of detail route trades
 	// Deprecated: Use ParseType.AggregateTrade instead. Kept for backward compatibility.
 	AggregateTrades bool `json:"aggregateTrades,omitempty"`
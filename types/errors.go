@@ -0,0 +1,125 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseErrorCode is a stable identifier for a class of parse failures
+type ParseErrorCode string
+
+const (
+	// ParseErrorFiltered means the transaction was rejected by ParseConfig filters
+	ParseErrorFiltered ParseErrorCode = "FILTERED"
+
+	// ParseErrorMalformedInput means the transaction data could not be decoded
+	ParseErrorMalformedInput ParseErrorCode = "MALFORMED_INPUT"
+
+	// ParseErrorDecoderPanic means a program parser panicked while decoding its instructions, or
+	// parsing panicked outside any program parser (without a ProgramId)
+	ParseErrorDecoderPanic ParseErrorCode = "DECODER_PANIC"
+
+	// ParseErrorUnsupportedVersion means the transaction message version is not supported
+	ParseErrorUnsupportedVersion ParseErrorCode = "UNSUPPORTED_VERSION"
+)
+
+// Sentinel errors for matching ParseError codes with errors.Is
+var (
+	ErrFiltered           = &ParseError{Code: ParseErrorFiltered}
+	ErrMalformedInput     = &ParseError{Code: ParseErrorMalformedInput}
+	ErrDecoderPanic       = &ParseError{Code: ParseErrorDecoderPanic}
+	ErrUnsupportedVersion = &ParseError{Code: ParseErrorUnsupportedVersion}
+)
+
+// ParseError describes a failure while parsing a transaction or one of its programs
+type ParseError struct {
+	Code      ParseErrorCode `json:"code"`                // Error class
+	ProgramId string         `json:"programId,omitempty"` // Program whose parser failed, if any
+	Idx       string         `json:"idx,omitempty"`       // Instruction index, if known
+	Message   string         `json:"message"`             // Cause message
+	Cause     error          `json:"-"`                   // Wrapped cause
+}
+
+// NewParseError creates a ParseError wrapping cause
+func NewParseError(code ParseErrorCode, programId string, idx string, cause error) *ParseError {
+	err := &ParseError{
+		Code:      code,
+		ProgramId: programId,
+		Idx:       idx,
+		Cause:     cause,
+	}
+	if cause != nil {
+		err.Message = cause.Error()
+	}
+	return err
+}
+
+// NewPanicError creates a ParseError for a recovered panic value
+func NewPanicError(code ParseErrorCode, programId string, idx string, recovered interface{}) *ParseError {
+	cause, ok := recovered.(error)
+	if !ok {
+		cause = fmt.Errorf("%v", recovered)
+	}
+	return NewParseError(code, programId, idx, cause)
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	msg := string(e.Code)
+	if e.ProgramId != "" {
+		msg += " program=" + e.ProgramId
+	}
+	if e.Idx != "" {
+		msg += " idx=" + e.Idx
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped cause
+func (e *ParseError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is a ParseError with the same code
+func (e *ParseError) Is(target error) bool {
+	var t *ParseError
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// TxErrorInstruction is the TxError.Kind of errors raised by an instruction ({"InstructionError":[idx, detail]})
+const TxErrorInstruction = "InstructionError"
+
+// TxError is the decoded transaction error (meta.err) of a failed transaction
+type TxError struct {
+	Kind             string      `json:"kind"`                // "InstructionError" or the transaction error, e.g. "InsufficientFundsForFee"
+	InstructionIndex int         `json:"instructionIndex"`    // Index of the failing outer instruction, -1 for transaction errors
+	InnerIndex       int         `json:"innerIndex"`          // Index of the failing CPI when known from the logs, -1 otherwise
+	ProgramId        string      `json:"programId,omitempty"` // Program that raised the error
+	Code             *uint32     `json:"code,omitempty"`      // Custom program error code
+	Name             string      `json:"name"`                // Resolved error name, the InstructionError variant or Kind
+	Raw              interface{} `json:"raw"`                 // Original meta.err
+}
+
+// Error implements the error interface
+func (e *TxError) Error() string {
+	msg := e.Kind
+	if e.InstructionIndex >= 0 {
+		msg += fmt.Sprintf(" idx=%d", e.InstructionIndex)
+	}
+	if e.ProgramId != "" {
+		msg += " program=" + e.ProgramId
+	}
+	if e.Name != "" && e.Name != e.Kind {
+		msg += ": " + e.Name
+	}
+	if e.Code != nil {
+		msg += fmt.Sprintf(" (%d)", *e.Code)
+	}
+	return msg
+}
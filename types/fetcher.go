@@ -1,5 +1,9 @@
 package types
 
+import (
+	"context"
+)
+
 // FetchFilterType specifies when to invoke a fetcher callback
 type FetchFilterType string
 
@@ -34,8 +38,12 @@ type ALTsFetcher struct {
 
 	// Fetch resolves ALT references to actual addresses
 	// Input: slice of ALT lookup references from transaction
-	// Output: map of ALT account key -> LoadedAddresses
+	// Output: map of ALT account key -> LoadedAddresses, where Writable/Readonly hold the
+	// addresses selected by WritableIndexes/ReadonlyIndexes of that lookup, in the same order
 	Fetch func(alts []AddressTableLookup) (map[string]*LoadedAddresses, error)
+
+	// FetchContext is the context-aware variant of Fetch, preferred when set
+	FetchContext func(ctx context.Context, alts []AddressTableLookup) (map[string]*LoadedAddresses, error)
 }
 
 // TokenAccountInfo contains token account metadata
@@ -55,6 +63,9 @@ type TokenAccountsFetcher struct {
 	// Input: slice of token account public keys
 	// Output: slice of TokenAccountInfo (nil for accounts that couldn't be fetched)
 	Fetch func(accountKeys []string) ([]*TokenAccountInfo, error)
+
+	// FetchContext is the context-aware variant of Fetch, preferred when set
+	FetchContext func(ctx context.Context, accountKeys []string) ([]*TokenAccountInfo, error)
 }
 
 // PoolInfoFetcher provides pluggable pool information resolution
@@ -64,8 +75,11 @@ type PoolInfoFetcher struct {
 
 	// Fetch retrieves pool information for given pool keys
 	// Input: slice of pool public keys
-	// Output: slice of pool info (interface{} to support different pool types)
-	Fetch func(poolKeys []string) ([]interface{}, error)
+	// Output: slice of PoolInfo aligned with input (nil for pools that couldn't be fetched)
+	Fetch func(poolKeys []string) ([]*PoolInfo, error)
+
+	// FetchContext is the context-aware variant of Fetch, preferred when set
+	FetchContext func(ctx context.Context, poolKeys []string) ([]*PoolInfo, error)
 }
 
 // NewALTsFetcher creates a new ALTs fetcher with specified filter and function
@@ -93,10 +107,91 @@ func NewTokenAccountsFetcher(
 // NewPoolInfoFetcher creates a new pool info fetcher with specified filter and function
 func NewPoolInfoFetcher(
 	filter FetchFilterType,
-	fetcher func(poolKeys []string) ([]interface{}, error),
+	fetcher func(poolKeys []string) ([]*PoolInfo, error),
 ) *PoolInfoFetcher {
 	return &PoolInfoFetcher{
 		Filter: filter,
 		Fetch:  fetcher,
 	}
 }
+
+// NewALTsFetcherContext creates a new ALTs fetcher with a context-aware function
+func NewALTsFetcherContext(
+	filter FetchFilterType,
+	fetcher func(ctx context.Context, alts []AddressTableLookup) (map[string]*LoadedAddresses, error),
+) *ALTsFetcher {
+	return &ALTsFetcher{
+		Filter:       filter,
+		FetchContext: fetcher,
+	}
+}
+
+// NewTokenAccountsFetcherContext creates a new token accounts fetcher with a context-aware function
+func NewTokenAccountsFetcherContext(
+	filter FetchFilterType,
+	fetcher func(ctx context.Context, accountKeys []string) ([]*TokenAccountInfo, error),
+) *TokenAccountsFetcher {
+	return &TokenAccountsFetcher{
+		Filter:       filter,
+		FetchContext: fetcher,
+	}
+}
+
+// NewPoolInfoFetcherContext creates a new pool info fetcher with a context-aware function
+func NewPoolInfoFetcherContext(
+	filter FetchFilterType,
+	fetcher func(ctx context.Context, poolKeys []string) ([]*PoolInfo, error),
+) *PoolInfoFetcher {
+	return &PoolInfoFetcher{
+		Filter:       filter,
+		FetchContext: fetcher,
+	}
+}
+
+// Enabled reports whether the fetcher has a fetch function
+func (f *ALTsFetcher) Enabled() bool {
+	return f != nil && (f.FetchContext != nil || f.Fetch != nil)
+}
+
+// FetchWithContext calls FetchContext if set, otherwise Fetch once ctx is checked
+func (f *ALTsFetcher) FetchWithContext(ctx context.Context, alts []AddressTableLookup) (map[string]*LoadedAddresses, error) {
+	if f.FetchContext != nil {
+		return f.FetchContext(ctx, alts)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Fetch(alts)
+}
+
+// Enabled reports whether the fetcher has a fetch function
+func (f *TokenAccountsFetcher) Enabled() bool {
+	return f != nil && (f.FetchContext != nil || f.Fetch != nil)
+}
+
+// FetchWithContext calls FetchContext if set, otherwise Fetch once ctx is checked
+func (f *TokenAccountsFetcher) FetchWithContext(ctx context.Context, accountKeys []string) ([]*TokenAccountInfo, error) {
+	if f.FetchContext != nil {
+		return f.FetchContext(ctx, accountKeys)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Fetch(accountKeys)
+}
+
+// Enabled reports whether the fetcher has a fetch function
+func (f *PoolInfoFetcher) Enabled() bool {
+	return f != nil && (f.FetchContext != nil || f.Fetch != nil)
+}
+
+// FetchWithContext calls FetchContext if set, otherwise Fetch once ctx is checked
+func (f *PoolInfoFetcher) FetchWithContext(ctx context.Context, poolKeys []string) ([]*PoolInfo, error) {
+	if f.FetchContext != nil {
+		return f.FetchContext(ctx, poolKeys)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Fetch(poolKeys)
+}
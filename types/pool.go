@@ -71,3 +71,70 @@ type PoolEvent struct {
 	// LpAmountRaw is the LP token raw amount
 	LpAmountRaw string `json:"lpAmountRaw,omitempty"`
 }
+
+// PoolInfo contains on-chain pool metadata returned by PoolInfoFetcher
+type PoolInfo struct {
+	// PoolId is the AMM pool address
+	PoolId string `json:"poolId"`
+
+	// ProgramId is the DEX program owning the pool
+	ProgramId string `json:"programId,omitempty"`
+
+	// AMM is the AMM name (e.g., 'RaydiumV4', 'Pumpswap')
+	AMM string `json:"amm,omitempty"`
+
+	// BaseMint is the base token mint (Token0)
+	BaseMint string `json:"baseMint"`
+
+	// QuoteMint is the quote token mint (Token1, usually SOL/USDC/USDT)
+	QuoteMint string `json:"quoteMint"`
+
+	// BaseDecimals is the base token decimals
+	BaseDecimals uint8 `json:"baseDecimals"`
+
+	// QuoteDecimals is the quote token decimals
+	QuoteDecimals uint8 `json:"quoteDecimals"`
+
+	// BaseVault is the pool token account holding the base token
+	BaseVault string `json:"baseVault,omitempty"`
+
+	// QuoteVault is the pool token account holding the quote token
+	QuoteVault string `json:"quoteVault,omitempty"`
+
+	// LpMint is the LP mint address
+	LpMint string `json:"lpMint,omitempty"`
+
+	// FeeRate is the pool trade fee as a fraction (0.0025 = 25 bps)
+	FeeRate float64 `json:"feeRate,omitempty"`
+}
+
+// HasMint reports whether the mint is the base or quote token of the pool
+func (p *PoolInfo) HasMint(mint string) bool {
+	return mint != "" && (mint == p.BaseMint || mint == p.QuoteMint)
+}
+
+// OtherMint returns the counterpart of the given mint in the pool, or "" if the mint is not in the pool
+func (p *PoolInfo) OtherMint(mint string) string {
+	switch mint {
+	case p.BaseMint:
+		return p.QuoteMint
+	case p.QuoteMint:
+		return p.BaseMint
+	default:
+		return ""
+	}
+}
+
+// MintDecimals returns the decimals of a pool token
+func (p *PoolInfo) MintDecimals(mint string) (uint8, bool) {
+	switch {
+	case mint == "":
+		return 0, false
+	case mint == p.BaseMint:
+		return p.BaseDecimals, true
+	case mint == p.QuoteMint:
+		return p.QuoteDecimals, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+// TraceReason explains why an instruction produced no event
+type TraceReason string
+
+const (
+	// TraceReasonTransactionFiltered means the whole transaction was rejected by ParseConfig filters
+	TraceReasonTransactionFiltered TraceReason = "transaction_filtered"
+
+	// TraceReasonProgramFiltered means the program was excluded by ProgramIds/IgnoreProgramIds
+	TraceReasonProgramFiltered TraceReason = "program_filtered"
+
+	// TraceReasonJupiterEarlyReturn means the Jupiter path returned before other programs were parsed
+	TraceReasonJupiterEarlyReturn TraceReason = "jupiter_early_return"
+
+	// TraceReasonNoParser means no parser is registered for the program
+	TraceReasonNoParser TraceReason = "no_parser"
+
+	// TraceReasonNotRequested means the program has parsers but their event types were not requested
+	TraceReasonNotRequested TraceReason = "not_requested"
+
+	// TraceReasonUnknownDiscriminator means the instruction data matches no known discriminator of its program
+	TraceReasonUnknownDiscriminator TraceReason = "unknown_discriminator"
+
+	// TraceReasonNoEvent means a parser handled the instruction but emitted nothing for it
+	TraceReasonNoEvent TraceReason = "no_event"
+
+	// TraceReasonInsufficientTransfers means fewer than 2 transfers were found for an unknown DEX
+	TraceReasonInsufficientTransfers TraceReason = "insufficient_transfers"
+
+	// TraceReasonUnsupportedToken means none of the unknown DEX transfers involve SOL or a stablecoin
+	TraceReasonUnsupportedToken TraceReason = "unsupported_token"
+
+	// TraceReasonParserPanic means the program parser panicked (see ParseResult.Errors)
+	TraceReasonParserPanic TraceReason = "parser_panic"
+)
+
+// Trace handler names recorded in InstructionTrace.Handlers
+const (
+	TraceHandlerTrade      = "trade"
+	TraceHandlerLiquidity  = "liquidity"
+	TraceHandlerMemeEvent  = "meme"
+	TraceHandlerTransfer   = "transfer"
+	TraceHandlerUnknownDEX = "unknown_dex"
+	TraceHandlerAlt        = "alt"
+)
+
+// ParseTrace explains how DexParser processed each classified instruction
+type ParseTrace struct {
+	// DexProgramId is the main program detected for the transaction
+	DexProgramId string `json:"dexProgramId,omitempty"`
+
+	// JupiterEarlyReturn is true when the Jupiter path produced trades and other programs were skipped
+	JupiterEarlyReturn bool `json:"jupiterEarlyReturn"`
+
+	// Instructions contains one entry per classified instruction, in idx order
+	Instructions []InstructionTrace `json:"instructions"`
+}
+
+// InstructionTrace records how one classified instruction was handled
+type InstructionTrace struct {
+	Idx           string      `json:"idx"`                     // Instruction index ("outer" or "outer-inner")
+	ProgramId     string      `json:"programId"`               // Program that owns the instruction
+	ProgramName   string      `json:"programName"`             // Human-readable program name
+	Discriminator string      `json:"discriminator"`           // Hex of the first (up to 8) data bytes
+	Handlers      []string    `json:"handlers,omitempty"`      // Parser kinds that ran for the program
+	TransferKey   string      `json:"transferKey,omitempty"`   // Transfer-action key of the instruction
+	TransferCount int         `json:"transferCount,omitempty"` // Transfers grouped under TransferKey
+	Events        int         `json:"events"`                  // Events attributed to the instruction
+	Reason        TraceReason `json:"reason,omitempty"`        // Why no event was emitted
+}
@@ -120,6 +120,11 @@ type TradeInfo struct {
 	Idx         string      `json:"idx"`                   // Instruction indexes
 	Signer      []string    `json:"signer,omitempty"`      // Original signer
 	Extras      interface{} `json:"extras,omitempty"`      // Additional parser-specific data
+
+	// Valuation set by pricing.Engine from on-chain reference prices
+	PriceQuote float64 `json:"priceQuote,omitempty"` // Price of the base token in the quote token
+	PriceUSD   float64 `json:"priceUSD,omitempty"`   // Price of the base token in USD
+	VolumeUSD  float64 `json:"volumeUSD,omitempty"`  // Trade value in USD
 }
 
 // ConvertToUIAmount converts raw token amount to human-readable format
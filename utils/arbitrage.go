@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// GetArbitrage finds the trade cycles in trades and accounts the signer's profit from the
+// balance changes, fee and tips of result. It returns nil if the trades form no cycle through
+// at least two pools. Failed transactions have no inner instructions, so their cycles are only
+// found where the trades could be rebuilt; see GetFailedRouteArbitrage.
+func GetArbitrage(result *types.ParseResult, trades []types.TradeInfo) *types.ArbitrageInfo {
+	cycles := findTradeCycles(SortTradesByIdx(trades))
+	if len(cycles) == 0 {
+		return nil
+	}
+	return accountArbitrage(result, cycles)
+}
+
+// GetFailedRouteArbitrage classifies a failed transaction as a failed arbitrage from its
+// router instructions, such as Jupiter routes, whose input and output mints are the same.
+// Each such route is a cycle of one hop, with its quoted output as OutputAmount. It returns
+// nil for successful transactions and when no route is a cycle.
+func GetFailedRouteArbitrage(result *types.ParseResult, routes []types.TradeInfo) *types.ArbitrageInfo {
+	if result.TxStatus != types.TransactionStatusFailed {
+		return nil
+	}
+	var cycles []types.ArbitrageCycle
+	for _, route := range SortTradesByIdx(routes) {
+		mint := constants.NormalizeMint(route.InputToken.Mint)
+		if mint == "" || mint != constants.NormalizeMint(route.OutputToken.Mint) {
+			continue
+		}
+		hop := types.ArbitrageHop{
+			AMM:          route.AMM,
+			ProgramId:    route.ProgramId,
+			InputMint:    mint,
+			OutputMint:   mint,
+			InputAmount:  route.InputToken.Amount,
+			OutputAmount: route.OutputToken.Amount,
+			Idx:          route.Idx,
+		}
+		if len(route.Pool) > 0 {
+			hop.Pool = route.Pool[0]
+		}
+		cycles = append(cycles, types.ArbitrageCycle{
+			Mint:   mint,
+			Hops:   []types.ArbitrageHop{hop},
+			Profit: hop.OutputAmount - hop.InputAmount,
+		})
+	}
+	if len(cycles) == 0 {
+		return nil
+	}
+	return accountArbitrage(result, cycles)
+}
+
+// accountArbitrage classifies cycles and accounts the signer's profit from the balance changes,
+// fee and tips of result
+func accountArbitrage(result *types.ParseResult, cycles []types.ArbitrageCycle) *types.ArbitrageInfo {
+	info := &types.ArbitrageInfo{
+		Type:        types.ArbitrageTypeAtomic,
+		Cycles:      cycles,
+		GrossProfit: make(map[string]float64),
+		NetProfit:   make(map[string]float64),
+		Fee:         tokenAmountUI(result.Fee),
+		PriorityFee: tokenAmountUI(result.PriorityFee),
+	}
+	failed := result.TxStatus == types.TransactionStatusFailed
+	if failed {
+		info.Type = types.ArbitrageTypeFailed
+	}
+	signer := ""
+	if len(result.Signer) > 0 {
+		signer = result.Signer[0]
+	}
+
+	// Raw changes per mint; native SOL is merged into wrapped SOL
+	changes := make(map[string]*big.Int)
+	decimals := map[string]uint8{constants.TOKENS.SOL: 9}
+	add := func(mint string, change types.TokenAmount) {
+		amount, ok := new(big.Int).SetString(change.Amount, 10)
+		if !ok {
+			return
+		}
+		mint = constants.NormalizeMint(mint)
+		if mint != constants.TOKENS.SOL {
+			decimals[mint] = change.Decimals
+		}
+		if changes[mint] == nil {
+			changes[mint] = new(big.Int)
+		}
+		changes[mint].Add(changes[mint], amount)
+	}
+	if result.SolBalanceChange != nil {
+		add(constants.TOKENS.NATIVE, result.SolBalanceChange.Change)
+	}
+	for mint, change := range result.TokenBalanceChange {
+		add(mint, change.Change)
+	}
+
+	// The signer's SOL change already includes the fee and the tips it paid
+	fee, _ := new(big.Int).SetString(result.Fee.Amount, 10)
+	if fee == nil {
+		fee = new(big.Int)
+	}
+	tips, paidTips := new(big.Int), new(big.Int)
+	if !failed {
+		for _, tip := range result.Tips {
+			amount, ok := new(big.Int).SetString(tip.AmountRaw, 10)
+			if !ok {
+				continue
+			}
+			tips.Add(tips, amount)
+			if tip.Payer == signer {
+				paidTips.Add(paidTips, amount)
+			}
+		}
+	}
+	info.Tips = types.ConvertToUIAmount(tips, 9)
+
+	sol := new(big.Int)
+	if changes[constants.TOKENS.SOL] != nil {
+		sol.Set(changes[constants.TOKENS.SOL])
+	}
+	sol.Add(sol, fee).Add(sol, paidTips)
+	changes[constants.TOKENS.SOL] = sol
+
+	for mint, change := range changes {
+		net := change
+		if mint == constants.TOKENS.SOL {
+			net = new(big.Int).Sub(change, fee)
+			net.Sub(net, paidTips)
+		}
+		if change.Sign() != 0 {
+			info.GrossProfit[mint] = types.ConvertToUIAmount(change, decimals[mint])
+		}
+		if net.Sign() != 0 {
+			info.NetProfit[mint] = types.ConvertToUIAmount(net, decimals[mint])
+		}
+	}
+	return info
+}
+
+// findTradeCycles returns disjoint cycles of trades in execution order, trying each unused trade
+// as the start of a cycle
+func findTradeCycles(trades []types.TradeInfo) []types.ArbitrageCycle {
+	hops := make([]types.ArbitrageHop, 0, len(trades))
+	for _, trade := range trades {
+		if trade.InputToken.Mint == "" || trade.OutputToken.Mint == "" {
+			continue
+		}
+		hop := types.ArbitrageHop{
+			AMM:          trade.AMM,
+			ProgramId:    trade.ProgramId,
+			InputMint:    constants.NormalizeMint(trade.InputToken.Mint),
+			OutputMint:   constants.NormalizeMint(trade.OutputToken.Mint),
+			InputAmount:  trade.InputToken.Amount,
+			OutputAmount: trade.OutputToken.Amount,
+			Idx:          trade.Idx,
+		}
+		if len(trade.Pool) > 0 {
+			hop.Pool = trade.Pool[0]
+		}
+		if hop.InputMint != hop.OutputMint {
+			hops = append(hops, hop)
+		}
+	}
+
+	used := make([]bool, len(hops))
+	var cycles []types.ArbitrageCycle
+	for start := range hops {
+		if used[start] {
+			continue
+		}
+		path := followCycle(hops, used, start)
+		if path == nil {
+			continue
+		}
+		cycle := types.ArbitrageCycle{Mint: hops[start].InputMint}
+		for _, i := range path {
+			used[i] = true
+			cycle.Hops = append(cycle.Hops, hops[i])
+		}
+		cycle.Profit = hops[path[len(path)-1]].OutputAmount - hops[start].InputAmount
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// followCycle follows from start the first later unused hop spending the previous output, and
+// returns the path once it is back at the starting mint through at least two pools
+func followCycle(hops []types.ArbitrageHop, used []bool, start int) []int {
+	path := []int{start}
+	for current := start; ; {
+		next := current + 1
+		for next < len(hops) && (used[next] || hops[next].InputMint != hops[current].OutputMint) {
+			next++
+		}
+		if next == len(hops) {
+			return nil
+		}
+		path = append(path, next)
+		if hops[next].OutputMint == hops[start].InputMint {
+			if distinctPools(hops, path) < 2 {
+				return nil
+			}
+			return path
+		}
+		current = next
+	}
+}
+
+// distinctPools counts the pools of a path, keyed by pool address or by program for trades
+// without one
+func distinctPools(hops []types.ArbitrageHop, path []int) int {
+	pools := make(map[string]bool)
+	for _, i := range path {
+		key := hops[i].Pool
+		if key == "" {
+			key = hops[i].ProgramId
+		}
+		pools[key] = true
+	}
+	return len(pools)
+}
+
+// tokenAmountUI returns the UI amount of a TokenAmount, computing it from the raw amount if unset
+func tokenAmountUI(amount types.TokenAmount) float64 {
+	if amount.UIAmount != nil {
+		return *amount.UIAmount
+	}
+	return types.ConvertToUIAmountString(amount.Amount, amount.Decimals)
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// AttachPoolInfo fetches every pool referenced by trades and liquidity events in one
+// PoolInfoFetcher call and fills in missing mints, decimals and Token0/Token1 orientation
+func (tu *TransactionUtils) AttachPoolInfo(trades []types.TradeInfo, liquidities []types.PoolEvent) {
+	config := tu.adapter.Config
+	if config == nil || !config.PoolInfoFetcher.Enabled() {
+		return
+	}
+	if len(trades) == 0 && len(liquidities) == 0 {
+		return
+	}
+	if !tu.adapter.ShouldFetch(config.PoolInfoFetcher.Filter) {
+		return
+	}
+
+	var poolKeys []string
+	seen := make(map[string]bool)
+	addPool := func(pool string) {
+		if pool != "" && !seen[pool] {
+			seen[pool] = true
+			poolKeys = append(poolKeys, pool)
+		}
+	}
+	for _, trade := range trades {
+		for _, pool := range trade.Pool {
+			addPool(pool)
+		}
+	}
+	for _, lp := range liquidities {
+		addPool(lp.PoolId)
+	}
+	if len(poolKeys) == 0 {
+		return
+	}
+
+	infos, err := config.PoolInfoFetcher.FetchWithContext(tu.adapter.Context(), poolKeys)
+	if err != nil {
+		return
+	}
+	pools := make(map[string]*types.PoolInfo, len(infos))
+	for i, info := range infos {
+		if i < len(poolKeys) && info != nil {
+			pools[poolKeys[i]] = info
+		}
+	}
+
+	for i := range trades {
+		for _, pool := range trades[i].Pool {
+			if info, ok := pools[pool]; ok {
+				applyPoolInfoToTrade(&trades[i], info)
+				break
+			}
+		}
+	}
+	for i := range liquidities {
+		if info, ok := pools[liquidities[i].PoolId]; ok {
+			applyPoolInfoToPoolEvent(&liquidities[i], info)
+		}
+	}
+}
+
+// applyPoolInfoToTrade fills a missing side of the trade and zero decimals from pool info
+func applyPoolInfoToTrade(trade *types.TradeInfo, pool *types.PoolInfo) {
+	switch {
+	case trade.InputToken.Mint == "" && pool.HasMint(trade.OutputToken.Mint):
+		trade.InputToken.Mint = pool.OtherMint(trade.OutputToken.Mint)
+		trade.Type = GetTradeType(trade.InputToken.Mint, trade.OutputToken.Mint)
+	case trade.OutputToken.Mint == "" && pool.HasMint(trade.InputToken.Mint):
+		trade.OutputToken.Mint = pool.OtherMint(trade.InputToken.Mint)
+		trade.Type = GetTradeType(trade.InputToken.Mint, trade.OutputToken.Mint)
+	}
+
+	fillTokenDecimals(&trade.InputToken, pool)
+	fillTokenDecimals(&trade.OutputToken, pool)
+
+	if trade.AMM == "" {
+		trade.AMM = pool.AMM
+	}
+}
+
+// fillTokenDecimals sets decimals for a token with unknown precision and recomputes its UI amount
+func fillTokenDecimals(token *types.TokenInfo, pool *types.PoolInfo) {
+	if token.Decimals != 0 {
+		return
+	}
+	decimals, ok := pool.MintDecimals(token.Mint)
+	if !ok || decimals == 0 {
+		return
+	}
+	token.Decimals = decimals
+	if token.AmountRaw != "" {
+		token.Amount = types.ConvertToUIAmountString(token.AmountRaw, decimals)
+	}
+}
+
+// applyPoolInfoToPoolEvent fills missing mints/decimals and orients Token0 as base, Token1 as quote
+func applyPoolInfoToPoolEvent(lp *types.PoolEvent, pool *types.PoolInfo) {
+	switch {
+	case lp.Token0Mint == "" && lp.Token1Mint == "":
+		lp.Token0Mint = pool.BaseMint
+		lp.Token1Mint = pool.QuoteMint
+	case lp.Token0Mint == "" && pool.HasMint(lp.Token1Mint):
+		lp.Token0Mint = pool.OtherMint(lp.Token1Mint)
+	case lp.Token1Mint == "" && pool.HasMint(lp.Token0Mint):
+		lp.Token1Mint = pool.OtherMint(lp.Token0Mint)
+	}
+
+	if pool.BaseMint != pool.QuoteMint && lp.Token0Mint == pool.QuoteMint && lp.Token1Mint == pool.BaseMint {
+		swapPoolEventTokens(lp)
+	}
+
+	if lp.Token0Decimals == nil {
+		if decimals, ok := pool.MintDecimals(lp.Token0Mint); ok {
+			lp.Token0Decimals = Ptr(decimals)
+			if lp.Token0Amount == nil && lp.Token0AmountRaw != "" {
+				lp.Token0Amount = Ptr(types.ConvertToUIAmountString(lp.Token0AmountRaw, decimals))
+			}
+		}
+	}
+	if lp.Token1Decimals == nil {
+		if decimals, ok := pool.MintDecimals(lp.Token1Mint); ok {
+			lp.Token1Decimals = Ptr(decimals)
+			if lp.Token1Amount == nil && lp.Token1AmountRaw != "" {
+				lp.Token1Amount = Ptr(types.ConvertToUIAmountString(lp.Token1AmountRaw, decimals))
+			}
+		}
+	}
+
+	if lp.PoolLpMint == "" {
+		lp.PoolLpMint = pool.LpMint
+	}
+	if lp.AMM == "" {
+		lp.AMM = pool.AMM
+	}
+}
+
+// swapPoolEventTokens exchanges the Token0 and Token1 sides of a pool event
+func swapPoolEventTokens(lp *types.PoolEvent) {
+	lp.Token0Mint, lp.Token1Mint = lp.Token1Mint, lp.Token0Mint
+	lp.Token0Amount, lp.Token1Amount = lp.Token1Amount, lp.Token0Amount
+	lp.Token0AmountRaw, lp.Token1AmountRaw = lp.Token1AmountRaw, lp.Token0AmountRaw
+	lp.Token0BalanceChange, lp.Token1BalanceChange = lp.Token1BalanceChange, lp.Token0BalanceChange
+	lp.Token0Decimals, lp.Token1Decimals = lp.Token1Decimals, lp.Token0Decimals
+}
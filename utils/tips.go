@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/DefaultPerson/solana-dex-parser-go/constants"
+	"github.com/DefaultPerson/solana-dex-parser-go/types"
+)
+
+// GetTips returns the SOL transfers to known tip accounts, in outer and inner instructions.
+// extra maps additional providers to their tip accounts and takes precedence over constants.TIP_ACCOUNTS.
+func (tu *TransactionUtils) GetTips(extra map[string][]string) []types.TipInfo {
+	providers := make(map[string]string)
+	for provider, accounts := range extra {
+		for _, account := range accounts {
+			providers[account] = provider
+		}
+	}
+	provider := func(account string) string {
+		if name, ok := providers[account]; ok {
+			return name
+		}
+		return constants.GetTipProvider(account)
+	}
+
+	innerSets := make(map[int][]interface{})
+	for _, set := range tu.adapter.InnerInstructions() {
+		innerSets[set.Index] = set.Instructions
+	}
+
+	var tips []types.TipInfo
+	check := func(instruction interface{}, idx string) {
+		transfer := tu.ParseInstructionAction(instruction, idx, nil)
+		if transfer == nil || transfer.ProgramId != constants.SYSTEM_PROGRAM_ID {
+			return
+		}
+		name := provider(transfer.Info.Destination)
+		if name == "" {
+			return
+		}
+		tips = append(tips, types.TipInfo{
+			Provider:  name,
+			Account:   transfer.Info.Destination,
+			Payer:     transfer.Info.Source,
+			Amount:    types.ConvertToUIAmountString(transfer.Info.TokenAmount.Amount, 9),
+			AmountRaw: transfer.Info.TokenAmount.Amount,
+			Idx:       idx,
+		})
+	}
+
+	for outerIndex, instruction := range tu.adapter.Instructions() {
+		check(instruction, strconv.Itoa(outerIndex))
+		for innerIndex, inner := range innerSets[outerIndex] {
+			check(inner, FormatIdx(outerIndex, innerIndex))
+		}
+	}
+	return tips
+}
+
+// AttachTips adds the total of the tips to the trade fees as a SOL fee of type "tip"
+func AttachTips(trade *types.TradeInfo, tips []types.TipInfo) {
+	if trade == nil || len(tips) == 0 {
+		return
+	}
+	total := new(big.Int)
+	for _, tip := range tips {
+		if amount, ok := new(big.Int).SetString(tip.AmountRaw, 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	fee := types.FeeInfo{
+		Mint:      constants.TOKENS.SOL,
+		Amount:    types.ConvertToUIAmount(total, 9),
+		AmountRaw: total.String(),
+		Decimals:  9,
+		Type:      "tip",
+	}
+	if len(tips) == 1 {
+		fee.Recipient = tips[0].Account
+	}
+	trade.Fees = append(trade.Fees, fee)
+}
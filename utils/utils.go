@@ -61,6 +61,21 @@ func GetTradeType(inMint, outMint string) types.TradeType {
 	return types.TradeTypeSell
 }
 
+// OrientTrade returns the base and quote side of a trade, with native SOL normalized to wrapped
+// SOL, and whether the trade buys the base token. The quote side is the token with the higher
+// constants.QuoteRank; trades between tokens of equal rank have no orientation.
+func OrientTrade(trade types.TradeInfo) (base, quote types.TokenInfo, buy, ok bool) {
+	in, out := trade.InputToken, trade.OutputToken
+	in.Mint, out.Mint = constants.NormalizeMint(in.Mint), constants.NormalizeMint(out.Mint)
+	switch inRank, outRank := constants.QuoteRank(in.Mint), constants.QuoteRank(out.Mint); {
+	case inRank > outRank:
+		return out, in, true, true
+	case outRank > inRank:
+		return in, out, false, true
+	}
+	return types.TokenInfo{}, types.TokenInfo{}, false, false
+}
+
 // GetAMMs extracts AMM names from transfer action keys
 func GetAMMs(transferActionKeys []string) []string {
 	var result []string